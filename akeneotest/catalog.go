@@ -0,0 +1,60 @@
+package akeneotest
+
+import akeneo "github.com/c-design/akeneo/api"
+
+// Catalog groups one double per resource service.
+type Catalog struct {
+	Products         *Products
+	ProductModels    *ProductModels
+	MediaFiles       *MediaFiles
+	Families         *Families
+	FamilyVariants   *FamilyVariants
+	Attributes       *Attributes
+	AttributeOptions *AttributeOptions
+	AttributeGroups  *AttributeGroups
+	AssociationTypes *AssociationTypes
+	Categories       *Categories
+	Channels         *Channels
+	Locales          *Locales
+	Currencies       *Currencies
+	MeasureFamilies  *MeasureFamilies
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{
+		Products:         NewProducts(),
+		ProductModels:    NewProductModels(),
+		MediaFiles:       NewMediaFiles(),
+		Families:         NewFamilies(),
+		FamilyVariants:   NewFamilyVariants(),
+		Attributes:       NewAttributes(),
+		AttributeOptions: NewAttributeOptions(),
+		AttributeGroups:  NewAttributeGroups(),
+		AssociationTypes: NewAssociationTypes(),
+		Categories:       NewCategories(),
+		Channels:         NewChannels(),
+		Locales:          NewLocales(),
+		Currencies:       NewCurrencies(),
+		MeasureFamilies:  NewMeasureFamilies(),
+	}
+}
+
+// Api returns an akeneo.Api wired to the doubles of the catalog.
+func (c *Catalog) Api() *akeneo.Api {
+	return &akeneo.Api{
+		Category:           c.Categories,
+		Family:             c.Families,
+		FamilyVariant:      c.FamilyVariants,
+		Attribute:          c.Attributes,
+		AttributeOption:    c.AttributeOptions,
+		AttributeGroup:     c.AttributeGroups,
+		AssociationTypeApi: c.AssociationTypes,
+		Product:            c.Products,
+		ProductModel:       c.ProductModels,
+		MediaFile:          c.MediaFiles,
+		Channel:            c.Channels,
+		Locale:             c.Locales,
+		Currency:           c.Currencies,
+		MeasureFamily:      c.MeasureFamilies,
+	}
+}
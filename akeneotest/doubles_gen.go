@@ -0,0 +1,2590 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package akeneotest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+// Products is an in-memory akeneo.ProductService. Upsert replaces the
+// stored resource instead of merging the patch into it.
+type Products struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.Product
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewProducts() *Products {
+	return &Products{items: map[string]*akeneo.Product{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *Products) Add(items ...*akeneo.Product) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Identifier)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by identifier.
+func (d *Products) All() []*akeneo.Product {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.Product
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *Products) copy(item *akeneo.Product) *akeneo.Product {
+	c := &akeneo.Product{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *Products) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Identifier) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *Products) GetAll(opts akeneo.QueryOptions) (*akeneo.ProductsResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *Products) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.ProductsResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.ProductsResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.ProductItem{Product: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *Products) Iterate(opts akeneo.QueryOptions) *akeneo.ProductIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *Products) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.ProductIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.ProductItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.ProductItem{Product: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewProductIterator(items)
+}
+
+func (d *Products) Get(code string) (*akeneo.Product, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *Products) GetWithContext(ctx context.Context, code string) (*akeneo.Product, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Product", code)
+	}
+
+	return d.copy(item), nil
+}
+
+func (d *Products) Create(item *akeneo.Product) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), item)
+}
+
+func (d *Products) CreateWithContext(ctx context.Context, item *akeneo.Product) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Identifier == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey("", item.Identifier)
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "Product \"%s\" already exists.", item.Identifier)
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *Products) Upsert(item *akeneo.Product) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), item)
+}
+
+func (d *Products) UpsertWithContext(ctx context.Context, item *akeneo.Product) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Identifier == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey("", item.Identifier)] = d.copy(item)
+
+	return nil
+}
+
+func (d *Products) BatchUpsert(items []*akeneo.Product) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), items)
+}
+
+func (d *Products) BatchUpsertWithContext(ctx context.Context, items []*akeneo.Product) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey("", item.Identifier)
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.Identifier == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.Identifier, true, status))
+	}
+
+	return lines, nil
+}
+
+func (d *Products) Delete(code string) *akeneo.ApiError {
+	return d.DeleteWithContext(context.Background(), code)
+}
+
+func (d *Products) DeleteWithContext(ctx context.Context, code string) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	key := itemKey("", code)
+	if _, ok := d.items[key]; !ok {
+		return notFound("Product", code)
+	}
+
+	delete(d.items, key)
+
+	return nil
+}
+
+// ProductModels is an in-memory akeneo.ProductModelService. Upsert replaces the
+// stored resource instead of merging the patch into it.
+type ProductModels struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.ProductModel
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewProductModels() *ProductModels {
+	return &ProductModels{items: map[string]*akeneo.ProductModel{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *ProductModels) Add(items ...*akeneo.ProductModel) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *ProductModels) All() []*akeneo.ProductModel {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.ProductModel
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *ProductModels) copy(item *akeneo.ProductModel) *akeneo.ProductModel {
+	c := &akeneo.ProductModel{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *ProductModels) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *ProductModels) GetAll(opts akeneo.QueryOptions) (*akeneo.ProductModelResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *ProductModels) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.ProductModelResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.ProductModelResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.ProductModelItem{ProductModel: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *ProductModels) Iterate(opts akeneo.QueryOptions) *akeneo.ProductModelIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *ProductModels) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.ProductModelIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.ProductModelItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.ProductModelItem{ProductModel: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewProductModelIterator(items)
+}
+
+func (d *ProductModels) Get(code string) (*akeneo.ProductModel, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *ProductModels) GetWithContext(ctx context.Context, code string) (*akeneo.ProductModel, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Product model", code)
+	}
+
+	return d.copy(item), nil
+}
+
+func (d *ProductModels) Create(item *akeneo.ProductModel) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), item)
+}
+
+func (d *ProductModels) CreateWithContext(ctx context.Context, item *akeneo.ProductModel) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey("", item.Code)
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "Product model \"%s\" already exists.", item.Code)
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *ProductModels) Upsert(item *akeneo.ProductModel) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), item)
+}
+
+func (d *ProductModels) UpsertWithContext(ctx context.Context, item *akeneo.ProductModel) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey("", item.Code)] = d.copy(item)
+
+	return nil
+}
+
+func (d *ProductModels) BatchUpsert(items []*akeneo.ProductModel) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), items)
+}
+
+func (d *ProductModels) BatchUpsertWithContext(ctx context.Context, items []*akeneo.ProductModel) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey("", item.Code)
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.Code == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.Code, false, status))
+	}
+
+	return lines, nil
+}
+
+// Families is an in-memory akeneo.FamilyService. Upsert replaces the
+// stored resource instead of merging the patch into it.
+type Families struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.Family
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewFamilies() *Families {
+	return &Families{items: map[string]*akeneo.Family{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *Families) Add(items ...*akeneo.Family) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *Families) All() []*akeneo.Family {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.Family
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *Families) copy(item *akeneo.Family) *akeneo.Family {
+	c := &akeneo.Family{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *Families) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *Families) GetAll(opts akeneo.QueryOptions) (*akeneo.FamiliesResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *Families) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.FamiliesResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.FamiliesResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.FamilyItem{Family: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *Families) Iterate(opts akeneo.QueryOptions) *akeneo.FamilyIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *Families) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.FamilyIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.FamilyItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.FamilyItem{Family: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewFamilyIterator(items)
+}
+
+func (d *Families) Get(code string) (*akeneo.Family, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *Families) GetWithContext(ctx context.Context, code string) (*akeneo.Family, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Family", code)
+	}
+
+	return d.copy(item), nil
+}
+
+func (d *Families) Create(item *akeneo.Family) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), item)
+}
+
+func (d *Families) CreateWithContext(ctx context.Context, item *akeneo.Family) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey("", item.Code)
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "Family \"%s\" already exists.", item.Code)
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *Families) Upsert(item *akeneo.Family) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), item)
+}
+
+func (d *Families) UpsertWithContext(ctx context.Context, item *akeneo.Family) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey("", item.Code)] = d.copy(item)
+
+	return nil
+}
+
+func (d *Families) BatchUpsert(items []*akeneo.Family) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), items)
+}
+
+func (d *Families) BatchUpsertWithContext(ctx context.Context, items []*akeneo.Family) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey("", item.Code)
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.Code == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.Code, false, status))
+	}
+
+	return lines, nil
+}
+
+// FamilyVariants is an in-memory akeneo.FamilyVariantService. Upsert replaces the
+// stored resource instead of merging the patch into it.
+type FamilyVariants struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.FamilyVariant
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewFamilyVariants() *FamilyVariants {
+	return &FamilyVariants{items: map[string]*akeneo.FamilyVariant{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *FamilyVariants) Add(familyCode string, items ...*akeneo.FamilyVariant) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey(familyCode, item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *FamilyVariants) All(familyCode string) []*akeneo.FamilyVariant {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.FamilyVariant
+	for _, key := range d.keys(familyCode) {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *FamilyVariants) copy(item *akeneo.FamilyVariant) *akeneo.FamilyVariant {
+	c := &akeneo.FamilyVariant{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *FamilyVariants) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *FamilyVariants) GetAll(familyCode string, opts akeneo.QueryOptions) (*akeneo.FamilyVariantsResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), familyCode, opts)
+}
+
+func (d *FamilyVariants) GetAllWithContext(ctx context.Context, familyCode string, opts akeneo.QueryOptions) (*akeneo.FamilyVariantsResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(familyCode), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.FamilyVariantsResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, *d.copy(d.items[key]))
+	}
+
+	return resp, nil
+}
+
+func (d *FamilyVariants) Iterate(familyCode string, opts akeneo.QueryOptions) *akeneo.FamilyVariantIterator {
+	return d.IterateWithContext(context.Background(), familyCode, opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *FamilyVariants) IterateWithContext(ctx context.Context, familyCode string, opts akeneo.QueryOptions) *akeneo.FamilyVariantIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.FamilyVariant
+	if d.Err == nil {
+		for _, key := range d.keys(familyCode) {
+			items = append(items, *d.copy(d.items[key]))
+		}
+	}
+
+	return akeneo.NewFamilyVariantIterator(items)
+}
+
+func (d *FamilyVariants) Get(familyCode string, code string) (*akeneo.FamilyVariant, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), familyCode, code)
+}
+
+func (d *FamilyVariants) GetWithContext(ctx context.Context, familyCode string, code string) (*akeneo.FamilyVariant, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey(familyCode, code)]
+	if !ok {
+		return nil, notFound("Family variant", code)
+	}
+
+	return d.copy(item), nil
+}
+
+func (d *FamilyVariants) Create(familyCode string, item *akeneo.FamilyVariant) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), familyCode, item)
+}
+
+func (d *FamilyVariants) CreateWithContext(ctx context.Context, familyCode string, item *akeneo.FamilyVariant) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey(familyCode, item.Code)
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "Family variant \"%s\" already exists.", item.Code)
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *FamilyVariants) Upsert(familyCode string, item *akeneo.FamilyVariant) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), familyCode, item)
+}
+
+func (d *FamilyVariants) UpsertWithContext(ctx context.Context, familyCode string, item *akeneo.FamilyVariant) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey(familyCode, item.Code)] = d.copy(item)
+
+	return nil
+}
+
+func (d *FamilyVariants) BatchUpsert(familyCode string, items []*akeneo.FamilyVariant) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), familyCode, items)
+}
+
+func (d *FamilyVariants) BatchUpsertWithContext(ctx context.Context, familyCode string, items []*akeneo.FamilyVariant) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey(familyCode, item.Code)
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.Code == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.Code, false, status))
+	}
+
+	return lines, nil
+}
+
+// Attributes is an in-memory akeneo.AttributeService. Upsert replaces the
+// stored resource instead of merging the patch into it.
+type Attributes struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.Attribute
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewAttributes() *Attributes {
+	return &Attributes{items: map[string]*akeneo.Attribute{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *Attributes) Add(items ...*akeneo.Attribute) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *Attributes) All() []*akeneo.Attribute {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.Attribute
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *Attributes) copy(item *akeneo.Attribute) *akeneo.Attribute {
+	c := &akeneo.Attribute{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *Attributes) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *Attributes) GetAll(opts akeneo.QueryOptions) (*akeneo.AttributesResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *Attributes) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.AttributesResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.AttributesResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.AttributeItem{Attribute: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *Attributes) Iterate(opts akeneo.QueryOptions) *akeneo.AttributeIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *Attributes) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.AttributeIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.AttributeItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.AttributeItem{Attribute: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewAttributeIterator(items)
+}
+
+func (d *Attributes) Get(code string) (*akeneo.Attribute, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *Attributes) GetWithContext(ctx context.Context, code string) (*akeneo.Attribute, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Attribute", code)
+	}
+
+	return d.copy(item), nil
+}
+
+func (d *Attributes) Create(item *akeneo.Attribute) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), item)
+}
+
+func (d *Attributes) CreateWithContext(ctx context.Context, item *akeneo.Attribute) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey("", item.Code)
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "Attribute \"%s\" already exists.", item.Code)
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *Attributes) Upsert(item *akeneo.Attribute) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), item)
+}
+
+func (d *Attributes) UpsertWithContext(ctx context.Context, item *akeneo.Attribute) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey("", item.Code)] = d.copy(item)
+
+	return nil
+}
+
+func (d *Attributes) BatchUpsert(items []*akeneo.Attribute) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), items)
+}
+
+func (d *Attributes) BatchUpsertWithContext(ctx context.Context, items []*akeneo.Attribute) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey("", item.Code)
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.Code == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.Code, false, status))
+	}
+
+	return lines, nil
+}
+
+// AttributeOptions is an in-memory akeneo.AttributeOptionService. Upsert replaces the
+// stored resource instead of merging the patch into it.
+type AttributeOptions struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.AttributeOption
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewAttributeOptions() *AttributeOptions {
+	return &AttributeOptions{items: map[string]*akeneo.AttributeOption{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *AttributeOptions) Add(attributeCode string, items ...*akeneo.AttributeOption) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey(attributeCode, item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *AttributeOptions) All(attributeCode string) []*akeneo.AttributeOption {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.AttributeOption
+	for _, key := range d.keys(attributeCode) {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *AttributeOptions) copy(item *akeneo.AttributeOption) *akeneo.AttributeOption {
+	c := &akeneo.AttributeOption{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *AttributeOptions) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *AttributeOptions) GetAll(attributeCode string, opts akeneo.QueryOptions) (*akeneo.AttributeOptionsResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), attributeCode, opts)
+}
+
+func (d *AttributeOptions) GetAllWithContext(ctx context.Context, attributeCode string, opts akeneo.QueryOptions) (*akeneo.AttributeOptionsResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(attributeCode), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.AttributeOptionsResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.AttributeOptionItem{AttributeOption: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *AttributeOptions) Iterate(attributeCode string, opts akeneo.QueryOptions) *akeneo.AttributeOptionIterator {
+	return d.IterateWithContext(context.Background(), attributeCode, opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *AttributeOptions) IterateWithContext(ctx context.Context, attributeCode string, opts akeneo.QueryOptions) *akeneo.AttributeOptionIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.AttributeOptionItem
+	if d.Err == nil {
+		for _, key := range d.keys(attributeCode) {
+			items = append(items, akeneo.AttributeOptionItem{AttributeOption: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewAttributeOptionIterator(items)
+}
+
+func (d *AttributeOptions) Get(attributeCode string, code string) (*akeneo.AttributeOption, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), attributeCode, code)
+}
+
+func (d *AttributeOptions) GetWithContext(ctx context.Context, attributeCode string, code string) (*akeneo.AttributeOption, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey(attributeCode, code)]
+	if !ok {
+		return nil, notFound("Attribute option", code)
+	}
+
+	return d.copy(item), nil
+}
+
+func (d *AttributeOptions) Create(attributeCode string, item *akeneo.AttributeOption) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), attributeCode, item)
+}
+
+func (d *AttributeOptions) CreateWithContext(ctx context.Context, attributeCode string, item *akeneo.AttributeOption) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey(attributeCode, item.Code)
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "Attribute option \"%s\" already exists.", item.Code)
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *AttributeOptions) Upsert(attributeCode string, item *akeneo.AttributeOption) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), attributeCode, item)
+}
+
+func (d *AttributeOptions) UpsertWithContext(ctx context.Context, attributeCode string, item *akeneo.AttributeOption) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey(attributeCode, item.Code)] = d.copy(item)
+
+	return nil
+}
+
+func (d *AttributeOptions) BatchUpsert(attributeCode string, items []*akeneo.AttributeOption) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), attributeCode, items)
+}
+
+func (d *AttributeOptions) BatchUpsertWithContext(ctx context.Context, attributeCode string, items []*akeneo.AttributeOption) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey(attributeCode, item.Code)
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.Code == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.Code, false, status))
+	}
+
+	return lines, nil
+}
+
+// AttributeGroups is an in-memory akeneo.AttributeGroupService. Upsert replaces the
+// stored resource instead of merging the patch into it.
+type AttributeGroups struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.AttributeGroup
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewAttributeGroups() *AttributeGroups {
+	return &AttributeGroups{items: map[string]*akeneo.AttributeGroup{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *AttributeGroups) Add(items ...*akeneo.AttributeGroup) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *AttributeGroups) All() []*akeneo.AttributeGroup {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.AttributeGroup
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *AttributeGroups) copy(item *akeneo.AttributeGroup) *akeneo.AttributeGroup {
+	c := &akeneo.AttributeGroup{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *AttributeGroups) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *AttributeGroups) GetAll(opts akeneo.QueryOptions) (*akeneo.AttributeGroupsResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *AttributeGroups) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.AttributeGroupsResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.AttributeGroupsResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.AttributeGroupItem{AttributeGroup: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *AttributeGroups) Iterate(opts akeneo.QueryOptions) *akeneo.AttributeGroupIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *AttributeGroups) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.AttributeGroupIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.AttributeGroupItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.AttributeGroupItem{AttributeGroup: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewAttributeGroupIterator(items)
+}
+
+func (d *AttributeGroups) Get(code string) (*akeneo.AttributeGroup, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *AttributeGroups) GetWithContext(ctx context.Context, code string) (*akeneo.AttributeGroup, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Attribute group", code)
+	}
+
+	return d.copy(item), nil
+}
+
+func (d *AttributeGroups) Create(item *akeneo.AttributeGroup) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), item)
+}
+
+func (d *AttributeGroups) CreateWithContext(ctx context.Context, item *akeneo.AttributeGroup) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey("", item.Code)
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "Attribute group \"%s\" already exists.", item.Code)
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *AttributeGroups) Upsert(item *akeneo.AttributeGroup) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), item)
+}
+
+func (d *AttributeGroups) UpsertWithContext(ctx context.Context, item *akeneo.AttributeGroup) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey("", item.Code)] = d.copy(item)
+
+	return nil
+}
+
+func (d *AttributeGroups) BatchUpsert(items []*akeneo.AttributeGroup) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), items)
+}
+
+func (d *AttributeGroups) BatchUpsertWithContext(ctx context.Context, items []*akeneo.AttributeGroup) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey("", item.Code)
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.Code == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.Code, false, status))
+	}
+
+	return lines, nil
+}
+
+// AssociationTypes is an in-memory akeneo.AssociationTypeService. Upsert replaces the
+// stored resource instead of merging the patch into it.
+type AssociationTypes struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.AssociationType
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewAssociationTypes() *AssociationTypes {
+	return &AssociationTypes{items: map[string]*akeneo.AssociationType{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *AssociationTypes) Add(items ...*akeneo.AssociationType) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *AssociationTypes) All() []*akeneo.AssociationType {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.AssociationType
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *AssociationTypes) copy(item *akeneo.AssociationType) *akeneo.AssociationType {
+	c := &akeneo.AssociationType{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *AssociationTypes) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *AssociationTypes) GetAll(opts akeneo.QueryOptions) (*akeneo.AssociationTypeResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *AssociationTypes) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.AssociationTypeResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.AssociationTypeResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.AssociationTypeItem{AssociationType: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *AssociationTypes) Iterate(opts akeneo.QueryOptions) *akeneo.AssociationTypeIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *AssociationTypes) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.AssociationTypeIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.AssociationTypeItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.AssociationTypeItem{AssociationType: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewAssociationTypeIterator(items)
+}
+
+func (d *AssociationTypes) Get(code string) (*akeneo.AssociationType, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *AssociationTypes) GetWithContext(ctx context.Context, code string) (*akeneo.AssociationType, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Association type", code)
+	}
+
+	return d.copy(item), nil
+}
+
+func (d *AssociationTypes) Create(item *akeneo.AssociationType) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), item)
+}
+
+func (d *AssociationTypes) CreateWithContext(ctx context.Context, item *akeneo.AssociationType) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey("", item.Code)
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "Association type \"%s\" already exists.", item.Code)
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *AssociationTypes) Upsert(item *akeneo.AssociationType) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), item)
+}
+
+func (d *AssociationTypes) UpsertWithContext(ctx context.Context, item *akeneo.AssociationType) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey("", item.Code)] = d.copy(item)
+
+	return nil
+}
+
+func (d *AssociationTypes) BatchUpsert(items []*akeneo.AssociationType) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), items)
+}
+
+func (d *AssociationTypes) BatchUpsertWithContext(ctx context.Context, items []*akeneo.AssociationType) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey("", item.Code)
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.Code == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.Code, false, status))
+	}
+
+	return lines, nil
+}
+
+// Categories is an in-memory akeneo.CategoryService. Upsert replaces the
+// stored resource instead of merging the patch into it.
+type Categories struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.Category
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewCategories() *Categories {
+	return &Categories{items: map[string]*akeneo.Category{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *Categories) Add(items ...*akeneo.Category) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *Categories) All() []*akeneo.Category {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.Category
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *Categories) copy(item *akeneo.Category) *akeneo.Category {
+	c := &akeneo.Category{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *Categories) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *Categories) GetAll(opts akeneo.QueryOptions) (*akeneo.CategoriesResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *Categories) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.CategoriesResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.CategoriesResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.CategoryItem{Category: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *Categories) Iterate(opts akeneo.QueryOptions) *akeneo.CategoryIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *Categories) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.CategoryIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.CategoryItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.CategoryItem{Category: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewCategoryIterator(items)
+}
+
+func (d *Categories) Get(code string) (*akeneo.Category, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *Categories) GetWithContext(ctx context.Context, code string) (*akeneo.Category, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Category", code)
+	}
+
+	return d.copy(item), nil
+}
+
+func (d *Categories) Create(item *akeneo.Category) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), item)
+}
+
+func (d *Categories) CreateWithContext(ctx context.Context, item *akeneo.Category) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey("", item.Code)
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "Category \"%s\" already exists.", item.Code)
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *Categories) Upsert(item *akeneo.Category) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), item)
+}
+
+func (d *Categories) UpsertWithContext(ctx context.Context, item *akeneo.Category) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey("", item.Code)] = d.copy(item)
+
+	return nil
+}
+
+func (d *Categories) BatchUpsert(items []*akeneo.Category) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), items)
+}
+
+func (d *Categories) BatchUpsertWithContext(ctx context.Context, items []*akeneo.Category) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey("", item.Code)
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.Code == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.Code, false, status))
+	}
+
+	return lines, nil
+}
+
+// Channels is an in-memory akeneo.ChannelService. Upsert replaces the
+// stored resource instead of merging the patch into it.
+type Channels struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.Channel
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewChannels() *Channels {
+	return &Channels{items: map[string]*akeneo.Channel{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *Channels) Add(items ...*akeneo.Channel) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *Channels) All() []*akeneo.Channel {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.Channel
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *Channels) copy(item *akeneo.Channel) *akeneo.Channel {
+	c := &akeneo.Channel{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *Channels) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *Channels) GetAll(opts akeneo.QueryOptions) (*akeneo.ChannelResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *Channels) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.ChannelResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.ChannelResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.ChannelItem{Channel: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *Channels) Iterate(opts akeneo.QueryOptions) *akeneo.ChannelIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *Channels) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.ChannelIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.ChannelItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.ChannelItem{Channel: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewChannelIterator(items)
+}
+
+func (d *Channels) Get(code string) (*akeneo.Channel, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *Channels) GetWithContext(ctx context.Context, code string) (*akeneo.Channel, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Channel", code)
+	}
+
+	return d.copy(item), nil
+}
+
+func (d *Channels) Create(item *akeneo.Channel) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), item)
+}
+
+func (d *Channels) CreateWithContext(ctx context.Context, item *akeneo.Channel) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey("", item.Code)
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "Channel \"%s\" already exists.", item.Code)
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *Channels) Upsert(item *akeneo.Channel) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), item)
+}
+
+func (d *Channels) UpsertWithContext(ctx context.Context, item *akeneo.Channel) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.Code == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey("", item.Code)] = d.copy(item)
+
+	return nil
+}
+
+func (d *Channels) BatchUpsert(items []*akeneo.Channel) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), items)
+}
+
+func (d *Channels) BatchUpsertWithContext(ctx context.Context, items []*akeneo.Channel) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey("", item.Code)
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.Code == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.Code, false, status))
+	}
+
+	return lines, nil
+}
+
+// Locales is an in-memory akeneo.LocaleService.
+type Locales struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.Locale
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewLocales() *Locales {
+	return &Locales{items: map[string]*akeneo.Locale{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *Locales) Add(items ...*akeneo.Locale) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *Locales) All() []*akeneo.Locale {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.Locale
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *Locales) copy(item *akeneo.Locale) *akeneo.Locale {
+	c := &akeneo.Locale{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *Locales) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *Locales) GetAll(opts akeneo.QueryOptions) (*akeneo.LocaleResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *Locales) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.LocaleResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.LocaleResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.LocaleItem{Locale: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *Locales) Iterate(opts akeneo.QueryOptions) *akeneo.LocaleIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *Locales) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.LocaleIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.LocaleItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.LocaleItem{Locale: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewLocaleIterator(items)
+}
+
+func (d *Locales) Get(code string) (*akeneo.Locale, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *Locales) GetWithContext(ctx context.Context, code string) (*akeneo.Locale, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Locale", code)
+	}
+
+	return d.copy(item), nil
+}
+
+// Currencies is an in-memory akeneo.CurrencyService.
+type Currencies struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.Currency
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewCurrencies() *Currencies {
+	return &Currencies{items: map[string]*akeneo.Currency{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *Currencies) Add(items ...*akeneo.Currency) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *Currencies) All() []*akeneo.Currency {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.Currency
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *Currencies) copy(item *akeneo.Currency) *akeneo.Currency {
+	c := &akeneo.Currency{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *Currencies) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *Currencies) GetAll(opts akeneo.QueryOptions) (*akeneo.CurrencyResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *Currencies) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.CurrencyResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.CurrencyResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.CurrencyItem{Currency: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *Currencies) Iterate(opts akeneo.QueryOptions) *akeneo.CurrencyIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *Currencies) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.CurrencyIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.CurrencyItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.CurrencyItem{Currency: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewCurrencyIterator(items)
+}
+
+func (d *Currencies) Get(code string) (*akeneo.Currency, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *Currencies) GetWithContext(ctx context.Context, code string) (*akeneo.Currency, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Currency", code)
+	}
+
+	return d.copy(item), nil
+}
+
+// MeasureFamilies is an in-memory akeneo.MeasureFamilyService.
+type MeasureFamilies struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.MeasureFamily
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewMeasureFamilies() *MeasureFamilies {
+	return &MeasureFamilies{items: map[string]*akeneo.MeasureFamily{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *MeasureFamilies) Add(items ...*akeneo.MeasureFamily) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey("", item.Code)] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by code.
+func (d *MeasureFamilies) All() []*akeneo.MeasureFamily {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.MeasureFamily
+	for _, key := range d.keys("") {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *MeasureFamilies) copy(item *akeneo.MeasureFamily) *akeneo.MeasureFamily {
+	c := &akeneo.MeasureFamily{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *MeasureFamilies) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.Code) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *MeasureFamilies) GetAll(opts akeneo.QueryOptions) (*akeneo.MeasureFamilyResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *MeasureFamilies) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.MeasureFamilyResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(""), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.MeasureFamilyResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.MeasureFamilyItem{MeasureFamily: *d.copy(d.items[key])})
+	}
+
+	return resp, nil
+}
+
+func (d *MeasureFamilies) Iterate(opts akeneo.QueryOptions) *akeneo.MeasureFamilyIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *MeasureFamilies) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.MeasureFamilyIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.MeasureFamilyItem
+	if d.Err == nil {
+		for _, key := range d.keys("") {
+			items = append(items, akeneo.MeasureFamilyItem{MeasureFamily: *d.copy(d.items[key])})
+		}
+	}
+
+	return akeneo.NewMeasureFamilyIterator(items)
+}
+
+func (d *MeasureFamilies) Get(code string) (*akeneo.MeasureFamily, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *MeasureFamilies) GetWithContext(ctx context.Context, code string) (*akeneo.MeasureFamily, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey("", code)]
+	if !ok {
+		return nil, notFound("Measure family", code)
+	}
+
+	return d.copy(item), nil
+}
+
+var (
+	_ akeneo.ProductService         = (*Products)(nil)
+	_ akeneo.ProductModelService    = (*ProductModels)(nil)
+	_ akeneo.FamilyService          = (*Families)(nil)
+	_ akeneo.FamilyVariantService   = (*FamilyVariants)(nil)
+	_ akeneo.AttributeService       = (*Attributes)(nil)
+	_ akeneo.AttributeOptionService = (*AttributeOptions)(nil)
+	_ akeneo.AttributeGroupService  = (*AttributeGroups)(nil)
+	_ akeneo.AssociationTypeService = (*AssociationTypes)(nil)
+	_ akeneo.CategoryService        = (*Categories)(nil)
+	_ akeneo.ChannelService         = (*Channels)(nil)
+	_ akeneo.LocaleService          = (*Locales)(nil)
+	_ akeneo.CurrencyService        = (*Currencies)(nil)
+	_ akeneo.MeasureFamilyService   = (*MeasureFamilies)(nil)
+)
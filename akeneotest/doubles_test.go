@@ -0,0 +1,169 @@
+package akeneotest
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+func TestCatalogApiUsesDoubles(t *testing.T) {
+	catalog := NewCatalog()
+	api := catalog.Api()
+
+	if err := api.Product.Create(&akeneo.Product{Identifier: "sku-1", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if products := catalog.Products.All(); len(products) != 1 || products[0].Identifier != "sku-1" {
+		t.Errorf("unexpected products %+v", products)
+	}
+}
+
+func TestProductsDoubleBehavesLikeAkeneo(t *testing.T) {
+	products := NewProducts()
+
+	if err := products.Create(&akeneo.Product{Identifier: "sku-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := products.Create(&akeneo.Product{Identifier: "sku-1"}); err == nil || err.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected a 422 for a duplicate, got %v", err)
+	}
+	if _, err := products.Get("missing"); err == nil || !akeneo.IsNotFound(err) {
+		t.Errorf("expected a 404, got %v", err)
+	}
+	if err := products.Delete("missing"); err == nil || !akeneo.IsNotFound(err) {
+		t.Errorf("expected a 404, got %v", err)
+	}
+
+	lines, err := products.BatchUpsert([]*akeneo.Product{{Identifier: "sku-1"}, {Identifier: "sku-2"}, {Identifier: ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int32{http.StatusNoContent, http.StatusCreated, http.StatusUnprocessableEntity}
+	for i, line := range lines {
+		if line.Line != int32(i+1) || line.StatusCode != expected[i] {
+			t.Errorf("line %d: unexpected %+v", i+1, line)
+		}
+	}
+}
+
+func TestDoublesReturnCopies(t *testing.T) {
+	products := NewProducts()
+	product := &akeneo.Product{Identifier: "sku-1", Categories: []string{"shoes"}}
+	products.Add(product)
+
+	product.Categories[0] = "changed"
+	stored, _ := products.Get("sku-1")
+	if stored.Categories[0] != "shoes" {
+		t.Error("the double kept a reference to the added product")
+	}
+
+	stored.Categories[0] = "changed"
+	if again, _ := products.Get("sku-1"); again.Categories[0] != "shoes" {
+		t.Error("the double returned a reference to its stored product")
+	}
+}
+
+func TestMeasureFamiliesDoubleKeepsHandBuiltUnits(t *testing.T) {
+	families := NewMeasureFamilies()
+	families.Add(&akeneo.MeasureFamily{
+		Code:     "Temperature",
+		Standard: "KELVIN",
+		Units: []*akeneo.FamilyUnit{
+			{Code: "KELVIN", Convert: map[string]string{"mul": "1"}},
+			{Code: "FAHRENHEIT", Convert: map[string]string{"sub": "32", "div": "1.8", "add": "273.15"}},
+		},
+	})
+
+	family, err := families.Get("Temperature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if convert := family.Units[1].Convert; len(convert) != 3 || convert["div"] != "1.8" {
+		t.Errorf("unexpected conversion %v", convert)
+	}
+}
+
+func TestDoublesPaginateAndIterate(t *testing.T) {
+	categories := NewCategories()
+	for _, code := range []string{"e", "d", "c", "b", "a"} {
+		categories.Add(&akeneo.Category{Code: code})
+	}
+
+	response, err := categories.GetAll(akeneo.PageOptions{Page: 2, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.CurrentPage != 2 || len(response.Data.Items) != 2 || response.Data.Items[0].Code != "c" {
+		t.Errorf("unexpected page %+v", response)
+	}
+
+	if _, err := categories.GetAll(akeneo.PageOptions{Limit: 1000}); err == nil {
+		t.Error("expected an error for an invalid limit")
+	}
+
+	var codes []string
+	it := categories.Iterate(nil)
+	for it.Next() {
+		codes = append(codes, it.Item().Code)
+	}
+	if len(codes) != 5 || codes[0] != "a" || codes[4] != "e" {
+		t.Errorf("unexpected codes %v", codes)
+	}
+}
+
+func TestDoublesReturnErr(t *testing.T) {
+	families := NewFamilies()
+	families.Add(&akeneo.Family{Code: "shoes"})
+	families.Err = &akeneo.ApiError{Code: http.StatusServiceUnavailable, Message: "maintenance"}
+
+	if _, err := families.Get("shoes"); err != families.Err {
+		t.Errorf("expected Err, got %v", err)
+	}
+	if it := families.Iterate(nil); it.Next() {
+		t.Error("expected an empty iteration")
+	}
+}
+
+func TestDoublesHonourContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := NewChannels().CreateWithContext(ctx, &akeneo.Channel{Code: "ecommerce"}); err == nil || err.Err != context.Canceled {
+		t.Errorf("expected a cancelled context error, got %v", err)
+	}
+}
+
+func TestMediaFilesDouble(t *testing.T) {
+	media := NewMediaFiles()
+	content := []byte("\x89PNG\r\n\x1a\nimage")
+
+	if err := media.Create(&akeneo.MediaFileBody{FileName: "shoe.png", File: content}); err != nil {
+		t.Fatal(err)
+	}
+	if len(media.Uploads) != 1 {
+		t.Fatalf("expected one upload, got %d", len(media.Uploads))
+	}
+
+	it := media.Iterate(nil)
+	if !it.Next() {
+		t.Fatal("expected a media file")
+	}
+	file := it.Item().MediaFile
+	if file.OriginalFilename != "shoe.png" || file.Extension != "png" || file.MimiType != "image/png" {
+		t.Errorf("unexpected media file %+v", file)
+	}
+
+	folder := t.TempDir()
+	if err := media.Download(file.Code, folder); err != nil {
+		t.Fatal(err)
+	}
+	downloaded, err := ioutil.ReadFile(filepath.Join(folder, file.Code))
+	if err != nil || string(downloaded) != string(content) {
+		t.Errorf("unexpected download %q, %v", downloaded, err)
+	}
+}
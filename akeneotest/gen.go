@@ -0,0 +1,335 @@
+//go:build ignore
+// +build ignore
+
+// gen.go writes doubles_gen.go, the in-memory doubles of the akeneo
+// resource services. Run it with `go generate` after a service changes.
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"text/template"
+)
+
+type double struct {
+	Name      string
+	Service   string
+	Type      string
+	Item      string
+	Wrapped   bool
+	Response  string
+	Key       string
+	Label     string
+	Parent    string
+	Writable  bool
+	Deletable bool
+}
+
+var doubles = []double{
+	{Name: "Products", Service: "ProductService", Type: "Product", Item: "ProductItem", Wrapped: true, Response: "ProductsResponse", Key: "Identifier", Label: "Product", Writable: true, Deletable: true},
+	{Name: "ProductModels", Service: "ProductModelService", Type: "ProductModel", Item: "ProductModelItem", Wrapped: true, Response: "ProductModelResponse", Key: "Code", Label: "Product model", Writable: true},
+	{Name: "Families", Service: "FamilyService", Type: "Family", Item: "FamilyItem", Wrapped: true, Response: "FamiliesResponse", Key: "Code", Label: "Family", Writable: true},
+	{Name: "FamilyVariants", Service: "FamilyVariantService", Type: "FamilyVariant", Item: "FamilyVariant", Response: "FamilyVariantsResponse", Key: "Code", Label: "Family variant", Parent: "familyCode", Writable: true},
+	{Name: "Attributes", Service: "AttributeService", Type: "Attribute", Item: "AttributeItem", Wrapped: true, Response: "AttributesResponse", Key: "Code", Label: "Attribute", Writable: true},
+	{Name: "AttributeOptions", Service: "AttributeOptionService", Type: "AttributeOption", Item: "AttributeOptionItem", Wrapped: true, Response: "AttributeOptionsResponse", Key: "Code", Label: "Attribute option", Parent: "attributeCode", Writable: true},
+	{Name: "AttributeGroups", Service: "AttributeGroupService", Type: "AttributeGroup", Item: "AttributeGroupItem", Wrapped: true, Response: "AttributeGroupsResponse", Key: "Code", Label: "Attribute group", Writable: true},
+	{Name: "AssociationTypes", Service: "AssociationTypeService", Type: "AssociationType", Item: "AssociationTypeItem", Wrapped: true, Response: "AssociationTypeResponse", Key: "Code", Label: "Association type", Writable: true},
+	{Name: "Categories", Service: "CategoryService", Type: "Category", Item: "CategoryItem", Wrapped: true, Response: "CategoriesResponse", Key: "Code", Label: "Category", Writable: true},
+	{Name: "Channels", Service: "ChannelService", Type: "Channel", Item: "ChannelItem", Wrapped: true, Response: "ChannelResponse", Key: "Code", Label: "Channel", Writable: true},
+	{Name: "Locales", Service: "LocaleService", Type: "Locale", Item: "LocaleItem", Wrapped: true, Response: "LocaleResponse", Key: "Code", Label: "Locale"},
+	{Name: "Currencies", Service: "CurrencyService", Type: "Currency", Item: "CurrencyItem", Wrapped: true, Response: "CurrencyResponse", Key: "Code", Label: "Currency"},
+	{Name: "MeasureFamilies", Service: "MeasureFamilyService", Type: "MeasureFamily", Item: "MeasureFamilyItem", Wrapped: true, Response: "MeasureFamilyResponse", Key: "Code", Label: "Measure family"},
+}
+
+var source = template.Must(template.New("doubles").Parse(`// Code generated by gen.go; DO NOT EDIT.
+
+package akeneotest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+{{range .}}
+{{- $p := "" }}{{ $pa := "" }}{{ $pv := "\"\"" }}
+{{- if .Parent }}{{ $p = printf "%s string, " .Parent }}{{ $pa = printf "%s, " .Parent }}{{ $pv = .Parent }}{{ end }}
+// {{.Name}} is an in-memory akeneo.{{.Service}}.
+{{- if .Writable }} Upsert replaces the
+// stored resource instead of merging the patch into it.{{ end }}
+type {{.Name}} struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.{{.Type}}
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func New{{.Name}}() *{{.Name}} {
+	return &{{.Name}}{items: map[string]*akeneo.{{.Type}}{}}
+}
+
+// Add stores copies of the items as they are, without any check.
+func (d *{{.Name}}) Add({{$p}}items ...*akeneo.{{.Type}}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		d.items[itemKey({{$pv}}, item.{{.Key}})] = d.copy(item)
+	}
+}
+
+// All returns copies of the stored items sorted by {{if eq .Key "Identifier"}}identifier{{else}}code{{end}}.
+func (d *{{.Name}}) All({{if .Parent}}{{.Parent}} string{{end}}) []*akeneo.{{.Type}} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []*akeneo.{{.Type}}
+	for _, key := range d.keys({{$pv}}) {
+		items = append(items, d.copy(d.items[key]))
+	}
+
+	return items
+}
+
+func (d *{{.Name}}) copy(item *akeneo.{{.Type}}) *akeneo.{{.Type}} {
+	c := &akeneo.{{.Type}}{}
+	clone(item, c)
+
+	return c
+}
+
+func (d *{{.Name}}) keys(parent string) []string {
+	var keys []string
+	for key, item := range d.items {
+		if key == itemKey(parent, item.{{.Key}}) {
+			keys = append(keys, key)
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *{{.Name}}) GetAll({{$p}}opts akeneo.QueryOptions) (*akeneo.{{.Response}}, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), {{$pa}}opts)
+}
+
+func (d *{{.Name}}) GetAllWithContext(ctx context.Context, {{$p}}opts akeneo.QueryOptions) (*akeneo.{{.Response}}, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys({{$pv}}), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.{{.Response}}{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		{{- if .Wrapped }}
+		resp.Data.Items = append(resp.Data.Items, akeneo.{{.Item}}{ {{- .Type}}: *d.copy(d.items[key])})
+		{{- else }}
+		resp.Data.Items = append(resp.Data.Items, *d.copy(d.items[key]))
+		{{- end }}
+	}
+
+	return resp, nil
+}
+
+func (d *{{.Name}}) Iterate({{$p}}opts akeneo.QueryOptions) *akeneo.{{.Type}}Iterator {
+	return d.IterateWithContext(context.Background(), {{$pa}}opts)
+}
+
+// IterateWithContext iterates over every stored item; Err, when set, makes
+// the iteration empty.
+func (d *{{.Name}}) IterateWithContext(ctx context.Context, {{$p}}opts akeneo.QueryOptions) *akeneo.{{.Type}}Iterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.{{.Item}}
+	if d.Err == nil {
+		for _, key := range d.keys({{$pv}}) {
+			{{- if .Wrapped }}
+			items = append(items, akeneo.{{.Item}}{ {{- .Type}}: *d.copy(d.items[key])})
+			{{- else }}
+			items = append(items, *d.copy(d.items[key]))
+			{{- end }}
+		}
+	}
+
+	return akeneo.New{{.Type}}Iterator(items)
+}
+
+func (d *{{.Name}}) Get({{$p}}code string) (*akeneo.{{.Type}}, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), {{$pa}}code)
+}
+
+func (d *{{.Name}}) GetWithContext(ctx context.Context, {{$p}}code string) (*akeneo.{{.Type}}, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[itemKey({{$pv}}, code)]
+	if !ok {
+		return nil, notFound("{{.Label}}", code)
+	}
+
+	return d.copy(item), nil
+}
+{{- if .Writable }}
+
+func (d *{{.Name}}) Create({{$p}}item *akeneo.{{.Type}}) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), {{$pa}}item)
+}
+
+func (d *{{.Name}}) CreateWithContext(ctx context.Context, {{$p}}item *akeneo.{{.Type}}) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.{{.Key}} == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	key := itemKey({{$pv}}, item.{{.Key}})
+	if _, ok := d.items[key]; ok {
+		return apiError(http.StatusUnprocessableEntity, "{{.Label}} \"%s\" already exists.", item.{{.Key}})
+	}
+
+	d.items[key] = d.copy(item)
+
+	return nil
+}
+
+func (d *{{.Name}}) Upsert({{$p}}item *akeneo.{{.Type}}) *akeneo.ApiError {
+	return d.UpsertWithContext(context.Background(), {{$pa}}item)
+}
+
+func (d *{{.Name}}) UpsertWithContext(ctx context.Context, {{$p}}item *akeneo.{{.Type}}) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if item.{{.Key}} == "" {
+		return apiError(http.StatusUnprocessableEntity, "Validation failed.")
+	}
+
+	d.items[itemKey({{$pv}}, item.{{.Key}})] = d.copy(item)
+
+	return nil
+}
+
+func (d *{{.Name}}) BatchUpsert({{$p}}items []*akeneo.{{.Type}}) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	return d.BatchUpsertWithContext(context.Background(), {{$pa}}items)
+}
+
+func (d *{{.Name}}) BatchUpsertWithContext(ctx context.Context, {{$p}}items []*akeneo.{{.Type}}) ([]*akeneo.ResponseBody, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	var lines []*akeneo.ResponseBody
+	for i, item := range items {
+		status := http.StatusNoContent
+
+		key := itemKey({{$pv}}, item.{{.Key}})
+		if _, ok := d.items[key]; !ok {
+			status = http.StatusCreated
+		}
+
+		if item.{{.Key}} == "" {
+			status = http.StatusUnprocessableEntity
+		} else {
+			d.items[key] = d.copy(item)
+		}
+
+		lines = append(lines, batchLine(i+1, item.{{.Key}}, {{eq .Key "Identifier"}}, status))
+	}
+
+	return lines, nil
+}
+{{- end }}
+{{- if .Deletable }}
+
+func (d *{{.Name}}) Delete({{$p}}code string) *akeneo.ApiError {
+	return d.DeleteWithContext(context.Background(), {{$pa}}code)
+}
+
+func (d *{{.Name}}) DeleteWithContext(ctx context.Context, {{$p}}code string) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	key := itemKey({{$pv}}, code)
+	if _, ok := d.items[key]; !ok {
+		return notFound("{{.Label}}", code)
+	}
+
+	delete(d.items, key)
+
+	return nil
+}
+{{- end }}
+{{end}}
+var (
+{{- range .}}
+	_ akeneo.{{.Service}} = (*{{.Name}})(nil)
+{{- end}}
+)
+`))
+
+func main() {
+	var b bytes.Buffer
+	if err := source.Execute(&b, doubles); err != nil {
+		log.Fatal(err)
+	}
+
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatalf("%v\n%s", err, b.Bytes())
+	}
+
+	if err := ioutil.WriteFile("doubles_gen.go", code, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,112 @@
+// Package akeneotest provides in-memory implementations of the akeneo
+// service interfaces, so code depending on akeneo.Api can be unit tested
+// without an HTTP server. The doubles keep what they are given in maps,
+// answer like Akeneo does for missing or duplicate resources, and return
+// Err instead when it is set.
+package akeneotest
+
+//go:generate go run gen.go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+func clone(src interface{}, dst interface{}) {
+	data, err := json.Marshal(src)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := json.Unmarshal(data, dst); err != nil {
+		panic(err)
+	}
+}
+
+func apiError(code int, format string, args ...interface{}) *akeneo.ApiError {
+	return &akeneo.ApiError{
+		Code:    code,
+		Status:  fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func notFound(resource string, code string) *akeneo.ApiError {
+	return apiError(http.StatusNotFound, "%s \"%s\" does not exist.", resource, code)
+}
+
+func contextError(ctx context.Context) *akeneo.ApiError {
+	if err := ctx.Err(); err != nil {
+		return &akeneo.ApiError{Message: err.Error(), Err: err}
+	}
+
+	return nil
+}
+
+func itemKey(parent string, code string) string {
+	if parent == "" {
+		return code
+	}
+
+	return parent + "/" + code
+}
+
+func sortedKeys(keys []string) []string {
+	sort.Strings(keys)
+	return keys
+}
+
+// paginate returns the page of keys selected by the page and limit options,
+// with the same defaults as Akeneo.
+func paginate(keys []string, opts akeneo.QueryOptions) ([]string, int, *akeneo.ApiError) {
+	page, limit := 1, 10
+
+	if opts != nil {
+		values, err := opts.QueryValues()
+		if err != nil {
+			return nil, 0, &akeneo.ApiError{Message: err.Error(), Err: err}
+		}
+
+		if parsed, err := strconv.Atoi(values.Get("page")); err == nil && parsed > 0 {
+			page = parsed
+		}
+
+		if parsed, err := strconv.Atoi(values.Get("limit")); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	start := (page - 1) * limit
+	if start > len(keys) {
+		start = len(keys)
+	}
+
+	end := start + limit
+	if end > len(keys) {
+		end = len(keys)
+	}
+
+	return keys[start:end], page, nil
+}
+
+func batchLine(line int, key string, useIdentifier bool, status int) *akeneo.ResponseBody {
+	body := &akeneo.ResponseBody{Line: int32(line), StatusCode: int32(status)}
+
+	if useIdentifier {
+		body.Identifier = key
+	} else {
+		body.Code = key
+	}
+
+	if status == http.StatusUnprocessableEntity {
+		body.Message = "Validation failed."
+	}
+
+	return body
+}
@@ -0,0 +1,209 @@
+package akeneotest
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+// MediaFiles is an in-memory akeneo.MediaFileService. Uploaded files are
+// kept in memory and Download writes them to disk like the real service.
+type MediaFiles struct {
+	mu    sync.Mutex
+	items map[string]*akeneo.MediaFile
+	files map[string][]byte
+
+	// Uploads lists every body passed to Create, in order.
+	Uploads []*akeneo.MediaFileBody
+
+	// Err, when set, is returned by every method.
+	Err *akeneo.ApiError
+}
+
+func NewMediaFiles() *MediaFiles {
+	return &MediaFiles{items: map[string]*akeneo.MediaFile{}, files: map[string][]byte{}}
+}
+
+// Add stores a media file with its content.
+func (d *MediaFiles) Add(item *akeneo.MediaFile, content []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := *item
+	d.items[item.Code] = &c
+	d.files[item.Code] = append([]byte(nil), content...)
+}
+
+// Content returns the content of a stored media file.
+func (d *MediaFiles) Content(code string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	content, ok := d.files[code]
+
+	return content, ok
+}
+
+func (d *MediaFiles) keys() []string {
+	var keys []string
+	for key := range d.items {
+		keys = append(keys, key)
+	}
+
+	return sortedKeys(keys)
+}
+
+func (d *MediaFiles) GetAll(opts akeneo.QueryOptions) (*akeneo.ProductMediaFileResponse, *akeneo.ApiError) {
+	return d.GetAllWithContext(context.Background(), opts)
+}
+
+func (d *MediaFiles) GetAllWithContext(ctx context.Context, opts akeneo.QueryOptions) (*akeneo.ProductMediaFileResponse, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, page, apiErr := paginate(d.keys(), opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	resp := &akeneo.ProductMediaFileResponse{}
+	resp.CurrentPage = page
+	for _, key := range keys {
+		resp.Data.Items = append(resp.Data.Items, akeneo.ProductMediaFileItem{MediaFile: *d.items[key]})
+	}
+
+	return resp, nil
+}
+
+func (d *MediaFiles) Iterate(opts akeneo.QueryOptions) *akeneo.MediaFileIterator {
+	return d.IterateWithContext(context.Background(), opts)
+}
+
+func (d *MediaFiles) IterateWithContext(ctx context.Context, opts akeneo.QueryOptions) *akeneo.MediaFileIterator {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var items []akeneo.ProductMediaFileItem
+	if d.Err == nil {
+		for _, key := range d.keys() {
+			items = append(items, akeneo.ProductMediaFileItem{MediaFile: *d.items[key]})
+		}
+	}
+
+	return akeneo.NewMediaFileIterator(items)
+}
+
+func (d *MediaFiles) Get(code string) (*akeneo.MediaFile, *akeneo.ApiError) {
+	return d.GetWithContext(context.Background(), code)
+}
+
+func (d *MediaFiles) GetWithContext(ctx context.Context, code string) (*akeneo.MediaFile, *akeneo.ApiError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return nil, err
+	}
+
+	item, ok := d.items[code]
+	if !ok {
+		return nil, notFound("Media file", code)
+	}
+
+	c := *item
+
+	return &c, nil
+}
+
+func (d *MediaFiles) Create(mediaFile *akeneo.MediaFileBody) *akeneo.ApiError {
+	return d.CreateWithContext(context.Background(), mediaFile)
+}
+
+// CreateWithContext stores the file under a code built like Akeneo's, from
+// the SHA-1 of the name and content. It does not update the product values.
+func (d *MediaFiles) CreateWithContext(ctx context.Context, mediaFile *akeneo.MediaFileBody) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	if mediaFile.FileName == "" {
+		return apiError(http.StatusUnprocessableEntity, "Property \"file\" is required.")
+	}
+
+	sum := sha1.Sum(append([]byte(mediaFile.FileName), mediaFile.File...))
+	hash := hex.EncodeToString(sum[:])
+	code := fmt.Sprintf("%s/%s/%s/%s/%s_%s", hash[0:1], hash[1:2], hash[2:3], hash[3:4], hash, mediaFile.FileName)
+
+	d.items[code] = &akeneo.MediaFile{
+		Code:             code,
+		OriginalFilename: mediaFile.FileName,
+		MimiType:         http.DetectContentType(mediaFile.File),
+		Size:             len(mediaFile.File),
+		Extension:        strings.TrimPrefix(filepath.Ext(mediaFile.FileName), "."),
+	}
+	d.files[code] = append([]byte(nil), mediaFile.File...)
+
+	upload := *mediaFile
+	d.Uploads = append(d.Uploads, &upload)
+
+	return nil
+}
+
+func (d *MediaFiles) Download(code string, folderPath string) *akeneo.ApiError {
+	return d.DownloadWithContext(context.Background(), code, folderPath)
+}
+
+func (d *MediaFiles) DownloadWithContext(ctx context.Context, code string, folderPath string) *akeneo.ApiError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return d.Err
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+
+	content, ok := d.files[code]
+	if !ok {
+		return notFound("Media file", code)
+	}
+
+	path := filepath.Join(folderPath, code)
+	if err := os.MkdirAll(filepath.Dir(path), 0775); err != nil {
+		return &akeneo.ApiError{Message: err.Error(), Err: err}
+	}
+
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return &akeneo.ApiError{Message: err.Error(), Err: err}
+	}
+
+	return nil
+}
+
+var _ akeneo.MediaFileService = (*MediaFiles)(nil)
@@ -5,20 +5,20 @@ type ApiService struct {
 }
 
 type Api struct {
-	Category           *CategoriesApi
-	Family             *FamilyApi
-	FamilyVariant      *FamilyVariantApi
-	Attribute          *AttributeApi
-	AttributeOption    *AttributeOptionApi
-	AttributeGroup     *AttributeGroupApi
-	AssociationTypeApi *AssociationTypeApi
-	Product            *ProductApi
-	ProductModel       *ProductModelApi
-	MediaFile          *MediaFileApi
-	Channel            *ChannelApi
-	Locale             *LocaleApi
-	Currency           *CurrencyApi
-	MeasureFamily      *MeasureFamilyApi
+	Category           CategoryService
+	Family             FamilyService
+	FamilyVariant      FamilyVariantService
+	Attribute          AttributeService
+	AttributeOption    AttributeOptionService
+	AttributeGroup     AttributeGroupService
+	AssociationTypeApi AssociationTypeService
+	Product            ProductService
+	ProductModel       ProductModelService
+	MediaFile          MediaFileService
+	Channel            ChannelService
+	Locale             LocaleService
+	Currency           CurrencyService
+	MeasureFamily      MeasureFamilyService
 }
 
 func NewAkeneoApi(client *Client) *Api {
@@ -45,6 +45,8 @@ func NewAkeneoApi(client *Client) *Api {
 
 type RequestOpts map[string]interface{}
 
+var pageQueryKeys = []string{"page", "limit", "withCount"}
+
 type Response struct {
 	Links       ResponseLinks `json:"_links"`
 	CurrentPage int           `json:"current_page"`
@@ -62,19 +64,14 @@ type ResponseLink struct {
 }
 
 type ResponseBody struct {
-	Line       int32  `json:"line"`
-	Identifier string `json:"identifier"`
-	Code       string `json:"code"`
-	StatusCode int32  `json:"status_code"`
-	Message    string `json:"message"`
+	Line       int32              `json:"line"`
+	Identifier string             `json:"identifier"`
+	Code       string             `json:"code"`
+	StatusCode int32              `json:"status_code"`
+	Message    string             `json:"message"`
+	Errors     []*ValidationError `json:"errors,omitempty"`
 }
 
 type ResponseBodyLinks struct {
 	Documentation ResponseLink `json:"documentation"`
 }
-
-type ApiError struct {
-	Code    int
-	Status  string
-	Message string
-}
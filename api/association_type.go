@@ -3,10 +3,9 @@ package akeneo
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
 type AssociationType struct {
@@ -29,101 +28,162 @@ type AssociationTypeResponse struct {
 }
 
 
-func (service *AssociationTypeApi) GetAll(opts RequestOpts) (*AssociationTypeResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *AssociationTypeApi) GetAll(opts QueryOptions) (*AssociationTypeResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+func (service *AssociationTypeApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*AssociationTypeResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceAssociationType, OperationGetAll)
+
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "association-types", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "association-types", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &AssociationTypeResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type AssociationTypeIterator struct {
+	*pager
+}
+
+func (service *AssociationTypeApi) Iterate(opts QueryOptions) *AssociationTypeIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *AssociationTypeApi) IterateWithContext(ctx context.Context, opts QueryOptions) *AssociationTypeIterator {
+	ctx = withOperation(ctx, ResourceAssociationType, OperationIterate)
+
+	return &AssociationTypeIterator{pager: newPager(ctx, service.client, "association-types", opts, pageQueryKeys, func() collectionPage { return &AssociationTypeResponse{} })}
+}
+
+func (it *AssociationTypeIterator) Item() *AssociationTypeItem {
+	item, _ := it.item.(*AssociationTypeItem)
+
+	return item
+}
+
+// NewAssociationTypeIterator returns an iterator over items already in memory, e.g. for
+// a test double of AssociationTypeService.
+func NewAssociationTypeIterator(items []AssociationTypeItem) *AssociationTypeIterator {
+	page := &AssociationTypeResponse{}
+	page.Data.Items = items
+
+	return &AssociationTypeIterator{pager: newItemPager(page)}
+}
+
+func (page *AssociationTypeResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *AssociationTypeApi) Get(code string) (*AssociationType, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *AssociationTypeApi) GetWithContext(ctx context.Context, code string) (*AssociationType, *ApiError) {
+	ctx = withOperation(ctx, ResourceAssociationType, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("association-types/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var associationType = &AssociationType{}
 	if err = json.NewDecoder(response.Body).Decode(&associationType); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return associationType, nil
 }
 
 func (service *AssociationTypeApi) Create(associationType *AssociationType) *ApiError {
+	return service.CreateWithContext(context.Background(), associationType)
+}
+
+func (service *AssociationTypeApi) CreateWithContext(ctx context.Context, associationType *AssociationType) *ApiError {
+	ctx = withOperation(ctx, ResourceAssociationType, OperationCreate)
+
 	headers := service.client.getHeadersForRequest()
 	body, _ := json.Marshal(associationType)
 
-	response, err := service.client.DoRequest("POST", "association-types", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", "association-types", headers, body, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *AssociationTypeApi) Upsert(associationType *AssociationType) *ApiError {
+	return service.UpsertWithContext(context.Background(), associationType)
+}
+
+func (service *AssociationTypeApi) UpsertWithContext(ctx context.Context, associationType *AssociationType) *ApiError {
+	ctx = withOperation(ctx, ResourceAssociationType, OperationUpsert)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("association-types/%s", associationType.Code)
 	body, _ := json.Marshal(associationType)
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Code: 0, Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *AssociationTypeApi) BatchUpsert(associationTypes []*AssociationType) ([]*ResponseBody, *ApiError) {
+	return service.BatchUpsertWithContext(context.Background(), associationTypes)
+}
+
+func (service *AssociationTypeApi) BatchUpsertWithContext(ctx context.Context, associationTypes []*AssociationType) ([]*ResponseBody, *ApiError) {
+	ctx = withOperation(ctx, ResourceAssociationType, OperationBatchUpsert)
+
 	headers := service.client.getHeadersForBatchRequest()
 	var body []byte
 
@@ -133,16 +193,15 @@ func (service *AssociationTypeApi) BatchUpsert(associationTypes []*AssociationTy
 		body = append(body, '\n')
 	}
 
-	response, err := service.client.DoRequest("PATCH", "association-types", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", "association-types", headers, body, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var apiResponse []*ResponseBody
@@ -152,11 +211,17 @@ func (service *AssociationTypeApi) BatchUpsert(associationTypes []*AssociationTy
 		var responseLine *ResponseBody
 		var reader = bytes.NewReader(scanner.Bytes())
 		if err = json.NewDecoder(reader).Decode(&responseLine); err != nil {
-			return nil, &ApiError{Message: err.Error()}
+			return nil, newApiError(err)
 		}
 
 		apiResponse = append(apiResponse, responseLine)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, newApiError(err)
+	}
+
+	service.client.observers.batchLines(ctx, apiResponse)
+
 	return apiResponse, nil
 }
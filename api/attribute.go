@@ -3,25 +3,57 @@ package akeneo
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 	"time"
 )
 
 const (
-	AkeneoTypeBoolean      = "pim_catalog_boolean"
-	AkeneoTypeMultiSelect  = "pim_catalog_multiselect"
-	AkeneoTypeSimpleSelect = "pim_catalog_simpleselect"
-	AkeneoTypeNumber       = "pim_catalog_number"
-	AkeneoTypeDate         = "pim_catalog_date"
-	AkeneoTypeImage        = "pim_catalog_image"
-	AkeneoTypeText         = "pim_catalog_text"
-	AkeneoTypeTextArea     = "pim_catalog_textarea"
-	AkeneoTypeFile         = "pim_catalog_file"
+	AkeneoTypeBoolean         = "pim_catalog_boolean"
+	AkeneoTypeMultiSelect     = "pim_catalog_multiselect"
+	AkeneoTypeSimpleSelect    = "pim_catalog_simpleselect"
+	AkeneoTypeNumber          = "pim_catalog_number"
+	AkeneoTypeDate            = "pim_catalog_date"
+	AkeneoTypeImage           = "pim_catalog_image"
+	AkeneoTypeText            = "pim_catalog_text"
+	AkeneoTypeTextArea        = "pim_catalog_textarea"
+	AkeneoTypeFile            = "pim_catalog_file"
+	AkeneoTypeIdentifier      = "pim_catalog_identifier"
+	AkeneoTypeMetric          = "pim_catalog_metric"
+	AkeneoTypePriceCollection = "pim_catalog_price_collection"
+
+	AkeneoTypeReferenceDataSimpleSelect = "pim_reference_data_simpleselect"
+	AkeneoTypeReferenceDataMultiSelect  = "pim_reference_data_multiselect"
+	AkeneoTypeAssetCollection           = "pim_catalog_asset_collection"
+	AkeneoTypeTable                     = "pim_catalog_table"
+	AkeneoTypeProductLink               = "pim_catalog_product_link"
+	AkeneoTypeReferenceEntity           = "akeneo_reference_entity"
+	AkeneoTypeReferenceEntityCollection = "akeneo_reference_entity_collection"
 )
 
+var AkeneoTypes = []string{
+	AkeneoTypeIdentifier,
+	AkeneoTypeText,
+	AkeneoTypeTextArea,
+	AkeneoTypeNumber,
+	AkeneoTypeMetric,
+	AkeneoTypePriceCollection,
+	AkeneoTypeBoolean,
+	AkeneoTypeDate,
+	AkeneoTypeSimpleSelect,
+	AkeneoTypeMultiSelect,
+	AkeneoTypeImage,
+	AkeneoTypeFile,
+	AkeneoTypeReferenceDataSimpleSelect,
+	AkeneoTypeReferenceDataMultiSelect,
+	AkeneoTypeAssetCollection,
+	AkeneoTypeTable,
+	AkeneoTypeProductLink,
+	AkeneoTypeReferenceEntity,
+	AkeneoTypeReferenceEntityCollection,
+}
+
 type Attribute struct {
 	Code                string            `json:"code"`
 	Type_               string            `json:"type"`
@@ -47,6 +79,24 @@ type Attribute struct {
 	DateMax             *time.Time        `json:"date_max,omitempty"`
 	AllowedExtensions   []string          `json:"allowed_extensions,omitempty"`
 	MaxFileSize         string            `json:"max_file_size,omitempty"`
+	ReferenceDataName   string            `json:"reference_data_name,omitempty"`
+	TableConfiguration  []TableColumn     `json:"table_configuration,omitempty"`
+	DefaultValue        *bool             `json:"default_value,omitempty"`
+	IsMainIdentifier    bool              `json:"is_main_identifier,omitempty"`
+}
+
+type TableColumn struct {
+	Code                      string                 `json:"code"`
+	DataType                  string                 `json:"data_type"`
+	Labels                    map[string]string      `json:"labels,omitempty"`
+	Validations               map[string]interface{} `json:"validations,omitempty"`
+	IsRequiredForCompleteness bool                   `json:"is_required_for_completeness,omitempty"`
+	Options                   []TableColumnOption    `json:"options,omitempty"`
+}
+
+type TableColumnOption struct {
+	Code   string            `json:"code"`
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 type AttributeApi ApiService
@@ -63,101 +113,162 @@ type AttributesResponse struct {
 	} `json:"_embedded"`
 }
 
-func (service *AttributeApi) GetAll(opts RequestOpts) (*AttributesResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *AttributeApi) GetAll(opts QueryOptions) (*AttributesResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+func (service *AttributeApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*AttributesResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceAttribute, OperationGetAll)
+
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "attributes", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "attributes", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &AttributesResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type AttributeIterator struct {
+	*pager
+}
+
+func (service *AttributeApi) Iterate(opts QueryOptions) *AttributeIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *AttributeApi) IterateWithContext(ctx context.Context, opts QueryOptions) *AttributeIterator {
+	ctx = withOperation(ctx, ResourceAttribute, OperationIterate)
+
+	return &AttributeIterator{pager: newPager(ctx, service.client, "attributes", opts, pageQueryKeys, func() collectionPage { return &AttributesResponse{} })}
+}
+
+func (it *AttributeIterator) Item() *AttributeItem {
+	item, _ := it.item.(*AttributeItem)
+
+	return item
+}
+
+// NewAttributeIterator returns an iterator over items already in memory, e.g. for
+// a test double of AttributeService.
+func NewAttributeIterator(items []AttributeItem) *AttributeIterator {
+	page := &AttributesResponse{}
+	page.Data.Items = items
+
+	return &AttributeIterator{pager: newItemPager(page)}
+}
+
+func (page *AttributesResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *AttributeApi) Get(code string) (*Attribute, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *AttributeApi) GetWithContext(ctx context.Context, code string) (*Attribute, *ApiError) {
+	ctx = withOperation(ctx, ResourceAttribute, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("attributes/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var attribute = &Attribute{}
 	if err = json.NewDecoder(response.Body).Decode(&attribute); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return attribute, nil
 }
 
 func (service *AttributeApi) Create(attribute *Attribute) *ApiError {
+	return service.CreateWithContext(context.Background(), attribute)
+}
+
+func (service *AttributeApi) CreateWithContext(ctx context.Context, attribute *Attribute) *ApiError {
+	ctx = withOperation(ctx, ResourceAttribute, OperationCreate)
+
 	headers := service.client.getHeadersForRequest()
 	body, _ := json.Marshal(attribute)
 
-	response, err := service.client.DoRequest("POST", "attributes", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", "attributes", headers, body, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *AttributeApi) Upsert(attribute *Attribute) *ApiError {
+	return service.UpsertWithContext(context.Background(), attribute)
+}
+
+func (service *AttributeApi) UpsertWithContext(ctx context.Context, attribute *Attribute) *ApiError {
+	ctx = withOperation(ctx, ResourceAttribute, OperationUpsert)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("attributes/%s", attribute.Code)
 	body, _ := json.Marshal(attribute)
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Code: 0, Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *AttributeApi) BatchUpsert(attributes []*Attribute) ([]*ResponseBody, *ApiError) {
+	return service.BatchUpsertWithContext(context.Background(), attributes)
+}
+
+func (service *AttributeApi) BatchUpsertWithContext(ctx context.Context, attributes []*Attribute) ([]*ResponseBody, *ApiError) {
+	ctx = withOperation(ctx, ResourceAttribute, OperationBatchUpsert)
+
 	headers := service.client.getHeadersForBatchRequest()
 	var body []byte
 
@@ -167,16 +278,15 @@ func (service *AttributeApi) BatchUpsert(attributes []*Attribute) ([]*ResponseBo
 		body = append(body, '\n')
 	}
 
-	response, err := service.client.DoRequest("PATCH", "attributes", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", "attributes", headers, body, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var apiResponse []*ResponseBody
@@ -186,11 +296,17 @@ func (service *AttributeApi) BatchUpsert(attributes []*Attribute) ([]*ResponseBo
 		var responseLine *ResponseBody
 		var reader = bytes.NewReader(scanner.Bytes())
 		if err = json.NewDecoder(reader).Decode(&responseLine); err != nil {
-			return nil, &ApiError{Message: err.Error()}
+			return nil, newApiError(err)
 		}
 
 		apiResponse = append(apiResponse, responseLine)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, newApiError(err)
+	}
+
+	service.client.observers.batchLines(ctx, apiResponse)
+
 	return apiResponse, nil
 }
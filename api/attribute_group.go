@@ -3,10 +3,9 @@ package akeneo
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
 type AttributeGroup struct {
@@ -19,7 +18,7 @@ type AttributeGroup struct {
 type AttributeGroupApi ApiService
 
 type AttributeGroupItem struct {
-	Attribute
+	AttributeGroup
 	ResponseLinks `json:"_links"`
 }
 
@@ -30,101 +29,162 @@ type AttributeGroupsResponse struct {
 	} `json:"_embedded"`
 }
 
-func (service *AttributeGroupApi) GetAll(opts RequestOpts) (*AttributeGroupsResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *AttributeGroupApi) GetAll(opts QueryOptions) (*AttributeGroupsResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+func (service *AttributeGroupApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*AttributeGroupsResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceAttributeGroup, OperationGetAll)
+
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "attribute-groups", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "attribute-groups", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &AttributeGroupsResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type AttributeGroupIterator struct {
+	*pager
+}
+
+func (service *AttributeGroupApi) Iterate(opts QueryOptions) *AttributeGroupIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *AttributeGroupApi) IterateWithContext(ctx context.Context, opts QueryOptions) *AttributeGroupIterator {
+	ctx = withOperation(ctx, ResourceAttributeGroup, OperationIterate)
+
+	return &AttributeGroupIterator{pager: newPager(ctx, service.client, "attribute-groups", opts, pageQueryKeys, func() collectionPage { return &AttributeGroupsResponse{} })}
+}
+
+func (it *AttributeGroupIterator) Item() *AttributeGroupItem {
+	item, _ := it.item.(*AttributeGroupItem)
+
+	return item
+}
+
+// NewAttributeGroupIterator returns an iterator over items already in memory, e.g. for
+// a test double of AttributeGroupService.
+func NewAttributeGroupIterator(items []AttributeGroupItem) *AttributeGroupIterator {
+	page := &AttributeGroupsResponse{}
+	page.Data.Items = items
+
+	return &AttributeGroupIterator{pager: newItemPager(page)}
+}
+
+func (page *AttributeGroupsResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *AttributeGroupApi) Get(code string) (*AttributeGroup, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *AttributeGroupApi) GetWithContext(ctx context.Context, code string) (*AttributeGroup, *ApiError) {
+	ctx = withOperation(ctx, ResourceAttributeGroup, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("attribute-groups/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var group = &AttributeGroup{}
 	if err = json.NewDecoder(response.Body).Decode(&group); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return group, nil
 }
 
 func (service *AttributeGroupApi) Create(group *AttributeGroup) *ApiError {
+	return service.CreateWithContext(context.Background(), group)
+}
+
+func (service *AttributeGroupApi) CreateWithContext(ctx context.Context, group *AttributeGroup) *ApiError {
+	ctx = withOperation(ctx, ResourceAttributeGroup, OperationCreate)
+
 	headers := service.client.getHeadersForRequest()
 	body, _ := json.Marshal(group)
 
-	response, err := service.client.DoRequest("POST", "attribute-groups", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", "attribute-groups", headers, body, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *AttributeGroupApi) Upsert(group *AttributeGroup) *ApiError {
+	return service.UpsertWithContext(context.Background(), group)
+}
+
+func (service *AttributeGroupApi) UpsertWithContext(ctx context.Context, group *AttributeGroup) *ApiError {
+	ctx = withOperation(ctx, ResourceAttributeGroup, OperationUpsert)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("attribute-groups/%s", group.Code)
 	body, _ := json.Marshal(group)
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Code: 0, Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *AttributeGroupApi) BatchUpsert(groups []*AttributeGroup) ([]*ResponseBody, *ApiError) {
+	return service.BatchUpsertWithContext(context.Background(), groups)
+}
+
+func (service *AttributeGroupApi) BatchUpsertWithContext(ctx context.Context, groups []*AttributeGroup) ([]*ResponseBody, *ApiError) {
+	ctx = withOperation(ctx, ResourceAttributeGroup, OperationBatchUpsert)
+
 	headers := service.client.getHeadersForBatchRequest()
 	var body []byte
 
@@ -134,16 +194,15 @@ func (service *AttributeGroupApi) BatchUpsert(groups []*AttributeGroup) ([]*Resp
 		body = append(body, '\n')
 	}
 
-	response, err := service.client.DoRequest("PATCH", "attribute-groups", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", "attribute-groups", headers, body, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var apiResponse []*ResponseBody
@@ -153,11 +212,17 @@ func (service *AttributeGroupApi) BatchUpsert(groups []*AttributeGroup) ([]*Resp
 		var responseLine *ResponseBody
 		var reader = bytes.NewReader(scanner.Bytes())
 		if err = json.NewDecoder(reader).Decode(&responseLine); err != nil {
-			return nil, &ApiError{Message: err.Error()}
+			return nil, newApiError(err)
 		}
 
 		apiResponse = append(apiResponse, responseLine)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, newApiError(err)
+	}
+
+	service.client.observers.batchLines(ctx, apiResponse)
+
 	return apiResponse, nil
 }
@@ -0,0 +1,19 @@
+package akeneo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAttributeGroupItemDecodesAttributeGroup(t *testing.T) {
+	body := `{"code":"marketing","sort_order":2,"attributes":["sku","name"],"labels":{"en_US":"Marketing"},"_links":{"self":{"href":"http://localhost/api/rest/v1/attribute-groups/marketing"}}}`
+
+	item := AttributeGroupItem{}
+	if err := json.Unmarshal([]byte(body), &item); err != nil {
+		t.Fatal(err)
+	}
+
+	if item.Code != "marketing" || item.SortOrder != 2 || len(item.Attributes) != 2 || item.Labels["en_US"] != "Marketing" {
+		t.Errorf("unexpected attribute group %+v", item.AttributeGroup)
+	}
+}
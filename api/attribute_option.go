@@ -3,10 +3,9 @@ package akeneo
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
 type AttributeOption struct {
@@ -19,7 +18,7 @@ type AttributeOption struct {
 type AttributeOptionApi ApiService
 
 type AttributeOptionItem struct {
-	Attribute
+	AttributeOption
 	ResponseLinks `json:"_links"`
 }
 
@@ -30,104 +29,165 @@ type AttributeOptionsResponse struct {
 	} `json:"_embedded"`
 }
 
-func (service *AttributeOptionApi) GetAll(attributeCode string, opts RequestOpts) (*AttributeOptionsResponse, *ApiError) {
+func (service *AttributeOptionApi) GetAll(attributeCode string, opts QueryOptions) (*AttributeOptionsResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), attributeCode, opts)
+}
+
+func (service *AttributeOptionApi) GetAllWithContext(ctx context.Context, attributeCode string, opts QueryOptions) (*AttributeOptionsResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceAttributeOption, OperationGetAll)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("attributes/%s/options", attributeCode)
 
-	queryParams := &url.Values{}
-
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &AttributeOptionsResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type AttributeOptionIterator struct {
+	*pager
+}
+
+func (service *AttributeOptionApi) Iterate(attributeCode string, opts QueryOptions) *AttributeOptionIterator {
+	return service.IterateWithContext(context.Background(), attributeCode, opts)
+}
+
+func (service *AttributeOptionApi) IterateWithContext(ctx context.Context, attributeCode string, opts QueryOptions) *AttributeOptionIterator {
+	ctx = withOperation(ctx, ResourceAttributeOption, OperationIterate)
+
+	return &AttributeOptionIterator{pager: newPager(ctx, service.client, fmt.Sprintf("attributes/%s/options", attributeCode), opts, pageQueryKeys, func() collectionPage { return &AttributeOptionsResponse{} })}
+}
+
+func (it *AttributeOptionIterator) Item() *AttributeOptionItem {
+	item, _ := it.item.(*AttributeOptionItem)
+
+	return item
+}
+
+// NewAttributeOptionIterator returns an iterator over items already in memory, e.g. for
+// a test double of AttributeOptionService.
+func NewAttributeOptionIterator(items []AttributeOptionItem) *AttributeOptionIterator {
+	page := &AttributeOptionsResponse{}
+	page.Data.Items = items
+
+	return &AttributeOptionIterator{pager: newItemPager(page)}
+}
+
+func (page *AttributeOptionsResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *AttributeOptionApi) Get(attributeCode, optionCode string) (*AttributeOption, *ApiError) {
+	return service.GetWithContext(context.Background(), attributeCode, optionCode)
+}
+
+func (service *AttributeOptionApi) GetWithContext(ctx context.Context, attributeCode, optionCode string) (*AttributeOption, *ApiError) {
+	ctx = withOperation(ctx, ResourceAttributeOption, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("attributes/%s/options/%s", attributeCode, optionCode)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var attribute = &AttributeOption{}
 	if err = json.NewDecoder(response.Body).Decode(&attribute); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return attribute, nil
 }
 
 func (service *AttributeOptionApi) Create(attributeCode string, attributeOption *AttributeOption) *ApiError {
+	return service.CreateWithContext(context.Background(), attributeCode, attributeOption)
+}
+
+func (service *AttributeOptionApi) CreateWithContext(ctx context.Context, attributeCode string, attributeOption *AttributeOption) *ApiError {
+	ctx = withOperation(ctx, ResourceAttributeOption, OperationCreate)
+
 	uri := fmt.Sprintf("attributes/%s/options", attributeCode)
 	headers := service.client.getHeadersForRequest()
 	body, _ := json.Marshal(attributeOption)
 
-	response, err := service.client.DoRequest("POST", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *AttributeOptionApi) Upsert(attributeCode string,attributeOption *AttributeOption) *ApiError {
+	return service.UpsertWithContext(context.Background(), attributeCode, attributeOption)
+}
+
+func (service *AttributeOptionApi) UpsertWithContext(ctx context.Context, attributeCode string,attributeOption *AttributeOption) *ApiError {
+	ctx = withOperation(ctx, ResourceAttributeOption, OperationUpsert)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("attributes/%s/options/%s", attributeCode, attributeOption.Code)
 	body, _ := json.Marshal(attributeOption)
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Code: 0, Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *AttributeOptionApi) BatchUpsert(attributeCode string, attributeOptions []*AttributeOption) ([]*ResponseBody, *ApiError) {
+	return service.BatchUpsertWithContext(context.Background(), attributeCode, attributeOptions)
+}
+
+func (service *AttributeOptionApi) BatchUpsertWithContext(ctx context.Context, attributeCode string, attributeOptions []*AttributeOption) ([]*ResponseBody, *ApiError) {
+	ctx = withOperation(ctx, ResourceAttributeOption, OperationBatchUpsert)
+
 	headers := service.client.getHeadersForBatchRequest()
 	uri := fmt.Sprintf("attributes/%s/options", attributeCode)
 
@@ -139,16 +199,15 @@ func (service *AttributeOptionApi) BatchUpsert(attributeCode string, attributeOp
 		body = append(body, '\n')
 	}
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var apiResponse []*ResponseBody
@@ -158,11 +217,17 @@ func (service *AttributeOptionApi) BatchUpsert(attributeCode string, attributeOp
 		var responseLine *ResponseBody
 		var reader = bytes.NewReader(scanner.Bytes())
 		if err = json.NewDecoder(reader).Decode(&responseLine); err != nil {
-			return nil, &ApiError{Message: err.Error()}
+			return nil, newApiError(err)
 		}
 
 		apiResponse = append(apiResponse, responseLine)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, newApiError(err)
+	}
+
+	service.client.observers.batchLines(ctx, apiResponse)
+
 	return apiResponse, nil
 }
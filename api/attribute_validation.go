@@ -0,0 +1,306 @@
+package akeneo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	ValidationRuleEmail  = "email"
+	ValidationRuleUrl    = "url"
+	ValidationRuleRegexp = "regexp"
+
+	TableColumnSelect          = "select"
+	TableColumnText            = "text"
+	TableColumnNumber          = "number"
+	TableColumnBoolean         = "boolean"
+	TableColumnReferenceEntity = "reference_entity"
+	TableColumnMeasurement     = "measurement"
+	TableColumnProductLink     = "product_link"
+)
+
+var attributeCodePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+var (
+	numericTypes       = []string{AkeneoTypeNumber, AkeneoTypeMetric, AkeneoTypePriceCollection}
+	textTypes          = []string{AkeneoTypeIdentifier, AkeneoTypeText, AkeneoTypeTextArea}
+	fileTypes          = []string{AkeneoTypeImage, AkeneoTypeFile}
+	referenceDataTypes = []string{AkeneoTypeReferenceDataSimpleSelect, AkeneoTypeReferenceDataMultiSelect, AkeneoTypeReferenceEntity, AkeneoTypeReferenceEntityCollection}
+	uniqueTypes        = []string{AkeneoTypeIdentifier, AkeneoTypeText, AkeneoTypeNumber, AkeneoTypeDate}
+	tableColumnTypes   = []string{TableColumnSelect, TableColumnText, TableColumnNumber, TableColumnBoolean, TableColumnReferenceEntity, TableColumnMeasurement, TableColumnProductLink}
+)
+
+// Validate checks the attribute definition the way Akeneo does on creation,
+// so a batch can be fixed before it is sent with AttributeApi.Create or
+// BatchUpsert. It reports every problem found, not only the first one.
+func (attribute *Attribute) Validate() error {
+	v := &attributeValidator{attribute: attribute}
+
+	v.common()
+
+	switch attribute.Type_ {
+	case AkeneoTypeMetric:
+		if attribute.MetricFamily == "" {
+			v.fail("metric_family is required")
+		}
+		if attribute.DefaultMetricUnit == "" {
+			v.fail("default_metric_unit is required")
+		}
+	case AkeneoTypeText:
+		if attribute.MaxCharacters > 255 {
+			v.fail("max_characters cannot be more than 255")
+		}
+	case AkeneoTypeTextArea:
+		if attribute.MaxCharacters > 65535 {
+			v.fail("max_characters cannot be more than 65535")
+		}
+	case AkeneoTypeIdentifier:
+		if attribute.Localizable || attribute.Scopable {
+			v.fail("an identifier cannot be localizable or scopable")
+		}
+	case AkeneoTypeReferenceDataSimpleSelect, AkeneoTypeReferenceDataMultiSelect, AkeneoTypeReferenceEntity, AkeneoTypeReferenceEntityCollection:
+		if attribute.ReferenceDataName == "" {
+			v.fail("reference_data_name is required")
+		}
+	case AkeneoTypeTable:
+		v.table()
+	}
+
+	v.only(attribute.MetricFamily != "" || attribute.DefaultMetricUnit != "", "metric_family and default_metric_unit", AkeneoTypeMetric)
+	v.only(attribute.NumberMin != "" || attribute.NumberMax != "", "number_min and number_max", numericTypes...)
+	v.only(attribute.DecimalsAllowed, "decimals_allowed", numericTypes...)
+	v.only(attribute.NegativeAllowed, "negative_allowed", AkeneoTypeNumber, AkeneoTypeMetric)
+	v.only(attribute.MaxCharacters != 0, "max_characters", textTypes...)
+	v.only(attribute.ValidationRule != "" || attribute.ValidationRegexp != "", "validation_rule and validation_regexp", AkeneoTypeIdentifier, AkeneoTypeText)
+	v.only(attribute.WysiwygEnabled, "wysiwyg_enabled", AkeneoTypeTextArea)
+	v.only(attribute.DateMin != nil || attribute.DateMax != nil, "date_min and date_max", AkeneoTypeDate)
+	v.only(len(attribute.AllowedExtensions) > 0 || attribute.MaxFileSize != "", "allowed_extensions and max_file_size", fileTypes...)
+	v.only(attribute.ReferenceDataName != "", "reference_data_name", referenceDataTypes...)
+	v.only(len(attribute.TableConfiguration) > 0, "table_configuration", AkeneoTypeTable)
+	v.only(attribute.DefaultValue != nil, "default_value", AkeneoTypeBoolean)
+	v.only(attribute.IsMainIdentifier, "is_main_identifier", AkeneoTypeIdentifier)
+	v.only(attribute.Unique, "unique", uniqueTypes...)
+
+	v.numbers()
+	v.dates()
+	v.validationRule()
+
+	if attribute.MaxFileSize != "" {
+		if size, err := strconv.ParseFloat(attribute.MaxFileSize, 64); err != nil || size <= 0 {
+			v.fail(fmt.Sprintf("max_file_size %q is not a positive number", attribute.MaxFileSize))
+		}
+	}
+
+	return v.err()
+}
+
+type attributeValidator struct {
+	attribute *Attribute
+	errs      []string
+}
+
+func (v *attributeValidator) fail(message string) {
+	v.errs = append(v.errs, message)
+}
+
+func (v *attributeValidator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("akeneo: invalid attribute %q: %s", v.attribute.Code, strings.Join(v.errs, "; "))
+}
+
+func (v *attributeValidator) common() {
+	attribute := v.attribute
+
+	if attribute.Code == "" {
+		v.fail("code is required")
+	} else if !attributeCodePattern.MatchString(attribute.Code) {
+		v.fail("code may only contain letters, numbers and underscores")
+	}
+
+	if attribute.Type_ == "" {
+		v.fail("type is required")
+	} else if !containsString(AkeneoTypes, attribute.Type_) {
+		v.fail(fmt.Sprintf("unknown type %q", attribute.Type_))
+	}
+
+	if attribute.Group == "" {
+		v.fail("group is required")
+	}
+
+	if attribute.Unique && (attribute.Localizable || attribute.Scopable) {
+		v.fail("a unique attribute cannot be localizable or scopable")
+	}
+
+	if len(attribute.AvailableLocales) > 0 && !attribute.Localizable {
+		v.fail("available_locales needs a localizable attribute")
+	}
+}
+
+// only reports a property set on a type that does not support it.
+func (v *attributeValidator) only(set bool, property string, types ...string) {
+	if set && v.attribute.Type_ != "" && !containsString(types, v.attribute.Type_) {
+		v.fail(fmt.Sprintf("%s cannot be set on a %s attribute", property, v.attribute.Type_))
+	}
+}
+
+func (v *attributeValidator) numbers() {
+	attribute := v.attribute
+
+	min, hasMin := v.number("number_min", attribute.NumberMin)
+	max, hasMax := v.number("number_max", attribute.NumberMax)
+
+	if hasMin && hasMax && min > max {
+		v.fail("number_min cannot be greater than number_max")
+	}
+
+	if attribute.Type_ == AkeneoTypePriceCollection {
+		return
+	}
+
+	if !attribute.NegativeAllowed && ((hasMin && min < 0) || (hasMax && max < 0)) {
+		v.fail("number_min and number_max cannot be negative when negative_allowed is false")
+	}
+}
+
+// number parses a bound, which must be an integer unless decimals are allowed.
+func (v *attributeValidator) number(property string, value string) (float64, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		v.fail(fmt.Sprintf("%s %q is not a number", property, value))
+		return 0, false
+	}
+
+	if !v.attribute.DecimalsAllowed && number != math.Trunc(number) {
+		v.fail(fmt.Sprintf("%s %q must be an integer when decimals_allowed is false", property, value))
+	}
+
+	return number, true
+}
+
+func (v *attributeValidator) dates() {
+	if v.attribute.DateMin != nil && v.attribute.DateMax != nil && v.attribute.DateMin.After(*v.attribute.DateMax) {
+		v.fail("date_min cannot be after date_max")
+	}
+}
+
+func (v *attributeValidator) validationRule() {
+	attribute := v.attribute
+
+	switch attribute.ValidationRule {
+	case "":
+		if attribute.ValidationRegexp != "" {
+			v.fail("validation_regexp needs the \"regexp\" validation_rule")
+		}
+	case ValidationRuleEmail, ValidationRuleUrl:
+		if attribute.ValidationRegexp != "" {
+			v.fail("validation_regexp needs the \"regexp\" validation_rule")
+		}
+	case ValidationRuleRegexp:
+		if attribute.ValidationRegexp == "" {
+			v.fail("validation_regexp is required by the \"regexp\" validation_rule")
+		} else if _, err := compileValidationRegexp(attribute.ValidationRegexp); err != nil {
+			v.fail(fmt.Sprintf("validation_regexp %q %s", attribute.ValidationRegexp, err))
+		}
+	default:
+		v.fail(fmt.Sprintf("unknown validation_rule %q", attribute.ValidationRule))
+	}
+}
+
+func (v *attributeValidator) table() {
+	columns := v.attribute.TableConfiguration
+
+	if len(columns) < 2 {
+		v.fail("table_configuration needs at least two columns")
+		return
+	}
+
+	if first := columns[0].DataType; first != TableColumnSelect && first != TableColumnReferenceEntity {
+		v.fail("the first column of table_configuration must be a select or a reference entity")
+	}
+
+	codes := map[string]bool{}
+	for i, column := range columns {
+		if column.Code == "" {
+			v.fail(fmt.Sprintf("table_configuration column %d has no code", i))
+		} else if codes[column.Code] {
+			v.fail(fmt.Sprintf("table_configuration column %q is duplicated", column.Code))
+		}
+		codes[column.Code] = true
+
+		if !containsString(tableColumnTypes, column.DataType) {
+			v.fail(fmt.Sprintf("table_configuration column %q has unknown data_type %q", column.Code, column.DataType))
+		}
+		if len(column.Options) > 0 && column.DataType != TableColumnSelect {
+			v.fail(fmt.Sprintf("table_configuration column %q can only have options as a select", column.Code))
+		}
+	}
+}
+
+var errRegexpNotDelimited = errors.New("is not delimited like /pattern/")
+
+// regexpDelimiters maps the bracket delimiters PHP accepts to their closing
+// counterpart. Any other delimiter closes the pattern with itself.
+var regexpDelimiters = map[byte]byte{'(': ')', '[': ']', '{': '}', '<': '>'}
+
+// compileValidationRegexp compiles a validation_regexp, which Akeneo checks
+// with PHP as /pattern/flags. The i, m and s flags have the same meaning in
+// Go and u is implied, Go patterns being UTF-8. Any other flag changes what
+// the pattern matches, so it is reported rather than dropped.
+func compileValidationRegexp(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) < 2 {
+		return nil, errRegexpNotDelimited
+	}
+
+	open := pattern[0]
+	if open == '\\' || open >= utf8.RuneSelf || unicode.IsSpace(rune(open)) || unicode.IsLetter(rune(open)) || unicode.IsDigit(rune(open)) {
+		return nil, errRegexpNotDelimited
+	}
+
+	closing, ok := regexpDelimiters[open]
+	if !ok {
+		closing = open
+	}
+
+	end := strings.LastIndexByte(pattern[1:], closing) + 1
+	if end == 0 {
+		return nil, errRegexpNotDelimited
+	}
+
+	var goFlags string
+	for _, flag := range pattern[end+1:] {
+		switch flag {
+		case 'i', 'm', 's':
+			if !strings.ContainsRune(goFlags, flag) {
+				goFlags += string(flag)
+			}
+		case 'u':
+		default:
+			return nil, fmt.Errorf("has the unsupported flag %q", flag)
+		}
+	}
+
+	expression := pattern[1:end]
+	if goFlags != "" {
+		expression = "(?" + goFlags + ")" + expression
+	}
+
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("does not compile: %s", strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+	}
+
+	return compiled, nil
+}
@@ -0,0 +1,108 @@
+package akeneo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAttributeValidateAcceptsValidDefinitions(t *testing.T) {
+	attributes := []*Attribute{
+		{Code: "sku", Type_: AkeneoTypeIdentifier, Group: "other", Unique: true, IsMainIdentifier: true},
+		{Code: "weight", Type_: AkeneoTypeMetric, Group: "technical", MetricFamily: "Weight", DefaultMetricUnit: "KILOGRAM", DecimalsAllowed: true, NumberMin: "0.5"},
+		{Code: "ean", Type_: AkeneoTypeText, Group: "other", ValidationRule: ValidationRuleRegexp, ValidationRegexp: "/^[0-9]{13}$/"},
+		{Code: "brand", Type_: AkeneoTypeReferenceEntity, Group: "marketing", ReferenceDataName: "brands"},
+		{Code: "designers", Type_: AkeneoTypeReferenceEntityCollection, Group: "marketing", ReferenceDataName: "designers"},
+	}
+
+	for _, attribute := range attributes {
+		if err := attribute.Validate(); err != nil {
+			t.Errorf("%s: %v", attribute.Code, err)
+		}
+	}
+}
+
+func TestAttributeValidateReportsEveryProblem(t *testing.T) {
+	attribute := &Attribute{Code: "bad code", Type_: AkeneoTypeNumber, NumberMin: "10", NumberMax: "1.5", MaxCharacters: 10}
+
+	err := attribute.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, problem := range []string{"code may only contain", "group is required", "max_characters cannot be set", "must be an integer", "number_min cannot be greater"} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("%q not reported in %v", problem, err)
+		}
+	}
+}
+
+func TestAttributeValidateTypes(t *testing.T) {
+	invalid := []*Attribute{
+		{Code: "a", Type_: "pim_catalog_unknown", Group: "other"},
+		{Code: "a", Type_: AkeneoTypeMetric, Group: "other"},
+		{Code: "a", Type_: AkeneoTypeReferenceEntity, Group: "other"},
+		{Code: "a", Type_: AkeneoTypeText, Group: "other", ReferenceDataName: "brands"},
+		{Code: "a", Type_: AkeneoTypeIdentifier, Group: "other", Localizable: true},
+		{Code: "a", Type_: AkeneoTypeTable, Group: "other", TableConfiguration: []TableColumn{{Code: "size", DataType: TableColumnText}, {Code: "size", DataType: "color"}}},
+	}
+
+	for _, attribute := range invalid {
+		if err := attribute.Validate(); err == nil {
+			t.Errorf("%s: expected an error", attribute.Type_)
+		}
+	}
+}
+
+func TestAttributeValidateRegexp(t *testing.T) {
+	valid := []string{"/^[a-z]+$/", "/^[a-z]+$/i", "#^https?://#", "{^a/b$}ms", "/^é+$/u"}
+	for _, pattern := range valid {
+		attribute := &Attribute{Code: "a", Type_: AkeneoTypeText, Group: "other", ValidationRule: ValidationRuleRegexp, ValidationRegexp: pattern}
+		if err := attribute.Validate(); err != nil {
+			t.Errorf("%s: %v", pattern, err)
+		}
+	}
+
+	invalid := []string{"^[a-z]+$", "/^[a-z]+$", "/^[a-z]+$/x", "/^[a-z+$/"}
+	for _, pattern := range invalid {
+		attribute := &Attribute{Code: "a", Type_: AkeneoTypeText, Group: "other", ValidationRule: ValidationRuleRegexp, ValidationRegexp: pattern}
+		if err := attribute.Validate(); err == nil {
+			t.Errorf("%s: expected an error", pattern)
+		}
+	}
+
+	attribute := &Attribute{Code: "a", Type_: AkeneoTypeText, Group: "other", ValidationRule: ValidationRuleEmail, ValidationRegexp: "/a/"}
+	if err := attribute.Validate(); err == nil {
+		t.Error("expected an error for a validation_regexp without the regexp rule")
+	}
+}
+
+func TestCompileValidationRegexpFlags(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		match   bool
+	}{
+		{"/^abc$/", "ABC", false},
+		{"/^abc$/i", "ABC", true},
+		{"/^b$/", "a\nb", false},
+		{"/^b$/m", "a\nb", true},
+		{"/a.b/", "a\nb", false},
+		{"/a.b/s", "a\nb", true},
+		{"/^a\\/b$/", "a/b", true},
+	}
+
+	for _, test := range tests {
+		pattern, err := compileValidationRegexp(test.pattern)
+		if err != nil {
+			t.Errorf("%s: %v", test.pattern, err)
+			continue
+		}
+		if pattern.MatchString(test.text) != test.match {
+			t.Errorf("%s on %q: expected match %v", test.pattern, test.text, test.match)
+		}
+	}
+
+	if _, err := compileValidationRegexp("/a/U"); err == nil || !strings.Contains(err.Error(), "unsupported flag") {
+		t.Errorf("expected the U flag to be reported, got %v", err)
+	}
+}
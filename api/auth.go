@@ -0,0 +1,220 @@
+package akeneo
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"golang.org/x/oauth2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator provides the bearer token attached to every API request.
+// ClientAuth (password grant), StaticTokenAuth and AppAuth implement it.
+type Authenticator interface {
+	GetToken() (*Token, error)
+	GetTokenWithContext(ctx context.Context) (*Token, error)
+}
+
+// StaticTokenAuth uses a pre-issued access token, such as the one given to an
+// Akeneo App or a connection created in the PIM UI. The token is never renewed.
+type StaticTokenAuth struct {
+	token *Token
+}
+
+func NewStaticTokenAuth(accessToken string) *StaticTokenAuth {
+	return &StaticTokenAuth{
+		token: &Token{Token: oauth2.Token{AccessToken: accessToken, TokenType: "Bearer"}},
+	}
+}
+
+func (sa *StaticTokenAuth) GetToken() (*Token, error) {
+	return sa.GetTokenWithContext(context.Background())
+}
+
+func (sa *StaticTokenAuth) GetTokenWithContext(ctx context.Context) (*Token, error) {
+	if sa.token.AccessToken == "" {
+		return nil, errors.New("akeneo: empty access token")
+	}
+
+	return sa.token, nil
+}
+
+type AppAuthConfig struct {
+	BaseUrl      string
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+	UserAgent    string
+	TokenUrl     string
+	AuthorizeUrl string
+}
+
+// AppAuth implements the authorization-code flow used by Akeneo Apps. The
+// user is sent to AuthorizeURL, the PIM redirects back with a code, and
+// Exchange trades that code for an access token. App tokens do not expire.
+//
+// Once given to NewClient as the Authenticator, the code exchange goes through
+// the transport, middlewares and observers of that client.
+type AppAuth struct {
+	sync.RWMutex
+	config    *AppAuthConfig
+	transport RoundTripFunc
+	observers observers
+	token     *Token
+}
+
+type AppToken struct {
+	Token
+	Scope   string `json:"scope"`
+	IdToken string `json:"id_token,omitempty"`
+}
+
+func NewAppAuth(config *AppAuthConfig) *AppAuth {
+	client := &http.Client{Timeout: time.Second * 30}
+
+	return &AppAuth{config: config, transport: client.Do}
+}
+
+func (aa *AppAuth) useTransport(transport RoundTripFunc, observers observers) {
+	aa.Lock()
+	defer aa.Unlock()
+
+	aa.transport = transport
+	aa.observers = observers
+}
+
+func (aa *AppAuth) tokenUrl() string {
+	if aa.config.TokenUrl != "" {
+		return aa.config.TokenUrl
+	}
+
+	return strings.TrimRight(aa.config.BaseUrl, "/") + "/connect/apps/v1/oauth2/token"
+}
+
+// AuthorizeURL returns the PIM page the user must be redirected to in order
+// to grant the App its scopes. state is echoed back on the callback.
+func (aa *AppAuth) AuthorizeURL(state string) string {
+	authorizeUrl := aa.config.AuthorizeUrl
+	if authorizeUrl == "" {
+		authorizeUrl = strings.TrimRight(aa.config.BaseUrl, "/") + "/connect/apps/v1/authorize"
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", aa.config.ClientId)
+	query.Set("scope", strings.Join(aa.config.Scopes, " "))
+	query.Set("state", state)
+
+	return authorizeUrl + "?" + query.Encode()
+}
+
+func (aa *AppAuth) Exchange(code string) (*AppToken, error) {
+	return aa.ExchangeWithContext(context.Background(), code)
+}
+
+// ExchangeWithContext trades the authorization code for an access token and
+// keeps it for subsequent GetToken calls.
+func (aa *AppAuth) ExchangeWithContext(ctx context.Context, code string) (token *AppToken, err error) {
+	aa.RLock()
+	transport, observers := aa.transport, aa.observers
+	aa.RUnlock()
+
+	started := time.Now()
+	defer func() {
+		observers.tokenRefresh(&TokenEvent{GrantType: GrantTypeAuthorizationCode, Duration: time.Since(started), Err: err})
+	}()
+
+	codeIdentifier, codeChallenge, err := GenerateCodeChallenge(aa.config.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("client_id", aa.config.ClientId)
+	form.Set("code", code)
+	form.Set("grant_type", GrantTypeAuthorizationCode)
+	form.Set("code_identifier", codeIdentifier)
+	form.Set("code_challenge", codeChallenge)
+
+	ctx = withOperation(ctx, ResourceAuth, OperationToken)
+	req, err := http.NewRequestWithContext(ctx, "POST", aa.tokenUrl(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if aa.config.UserAgent != "" {
+		req.Header.Set("User-Agent", aa.config.UserAgent)
+	}
+
+	resp, err := transport(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, newResponseError(resp)
+	}
+
+	token = &AppToken{}
+	if err := json.NewDecoder(resp.Body).Decode(token); err != nil {
+		return nil, err
+	}
+
+	aa.SetToken(&token.Token)
+
+	return token, nil
+}
+
+// SetToken restores a token obtained by a previous Exchange.
+func (aa *AppAuth) SetToken(token *Token) {
+	aa.Lock()
+	defer aa.Unlock()
+
+	aa.token = token
+}
+
+func (aa *AppAuth) GetToken() (*Token, error) {
+	return aa.GetTokenWithContext(context.Background())
+}
+
+func (aa *AppAuth) GetTokenWithContext(ctx context.Context) (*Token, error) {
+	aa.RLock()
+	defer aa.RUnlock()
+
+	if aa.token == nil || aa.token.AccessToken == "" {
+		return nil, errors.New("akeneo: app is not authorized, exchange an authorization code first")
+	}
+
+	return aa.token, nil
+}
+
+// GenerateCodeChallenge returns a random code identifier and the matching
+// challenge, the hex encoded SHA-256 of the identifier followed by the
+// client secret, as expected by the Akeneo App token endpoint.
+func GenerateCodeChallenge(clientSecret string) (codeIdentifier string, codeChallenge string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+
+	codeIdentifier = hex.EncodeToString(raw)
+
+	return codeIdentifier, CodeChallenge(codeIdentifier, clientSecret), nil
+}
+
+func CodeChallenge(codeIdentifier string, clientSecret string) string {
+	sum := sha256.Sum256([]byte(codeIdentifier + clientSecret))
+
+	return hex.EncodeToString(sum[:])
+}
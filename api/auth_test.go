@@ -0,0 +1,165 @@
+package akeneo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newAppServer answers the App token endpoint, checking the code challenge
+// sent along with the code, and a locale endpoint requiring the App token.
+func newAppServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/connect/apps/v1/oauth2/token":
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+			}
+
+			sum := sha256.Sum256([]byte(r.PostForm.Get("code_identifier") + "secret"))
+			if r.PostForm.Get("code_challenge") != hex.EncodeToString(sum[:]) {
+				t.Errorf("the code challenge does not match the code identifier: %v", r.PostForm)
+			}
+			if r.PostForm.Get("grant_type") != GrantTypeAuthorizationCode || r.PostForm.Get("client_id") != "app" {
+				t.Errorf("unexpected form %v", r.PostForm)
+			}
+
+			if r.PostForm.Get("code") != "valid-code" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_grant","error_description":"Code is invalid"}`)
+				return
+			}
+
+			fmt.Fprint(w, `{"access_token":"app-token","token_type":"bearer","scope":"read_products","id_token":"id"}`)
+		case "/api/rest/v1/locales/en_US":
+			if r.Header.Get("Authorization") != "Bearer app-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"code":"en_US","enabled":true}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestAppAuthExchange(t *testing.T) {
+	server := newAppServer(t)
+	auth := NewAppAuth(&AppAuthConfig{BaseUrl: server.URL, ClientId: "app", ClientSecret: "secret"})
+
+	if _, err := auth.GetToken(); err == nil {
+		t.Error("expected an error before the code exchange")
+	}
+
+	if _, err := auth.Exchange("expired-code"); !IsBadRequest(err) {
+		t.Errorf("expected a bad request, got %v", err)
+	}
+	if _, err := auth.GetToken(); err == nil {
+		t.Error("a failed exchange stored a token")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := auth.ExchangeWithContext(ctx, "valid-code"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled exchange, got %v", err)
+	}
+
+	token, err := auth.ExchangeWithContext(context.Background(), "valid-code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "app-token" || token.Scope != "read_products" || token.IdToken != "id" {
+		t.Errorf("unexpected token %+v", token)
+	}
+
+	stored, err := auth.GetToken()
+	if err != nil || stored.AccessToken != "app-token" {
+		t.Errorf("unexpected stored token %v, %v", stored, err)
+	}
+
+	api := NewAkeneoApi(NewClient(&ClientConfig{BaseUrl: server.URL, Authenticator: auth}))
+	if locale, err := api.Locale.Get("en_US"); err != nil || locale.Code != "en_US" {
+		t.Errorf("unexpected locale %v, %v", locale, err)
+	}
+}
+
+type tokenObserver struct {
+	NopObserver
+	grants []string
+}
+
+func (o *tokenObserver) OnTokenRefresh(event *TokenEvent) {
+	o.grants = append(o.grants, event.GrantType)
+}
+
+func TestAppAuthExchangeUsesClientTransport(t *testing.T) {
+	server := newAppServer(t)
+	auth := NewAppAuth(&AppAuthConfig{BaseUrl: server.URL, ClientId: "app", ClientSecret: "secret"})
+
+	var mu sync.Mutex
+	var operations []string
+	middleware := func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			op, _ := OperationFromContext(request.Context())
+
+			mu.Lock()
+			operations = append(operations, op.Resource+"."+op.Name)
+			mu.Unlock()
+
+			return next(request)
+		}
+	}
+
+	observer := &tokenObserver{}
+	NewClient(&ClientConfig{
+		BaseUrl:       server.URL,
+		Authenticator: auth,
+		Middlewares:   []Middleware{middleware},
+		Observers:     []Observer{observer},
+	})
+
+	if _, err := auth.Exchange("valid-code"); err != nil {
+		t.Fatal(err)
+	}
+
+	if joined := strings.Join(operations, ","); joined != "auth.token" {
+		t.Errorf("expected the exchange to go through the middleware, got %s", joined)
+	}
+	if joined := strings.Join(observer.grants, ","); joined != GrantTypeAuthorizationCode {
+		t.Errorf("unexpected observed grants %s", joined)
+	}
+}
+
+func TestGenerateCodeChallenge(t *testing.T) {
+	identifier, challenge, err := GenerateCodeChallenge("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := hex.DecodeString(identifier); err != nil || len(identifier) != 64 {
+		t.Errorf("unexpected code identifier %q", identifier)
+	}
+
+	sum := sha256.Sum256([]byte(identifier + "secret"))
+	if challenge != hex.EncodeToString(sum[:]) || challenge != CodeChallenge(identifier, "secret") {
+		t.Errorf("the challenge %q does not match the identifier %q", challenge, identifier)
+	}
+	if CodeChallenge(identifier, "other") == challenge {
+		t.Error("the challenge does not depend on the client secret")
+	}
+
+	other, _, _ := GenerateCodeChallenge("secret")
+	if other == identifier {
+		t.Error("expected a new code identifier on every call")
+	}
+}
@@ -0,0 +1,396 @@
+package akeneo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+type CassetteMode int
+
+const (
+	CassetteRecord CassetteMode = iota
+	CassetteReplay
+)
+
+const redacted = "REDACTED"
+
+var ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+var (
+	redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+	redactedFields  = map[string]bool{
+		"password":       true,
+		"access_token":   true,
+		"refresh_token":  true,
+		"client_secret":  true,
+		"code_challenge": true,
+		"id_token":       true,
+	}
+
+	// The authorization code of an App is only redacted in form bodies, as
+	// "code" is the key of most resources in JSON bodies.
+	redactedFormFields = map[string]bool{
+		"code": true,
+	}
+)
+
+type CassetteRequest struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Query   string      `json:"query,omitempty"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+	Base64  bool        `json:"base64,omitempty"`
+}
+
+type CassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	Base64     bool        `json:"base64,omitempty"`
+}
+
+type Interaction struct {
+	Request  *CassetteRequest  `json:"request"`
+	Response *CassetteResponse `json:"response"`
+}
+
+// Cassette is an http.RoundTripper that records the requests sent to Akeneo
+// and their responses, or replays a recorded session without any network.
+// Credentials and tokens are redacted before anything is stored. Pass it as
+// ClientConfig.Transport.
+//
+// In replay mode a request is answered by the first interaction not used
+// yet with the same method, path, query and body. JSON and NDJSON bodies are
+// compared after normalisation, multipart bodies part by part.
+type Cassette struct {
+	Path      string
+	Mode      CassetteMode
+	Transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// NewCassette opens the cassette file in replay mode, or prepares an empty
+// cassette in record mode. Recorded interactions are written by Save.
+func NewCassette(path string, mode CassetteMode) (*Cassette, error) {
+	c := &Cassette{Path: path, Mode: mode}
+
+	if mode == CassetteReplay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(data, &c.interactions); err != nil {
+			return nil, fmt.Errorf("cassette %s: %v", path, err)
+		}
+
+		c.used = make([]bool, len(c.interactions))
+	}
+
+	return c, nil
+}
+
+func (c *Cassette) Interactions() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]*Interaction(nil), c.interactions...)
+}
+
+func (c *Cassette) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := newCassetteRequest(request, body)
+
+	if c.Mode == CassetteReplay {
+		return c.replay(request, recorded)
+	}
+
+	transport := c.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+
+	headers := response.Header.Clone()
+	redactHeaders(headers)
+
+	recordedResponse := &CassetteResponse{StatusCode: response.StatusCode, Headers: headers}
+	recordedResponse.Body, recordedResponse.Base64 = encodeBody(redactBody(response.Header.Get("Content-Type"), responseBody))
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, &Interaction{Request: recorded, Response: recordedResponse})
+	c.used = append(c.used, true)
+	c.mu.Unlock()
+
+	return response, nil
+}
+
+func (c *Cassette) replay(request *http.Request, recorded *CassetteRequest) (*http.Response, error) {
+	key := recorded.matchKey()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, interaction := range c.interactions {
+		if c.used[i] || interaction.Request.matchKey() != key {
+			continue
+		}
+
+		c.used[i] = true
+
+		body := decodeBody(interaction.Response.Body, interaction.Response.Base64)
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Headers.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       request,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, request.URL.RequestURI())
+}
+
+// Save writes the recorded interactions to the cassette file.
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0775); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(c.Path, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+
+	// WriteFile keeps the mode of an existing file.
+	return os.Chmod(c.Path, 0600)
+}
+
+func readRequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+func newCassetteRequest(request *http.Request, body []byte) *CassetteRequest {
+	headers := request.Header.Clone()
+	redactHeaders(headers)
+
+	recorded := &CassetteRequest{
+		Method:  request.Method,
+		Path:    request.URL.Path,
+		Query:   request.URL.Query().Encode(),
+		Headers: headers,
+	}
+	recorded.Body, recorded.Base64 = encodeBody(redactBody(request.Header.Get("Content-Type"), body))
+
+	return recorded
+}
+
+func (cr *CassetteRequest) matchKey() string {
+	query, _ := url.ParseQuery(cr.Query)
+
+	return cr.Method + " " + cr.Path + "?" + query.Encode() + "\n" + normalizeBody(cr.Headers.Get("Content-Type"), decodeBody(cr.Body, cr.Base64))
+}
+
+// encodeBody keeps text bodies readable in the cassette file and stores
+// binary ones, such as media files, in base64.
+func encodeBody(body []byte) (string, bool) {
+	if utf8.Valid(body) {
+		return string(body), false
+	}
+
+	return base64.StdEncoding.EncodeToString(body), true
+}
+
+func decodeBody(body string, encoded bool) []byte {
+	if !encoded {
+		return []byte(body)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(body)
+
+	return data
+}
+
+func redactHeaders(headers http.Header) {
+	for _, name := range redactedHeaders {
+		if headers.Get(name) != "" {
+			headers.Set(name, redacted)
+		}
+	}
+}
+
+// redactBody replaces the values of sensitive fields in JSON, NDJSON and
+// form-encoded bodies.
+func redactBody(contentType string, body []byte) []byte {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if mediaType == "application/x-www-form-urlencoded" {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body
+		}
+
+		for key := range form {
+			if redactedFields[key] || redactedFormFields[key] {
+				form.Set(key, redacted)
+			}
+		}
+
+		return []byte(form.Encode())
+	}
+
+	lines := bytes.Split(body, []byte("\n"))
+	for i, line := range lines {
+		var value interface{}
+		if json.Unmarshal(line, &value) != nil {
+			continue
+		}
+
+		if object, ok := value.(map[string]interface{}); ok && redactObject(object) {
+			if data, err := json.Marshal(object); err == nil {
+				lines[i] = data
+			}
+		}
+	}
+
+	return bytes.Join(lines, []byte("\n"))
+}
+
+func redactObject(object map[string]interface{}) bool {
+	changed := false
+
+	for key, value := range object {
+		if redactedFields[key] {
+			if _, ok := value.(string); ok {
+				object[key] = redacted
+				changed = true
+			}
+			continue
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok && redactObject(nested) {
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func normalizeBody(contentType string, body []byte) string {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		return normalizeMultipart(params["boundary"], body)
+	}
+
+	if mediaType == "application/x-www-form-urlencoded" {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			return form.Encode()
+		}
+	}
+
+	var lines []string
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var value interface{}
+		if json.Unmarshal(line, &value) == nil {
+			if data, err := json.Marshal(value); err == nil {
+				line = data
+			}
+		}
+
+		lines = append(lines, string(line))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// normalizeMultipart drops the random boundary so that two uploads of the
+// same fields and files compare equal.
+func normalizeMultipart(boundary string, body []byte) string {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return string(body)
+		}
+
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			return string(body)
+		}
+
+		parts = append(parts, part.FormName()+"|"+part.FileName()+"|"+normalizeBody(part.Header.Get("Content-Type"), content))
+	}
+
+	sort.Strings(parts)
+
+	return strings.Join(parts, "\n")
+}
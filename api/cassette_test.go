@@ -0,0 +1,168 @@
+package akeneo
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newCassetteServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/oauth/v1/token":
+			fmt.Fprint(w, `{"access_token":"secret-access","refresh_token":"secret-refresh","token_type":"bearer","expires_in":3600}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/rest/v1/products/sku-1":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"identifier":"sku-1","enabled":true,"family":"shoes"}`)
+		case r.Method == http.MethodPatch && r.URL.Path == "/api/rest/v1/products/sku-1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code":404,"message":"Resource not found."}`)
+		}
+	}))
+}
+
+func newCassetteApi(baseUrl string, cassette *Cassette) *Api {
+	return NewAkeneoApi(NewClient(&ClientConfig{
+		BaseUrl:   baseUrl,
+		Username:  "admin",
+		Password:  "secret-password",
+		ClientId:  "client",
+		SecretKey: "secret-key",
+		Transport: cassette,
+	}))
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	server := newCassetteServer()
+	path := filepath.Join(t.TempDir(), "cassettes", "products.json")
+
+	recorder, err := NewCassette(path, CassetteRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := newCassetteApi(server.URL, recorder)
+	product, apiErr := api.Product.Get("sku-1")
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if apiErr := api.Product.Upsert(&Product{Identifier: "sku-1", Enabled: true, FamilyCode: "shoes"}); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	server.Close()
+
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if count := len(recorder.Interactions()); count != 3 {
+		t.Fatalf("expected 3 interactions, got %d", count)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("expected a cassette only readable by its owner, got %v", info.Mode())
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-access", "secret-refresh", "secret-password", "Basic "} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("the cassette holds %q", secret)
+		}
+	}
+
+	player, err := NewCassette(path, CassetteReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api = newCassetteApi(server.URL, player)
+	replayed, apiErr := api.Product.Get("sku-1")
+	if apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if replayed.Identifier != product.Identifier || replayed.FamilyCode != "shoes" {
+		t.Errorf("unexpected product %+v", replayed)
+	}
+
+	// JSON bodies match whatever the order of their keys.
+	if apiErr := api.Product.Upsert(&Product{FamilyCode: "shoes", Identifier: "sku-1", Enabled: true}); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+
+	// Each interaction is replayed once.
+	if _, apiErr := api.Product.Get("sku-1"); apiErr == nil || !errors.Is(apiErr, ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction, got %v", apiErr)
+	}
+}
+
+func TestCassetteReplayDoesNotMatchOtherBodies(t *testing.T) {
+	server := newCassetteServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder, _ := NewCassette(path, CassetteRecord)
+	if apiErr := newCassetteApi(server.URL, recorder).Product.Upsert(&Product{Identifier: "sku-1", Enabled: true}); apiErr != nil {
+		t.Fatal(apiErr)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	player, err := NewCassette(path, CassetteReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiErr := newCassetteApi(server.URL, player).Product.Upsert(&Product{Identifier: "sku-1", Enabled: false})
+	if apiErr == nil || !errors.Is(apiErr, ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction, got %v", apiErr)
+	}
+}
+
+func TestCassetteRedactsBodies(t *testing.T) {
+	redactedBody := string(redactBody("application/json", []byte(`{"username":"admin","password":"p","grant_type":"password","nested":{"refresh_token":"r"}}`)))
+
+	if strings.Contains(redactedBody, `"p"`) || strings.Contains(redactedBody, `"r"`) || !strings.Contains(redactedBody, "admin") {
+		t.Errorf("unexpected redacted body %s", redactedBody)
+	}
+
+	redactedBody = string(redactBody("application/x-www-form-urlencoded", []byte("client_id=app&code=auth-code&code_challenge=c&grant_type=authorization_code")))
+	if redactedBody != "client_id=app&code=REDACTED&code_challenge=REDACTED&grant_type=authorization_code" {
+		t.Errorf("unexpected redacted form %s", redactedBody)
+	}
+
+	// In JSON bodies, code is the code of a resource.
+	if redactedBody := string(redactBody("application/json", []byte(`{"code":"shoes"}`))); redactedBody != `{"code":"shoes"}` {
+		t.Errorf("unexpected redacted body %s", redactedBody)
+	}
+}
+
+func TestCassetteStoresBinaryBodiesInBase64(t *testing.T) {
+	binary := []byte{0xff, 0xd8, 0xff, 0x00}
+
+	encoded, isBase64 := encodeBody(binary)
+	if !isBase64 || string(decodeBody(encoded, isBase64)) != string(binary) {
+		t.Errorf("binary body not kept: %q", encoded)
+	}
+
+	if encoded, isBase64 := encodeBody([]byte(`{"a":1}`)); isBase64 || encoded != `{"a":1}` {
+		t.Errorf("text body should stay readable, got %q", encoded)
+	}
+}
+
+func TestCassetteReplayMissingFile(t *testing.T) {
+	if _, err := NewCassette(filepath.Join(t.TempDir(), "missing.json"), CassetteReplay); err == nil {
+		t.Error("expected an error for a missing cassette")
+	}
+}
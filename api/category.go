@@ -3,12 +3,14 @@ package akeneo
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
+// Category is a category of a tree. A nil Parent is not sent, so an upsert
+// leaves the parent unchanged; an empty Parent is sent as null and makes
+// the category a root.
 type Category struct {
 	Code   string            `json:"code"`
 	Parent *string           `json:"parent,omitempty"`
@@ -29,101 +31,162 @@ type CategoriesResponse struct {
 
 type CategoriesApi ApiService
 
-func (service *CategoriesApi) GetAll(opts RequestOpts) (*CategoriesResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *CategoriesApi) GetAll(opts QueryOptions) (*CategoriesResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+func (service *CategoriesApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*CategoriesResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceCategory, OperationGetAll)
+
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "categories", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "categories", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &CategoriesResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type CategoryIterator struct {
+	*pager
+}
+
+func (service *CategoriesApi) Iterate(opts QueryOptions) *CategoryIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *CategoriesApi) IterateWithContext(ctx context.Context, opts QueryOptions) *CategoryIterator {
+	ctx = withOperation(ctx, ResourceCategory, OperationIterate)
+
+	return &CategoryIterator{pager: newPager(ctx, service.client, "categories", opts, pageQueryKeys, func() collectionPage { return &CategoriesResponse{} })}
+}
+
+func (it *CategoryIterator) Item() *CategoryItem {
+	item, _ := it.item.(*CategoryItem)
+
+	return item
+}
+
+// NewCategoryIterator returns an iterator over items already in memory, e.g. for
+// a test double of CategoryService.
+func NewCategoryIterator(items []CategoryItem) *CategoryIterator {
+	page := &CategoriesResponse{}
+	page.Data.Items = items
+
+	return &CategoryIterator{pager: newItemPager(page)}
+}
+
+func (page *CategoriesResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *CategoriesApi) Get(code string) (*Category, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *CategoriesApi) GetWithContext(ctx context.Context, code string) (*Category, *ApiError) {
+	ctx = withOperation(ctx, ResourceCategory, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("categories/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var successResponse = &Category{}
 	if err = json.NewDecoder(response.Body).Decode(&successResponse); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return successResponse, nil
 }
 
 func (service *CategoriesApi) Create(category *Category) *ApiError {
+	return service.CreateWithContext(context.Background(), category)
+}
+
+func (service *CategoriesApi) CreateWithContext(ctx context.Context, category *Category) *ApiError {
+	ctx = withOperation(ctx, ResourceCategory, OperationCreate)
+
 	headers := service.client.getHeadersForRequest()
 	body, _ := json.Marshal(category)
 
-	response, err := service.client.DoRequest("POST", "categories", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", "categories", headers, body, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *CategoriesApi) Upsert(category *Category) *ApiError {
+	return service.UpsertWithContext(context.Background(), category)
+}
+
+func (service *CategoriesApi) UpsertWithContext(ctx context.Context, category *Category) *ApiError {
+	ctx = withOperation(ctx, ResourceCategory, OperationUpsert)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("categories/%s", category.Code)
 	body, _ := json.Marshal(category)
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Code: 0, Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *CategoriesApi) BatchUpsert(categories []*Category) ([]*ResponseBody, *ApiError) {
+	return service.BatchUpsertWithContext(context.Background(), categories)
+}
+
+func (service *CategoriesApi) BatchUpsertWithContext(ctx context.Context, categories []*Category) ([]*ResponseBody, *ApiError) {
+	ctx = withOperation(ctx, ResourceCategory, OperationBatchUpsert)
+
 	headers := service.client.getHeadersForBatchRequest()
 	var body []byte
 
@@ -133,16 +196,15 @@ func (service *CategoriesApi) BatchUpsert(categories []*Category) ([]*ResponseBo
 		body = append(body, '\n')
 	}
 
-	response, err := service.client.DoRequest("PATCH", "categories", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", "categories", headers, body, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var apiResponse []*ResponseBody
@@ -152,11 +214,53 @@ func (service *CategoriesApi) BatchUpsert(categories []*Category) ([]*ResponseBo
 		var responseLine *ResponseBody
 		var reader = bytes.NewReader(scanner.Bytes())
 		if err = json.NewDecoder(reader).Decode(&responseLine); err != nil {
-			return nil, &ApiError{Message: err.Error()}
+			return nil, newApiError(err)
 		}
 
 		apiResponse = append(apiResponse, responseLine)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, newApiError(err)
+	}
+
+	service.client.observers.batchLines(ctx, apiResponse)
+
 	return apiResponse, nil
 }
+
+// plainCategory has the fields of Category without its MarshalJSON.
+type plainCategory Category
+
+// categoryPayload is the JSON form of a category. Its Parent hides the one of
+// the category: Akeneo only moves a category to the root of a tree on an
+// explicit null.
+type categoryPayload struct {
+	plainCategory
+	Parent interface{} `json:"parent,omitempty"`
+}
+
+func newCategoryPayload(category Category) categoryPayload {
+	payload := categoryPayload{plainCategory: plainCategory(category)}
+	if category.Parent != nil {
+		payload.Parent = *category.Parent
+		if *category.Parent == "" {
+			payload.Parent = json.RawMessage("null")
+		}
+	}
+
+	return payload
+}
+
+func (category Category) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newCategoryPayload(category))
+}
+
+// MarshalJSON keeps the links, which the MarshalJSON promoted from Category
+// would leave out.
+func (item CategoryItem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		categoryPayload
+		ResponseLinks `json:"_links"`
+	}{newCategoryPayload(item.Category), item.ResponseLinks})
+}
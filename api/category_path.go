@@ -0,0 +1,234 @@
+package akeneo
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// maxCodeLength is the longest code Akeneo accepts.
+const maxCodeLength = 100
+
+// CategoryPath is a category given by its path, like
+// "master/apparel/shoes". Labels holds, by locale, the path of the labels
+// of the same categories, like "Master/Apparel/Shoes".
+type CategoryPath struct {
+	Path   string
+	Labels map[string]string
+}
+
+// CategoryPathResolver turns category paths into category codes, adding the
+// missing categories to a CategoryTree. Save then creates them, parents first.
+type CategoryPathResolver struct {
+	Tree *CategoryTree
+
+	// Separator splits the paths. Defaults to "/".
+	Separator string
+
+	// Code returns the code of a new category from the code of its parent,
+	// empty for a root, and its path segment. Defaults to the parent code
+	// and the segment in lower case, joined by an underscore.
+	Code func(parent string, segment string) string
+}
+
+func NewCategoryPathResolver(tree *CategoryTree) *CategoryPathResolver {
+	return &CategoryPathResolver{Tree: tree}
+}
+
+// Resolve returns the code of the last category of each path. A category of
+// a path is an existing child of the previous one whose code is the
+// generated code or the segment, whose label is the one given for the
+// locale, or whose label in any locale is the segment; otherwise it is added
+// to the tree. Every path is checked first, so that an invalid path leaves
+// the tree unchanged.
+func (resolver *CategoryPathResolver) Resolve(paths ...CategoryPath) ([]string, error) {
+	parsed := make([][]categorySegment, 0, len(paths))
+	for _, path := range paths {
+		segments, err := resolver.parse(path)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, segments)
+	}
+
+	codes := make([]string, 0, len(paths))
+	for _, segments := range parsed {
+		code, err := resolver.resolve(segments)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// Save creates the categories added by Resolve.
+func (resolver *CategoryPathResolver) Save(ctx context.Context, service CategoryService) ([]*ResponseBody, *ApiError) {
+	return resolver.Tree.Save(ctx, service)
+}
+
+// categorySegment is a segment of a category path with its labels by
+// locale.
+type categorySegment struct {
+	name   string
+	labels map[string]string
+}
+
+func (resolver *CategoryPathResolver) parse(path CategoryPath) ([]categorySegment, error) {
+	names := resolver.split(path.Path)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("akeneo: empty category path %q", path.Path)
+	}
+
+	segments := make([]categorySegment, len(names))
+	for i, name := range names {
+		segments[i] = categorySegment{name: name, labels: map[string]string{}}
+	}
+
+	for locale, labelPath := range path.Labels {
+		localized := resolver.split(labelPath)
+		if len(localized) != len(names) {
+			return nil, fmt.Errorf("akeneo: the %s labels %q do not match the category path %q", locale, labelPath, path.Path)
+		}
+		for i, label := range localized {
+			segments[i].labels[locale] = label
+		}
+	}
+
+	return segments, nil
+}
+
+func (resolver *CategoryPathResolver) resolve(segments []categorySegment) (string, error) {
+	path := make([]string, 0, len(segments))
+
+	parent := ""
+	for _, segment := range segments {
+		path = append(path, segment.name)
+
+		if existing := resolver.find(parent, segment.name, segment.labels); existing != "" {
+			parent = existing
+			continue
+		}
+
+		code := resolver.code(parent, segment.name, path)
+		category := &Category{Code: code, Labels: segment.labels}
+		if parent != "" {
+			parentCode := parent
+			category.Parent = &parentCode
+		}
+		if len(category.Labels) == 0 {
+			category.Labels = nil
+		}
+
+		if err := resolver.Tree.Add(category); err != nil {
+			return "", err
+		}
+		parent = code
+	}
+
+	return parent, nil
+}
+
+func (resolver *CategoryPathResolver) split(path string) []string {
+	separator := resolver.Separator
+	if separator == "" {
+		separator = "/"
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(path, separator) {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments
+}
+
+// find returns the code of the child of parent matching segment, if any.
+func (resolver *CategoryPathResolver) find(parent string, segment string, labels map[string]string) string {
+	candidates := resolver.Tree.Roots()
+	if parent != "" {
+		candidates = resolver.Tree.Children(parent)
+	}
+
+	generated := resolver.generatedCode(parent, segment)
+	for _, candidate := range candidates {
+		if candidate.Code() == generated || candidate.Code() == segment {
+			return candidate.Code()
+		}
+	}
+
+	for _, candidate := range candidates {
+		for locale, label := range labels {
+			if candidate.Category.Labels[locale] == label {
+				return candidate.Code()
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, label := range candidate.Category.Labels {
+			if strings.EqualFold(label, segment) {
+				return candidate.Code()
+			}
+		}
+	}
+
+	return ""
+}
+
+// code returns the code of a new category, made unique with a hash of its
+// path when it is already used elsewhere in the tree, and then with a
+// counter.
+func (resolver *CategoryPathResolver) code(parent string, segment string, path []string) string {
+	code := resolver.generatedCode(parent, segment)
+	if resolver.Tree.Get(code) == nil {
+		return code
+	}
+
+	suffix := "_" + pathHash(path)
+	unique := limitCode(code, suffix)
+	for i := 2; resolver.Tree.Get(unique) != nil; i++ {
+		unique = limitCode(code, fmt.Sprintf("%s_%d", suffix, i))
+	}
+
+	return unique
+}
+
+func (resolver *CategoryPathResolver) generatedCode(parent string, segment string) string {
+	if resolver.Code != nil {
+		return resolver.Code(parent, segment)
+	}
+
+	slug := strings.Trim(codeUnsafeCharacters.ReplaceAllString(strings.ToLower(segment), "_"), "_")
+	if slug == "" {
+		slug = pathHash([]string{segment})
+	}
+	if parent != "" {
+		slug = parent + "_" + slug
+	}
+
+	return limitCode(slug, "")
+}
+
+// limitCode appends suffix to code, cutting code so that the result is not
+// longer than Akeneo allows. A cut code gets a hash of the full code.
+func limitCode(code string, suffix string) string {
+	if len(code)+len(suffix) <= maxCodeLength {
+		return code + suffix
+	}
+
+	hash := "_" + pathHash([]string{code})
+
+	return code[:maxCodeLength-len(suffix)-len(hash)] + hash + suffix
+}
+
+func pathHash(path []string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(strings.Join(path, "\x00")))
+
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
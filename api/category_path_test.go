@@ -0,0 +1,137 @@
+package akeneo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCategoryPathResolverFindsExistingCategories(t *testing.T) {
+	tree := newTestCategoryTree(t)
+	resolver := NewCategoryPathResolver(tree)
+
+	codes, err := resolver.Resolve(
+		CategoryPath{Path: "master/shoes/boots"},
+		CategoryPath{Path: " Master / SHOES "},
+		CategoryPath{Path: "master/Chaussures", Labels: map[string]string{"en_US": "Master/Shoes"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if joined := strings.Join(codes, ","); joined != "boots,shoes,shoes" {
+		t.Errorf("unexpected codes %s", joined)
+	}
+	if changes := tree.Changes(); len(changes) != 0 {
+		t.Errorf("expected no new category, got %v", changes)
+	}
+}
+
+func TestCategoryPathResolverAddsMissingCategories(t *testing.T) {
+	tree := newTestCategoryTree(t)
+	resolver := NewCategoryPathResolver(tree)
+
+	codes, err := resolver.Resolve(
+		CategoryPath{Path: "master/shoes/Running Shoes", Labels: map[string]string{"fr_FR": "Maître/Chaussures/Course"}},
+		CategoryPath{Path: "outlet/shoes"},
+		CategoryPath{Path: "master/shoes/running shoes/trail"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if joined := strings.Join(codes, ","); joined != "shoes_running_shoes,outlet_shoes,shoes_running_shoes_trail" {
+		t.Errorf("unexpected codes %s", joined)
+	}
+
+	running := tree.Get("shoes_running_shoes").Category
+	if *running.Parent != "shoes" || running.Labels["fr_FR"] != "Course" || len(running.Labels) != 1 {
+		t.Errorf("unexpected category %+v", running)
+	}
+
+	var changes []string
+	for _, category := range tree.Changes() {
+		changes = append(changes, category.Code)
+	}
+	if joined := strings.Join(changes, ","); joined != "shoes_running_shoes,shoes_running_shoes_trail,outlet,outlet_shoes" {
+		t.Errorf("unexpected changes %s", joined)
+	}
+}
+
+func TestCategoryPathResolverCodes(t *testing.T) {
+	tree := newTestCategoryTree(t)
+	resolver := NewCategoryPathResolver(tree)
+	resolver.Separator = ">"
+	resolver.Code = func(parent string, segment string) string {
+		return "shoes"
+	}
+
+	// The code is taken in another tree, so it is made unique.
+	codes, err := resolver.Resolve(CategoryPath{Path: "sales > Shoes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(codes[0], "shoes_") || len(codes[0]) != len("shoes_")+8 {
+		t.Errorf("unexpected code %s", codes[0])
+	}
+
+	if _, err := resolver.Resolve(CategoryPath{Path: " > "}); err == nil {
+		t.Error("expected an error for an empty path")
+	}
+	if _, err := resolver.Resolve(CategoryPath{Path: "a > b", Labels: map[string]string{"en_US": "A"}}); err == nil {
+		t.Error("expected an error for labels that do not match the path")
+	}
+}
+
+func TestCategoryPathResolverLeavesTreeUnchangedOnError(t *testing.T) {
+	tree := newTestCategoryTree(t)
+	resolver := NewCategoryPathResolver(tree)
+
+	_, err := resolver.Resolve(
+		CategoryPath{Path: "master/shoes/sneakers"},
+		CategoryPath{Path: "outlet/shoes", Labels: map[string]string{"en_US": "Outlet"}},
+	)
+	if err == nil {
+		t.Fatal("expected an error for labels that do not match the path")
+	}
+	if changes := tree.Changes(); len(changes) != 0 {
+		t.Errorf("expected no new category, got %v", changes)
+	}
+}
+
+func TestCategoryPathResolverCountsCollidingCodes(t *testing.T) {
+	tree := newTestCategoryTree(t)
+	resolver := NewCategoryPathResolver(tree)
+	resolver.Code = func(parent string, segment string) string {
+		return "shoes"
+	}
+
+	hashed := "shoes_" + pathHash([]string{"sales", "Shoes"})
+	for _, code := range []string{hashed, hashed + "_2"} {
+		if err := tree.Add(&Category{Code: code}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	codes, err := resolver.Resolve(CategoryPath{Path: "sales/Shoes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes[0] != hashed+"_3" || *tree.Get(codes[0]).Category.Parent != "sales" {
+		t.Errorf("unexpected code %s", codes[0])
+	}
+}
+
+func TestLimitCode(t *testing.T) {
+	long := strings.Repeat("a", 120)
+
+	limited := limitCode(long, "_suffix")
+	if len(limited) != maxCodeLength || !strings.HasSuffix(limited, "_suffix") {
+		t.Errorf("unexpected code %s", limited)
+	}
+	if limitCode(strings.Repeat("a", 119), "") == limitCode(long, "") {
+		t.Error("cut codes of different codes are equal")
+	}
+	if code := limitCode("short", "_x"); code != "short_x" {
+		t.Errorf("unexpected code %s", code)
+	}
+}
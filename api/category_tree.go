@@ -0,0 +1,336 @@
+package akeneo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxBatchSize is the number of lines Akeneo accepts in one batch upsert.
+const maxBatchSize = 100
+
+var ErrCategoryCycle = errors.New("akeneo: a category cannot be moved under itself or one of its descendants")
+
+type CategoryNode struct {
+	Category *Category
+	Parent   *CategoryNode
+	Children []*CategoryNode
+}
+
+func (node *CategoryNode) Code() string {
+	return node.Category.Code
+}
+
+// Label returns the label of the category in locale, or its code.
+func (node *CategoryNode) Label(locale string) string {
+	if label := node.Category.Labels[locale]; label != "" {
+		return label
+	}
+
+	return node.Category.Code
+}
+
+// CategoryTree holds categories in memory as the trees Akeneo shows: the
+// categories without parent are the roots of the trees. Added and moved
+// categories are kept until they are saved with Save.
+type CategoryTree struct {
+	roots []*CategoryNode
+	nodes map[string]*CategoryNode
+	moved map[string]bool
+}
+
+// NewCategoryTree builds the tree of categories, which must hold the parent
+// of each category. Children keep the order of categories.
+func NewCategoryTree(categories []*Category) (*CategoryTree, error) {
+	tree := &CategoryTree{nodes: map[string]*CategoryNode{}, moved: map[string]bool{}}
+
+	for _, category := range categories {
+		if _, known := tree.nodes[category.Code]; known {
+			return nil, fmt.Errorf("akeneo: category %q is given twice", category.Code)
+		}
+		tree.nodes[category.Code] = &CategoryNode{Category: category}
+	}
+
+	for _, category := range categories {
+		node := tree.nodes[category.Code]
+		if category.Parent == nil || *category.Parent == "" {
+			tree.roots = append(tree.roots, node)
+			continue
+		}
+
+		parent, known := tree.nodes[*category.Parent]
+		if !known {
+			return nil, fmt.Errorf("akeneo: parent %q of category %q does not exist", *category.Parent, category.Code)
+		}
+
+		node.Parent = parent
+		parent.Children = append(parent.Children, node)
+	}
+
+	// Every category reaches a root unless parents form a cycle.
+	reached := 0
+	for _, root := range tree.roots {
+		reached += len(tree.descendants(root)) + 1
+	}
+	if reached != len(tree.nodes) {
+		return nil, fmt.Errorf("akeneo: the parents of %d categories form a cycle", len(tree.nodes)-reached)
+	}
+
+	return tree, nil
+}
+
+// LoadCategoryTree fetches every category and builds their tree.
+func LoadCategoryTree(ctx context.Context, service CategoryService) (*CategoryTree, *ApiError) {
+	var categories []*Category
+
+	it := service.IterateWithContext(ctx, PageOptions{Limit: maxPageLimit})
+	for it.Next() {
+		category := it.Item().Category
+		categories = append(categories, &category)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	tree, err := NewCategoryTree(categories)
+	if err != nil {
+		return nil, newApiError(err)
+	}
+
+	return tree, nil
+}
+
+func (tree *CategoryTree) Roots() []*CategoryNode {
+	return tree.roots
+}
+
+func (tree *CategoryTree) Get(code string) *CategoryNode {
+	return tree.nodes[code]
+}
+
+func (tree *CategoryTree) Children(code string) []*CategoryNode {
+	if node := tree.nodes[code]; node != nil {
+		return node.Children
+	}
+
+	return nil
+}
+
+// Ancestors returns the ancestors of a category, from its root to its parent.
+func (tree *CategoryTree) Ancestors(code string) []*CategoryNode {
+	node := tree.nodes[code]
+	if node == nil {
+		return nil
+	}
+
+	var ancestors []*CategoryNode
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		ancestors = append([]*CategoryNode{parent}, ancestors...)
+	}
+
+	return ancestors
+}
+
+// Descendants returns the categories under code, depth first.
+func (tree *CategoryTree) Descendants(code string) []*CategoryNode {
+	if node := tree.nodes[code]; node != nil {
+		return tree.descendants(node)
+	}
+
+	return nil
+}
+
+// Path returns the codes from the root of the category to the category.
+func (tree *CategoryTree) Path(code string) []string {
+	node := tree.nodes[code]
+	if node == nil {
+		return nil
+	}
+
+	var path []string
+	for _, ancestor := range tree.Ancestors(code) {
+		path = append(path, ancestor.Code())
+	}
+
+	return append(path, code)
+}
+
+// Breadcrumb returns the labels in locale from the root of the category to
+// the category, joined by separator.
+func (tree *CategoryTree) Breadcrumb(code string, locale string, separator string) string {
+	node := tree.nodes[code]
+	if node == nil {
+		return ""
+	}
+
+	var labels []string
+	for _, ancestor := range tree.Ancestors(code) {
+		labels = append(labels, ancestor.Label(locale))
+	}
+
+	return strings.Join(append(labels, node.Label(locale)), separator)
+}
+
+// Subtree returns a new tree made of a copy of the category, as its root,
+// and of its descendants.
+func (tree *CategoryTree) Subtree(code string) *CategoryTree {
+	node := tree.nodes[code]
+	if node == nil {
+		return nil
+	}
+
+	root := *node.Category
+	root.Parent = nil
+	categories := []*Category{&root}
+
+	for _, descendant := range tree.descendants(node) {
+		category := *descendant.Category
+		categories = append(categories, &category)
+	}
+
+	subtree, _ := NewCategoryTree(categories)
+
+	return subtree
+}
+
+// Add inserts a new category under its parent, or as a root. It is sent by
+// Save like a moved category.
+func (tree *CategoryTree) Add(category *Category) error {
+	if _, known := tree.nodes[category.Code]; known {
+		return fmt.Errorf("akeneo: category %q already exists", category.Code)
+	}
+
+	parent := ""
+	if category.Parent != nil {
+		parent = *category.Parent
+	}
+	if parent != "" && tree.nodes[parent] == nil {
+		return fmt.Errorf("akeneo: category %q does not exist", parent)
+	}
+
+	node := &CategoryNode{Category: category}
+	tree.nodes[category.Code] = node
+	tree.roots = append(tree.roots, node)
+
+	if err := tree.Move(category.Code, parent); err != nil {
+		return err
+	}
+	tree.moved[category.Code] = true
+
+	return nil
+}
+
+// Move gives a new parent to a category, or makes it a root when parent is
+// empty. The category is appended to the children of its new parent.
+func (tree *CategoryTree) Move(code string, parent string) error {
+	node := tree.nodes[code]
+	if node == nil {
+		return fmt.Errorf("akeneo: category %q does not exist", code)
+	}
+
+	var newParent *CategoryNode
+	if parent != "" {
+		newParent = tree.nodes[parent]
+		if newParent == nil {
+			return fmt.Errorf("akeneo: category %q does not exist", parent)
+		}
+
+		for ancestor := newParent; ancestor != nil; ancestor = ancestor.Parent {
+			if ancestor == node {
+				return ErrCategoryCycle
+			}
+		}
+	}
+
+	if node.Parent == newParent {
+		return nil
+	}
+
+	if node.Parent != nil {
+		node.Parent.Children = removeCategoryNode(node.Parent.Children, node)
+	} else {
+		tree.roots = removeCategoryNode(tree.roots, node)
+	}
+
+	node.Parent = newParent
+	if newParent != nil {
+		newParent.Children = append(newParent.Children, node)
+		node.Category.Parent = &parent
+	} else {
+		// An empty parent is sent as null, which a nil parent is not.
+		root := ""
+		tree.roots = append(tree.roots, node)
+		node.Category.Parent = &root
+	}
+
+	tree.moved[code] = true
+
+	return nil
+}
+
+// Changes returns the categories added or moved since the tree was built or saved,
+// parents before their children.
+func (tree *CategoryTree) Changes() []*Category {
+	var changes []*Category
+
+	for _, root := range tree.roots {
+		for _, node := range append([]*CategoryNode{root}, tree.descendants(root)...) {
+			if tree.moved[node.Code()] {
+				changes = append(changes, node.Category)
+			}
+		}
+	}
+
+	return changes
+}
+
+// Save sends the added and moved categories with CategoriesApi.BatchUpsert, parents
+// first and by batches of 100. A category whose line fails stays in
+// Changes.
+func (tree *CategoryTree) Save(ctx context.Context, service CategoryService) ([]*ResponseBody, *ApiError) {
+	var lines []*ResponseBody
+	changes := tree.Changes()
+
+	for start := 0; start < len(changes); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(changes) {
+			end = len(changes)
+		}
+
+		batch, err := service.BatchUpsertWithContext(ctx, changes[start:end])
+		if err != nil {
+			return lines, err
+		}
+
+		for _, line := range batch {
+			if line != nil && line.StatusCode < 300 {
+				delete(tree.moved, line.Code)
+			}
+		}
+
+		lines = append(lines, batch...)
+	}
+
+	return lines, nil
+}
+
+func (tree *CategoryTree) descendants(node *CategoryNode) []*CategoryNode {
+	var descendants []*CategoryNode
+	for _, child := range node.Children {
+		descendants = append(descendants, child)
+		descendants = append(descendants, tree.descendants(child)...)
+	}
+
+	return descendants
+}
+
+func removeCategoryNode(nodes []*CategoryNode, removed *CategoryNode) []*CategoryNode {
+	for i, node := range nodes {
+		if node == removed {
+			return append(nodes[:i:i], nodes[i+1:]...)
+		}
+	}
+
+	return nodes
+}
@@ -0,0 +1,192 @@
+package akeneo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestCategoryTree(t *testing.T) *CategoryTree {
+	master, shoes := "master", "shoes"
+
+	tree, err := NewCategoryTree([]*Category{
+		{Code: "master", Labels: map[string]string{"en_US": "Master"}},
+		{Code: "shoes", Parent: &master, Labels: map[string]string{"en_US": "Shoes"}},
+		{Code: "boots", Parent: &shoes},
+		{Code: "sandals", Parent: &shoes},
+		{Code: "shirts", Parent: &master},
+		{Code: "sales"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tree
+}
+
+func categoryCodes(nodes []*CategoryNode) string {
+	codes := make([]string, len(nodes))
+	for i, node := range nodes {
+		codes[i] = node.Code()
+	}
+
+	return strings.Join(codes, ",")
+}
+
+func TestCategoryTreeTraversal(t *testing.T) {
+	tree := newTestCategoryTree(t)
+
+	if codes := categoryCodes(tree.Roots()); codes != "master,sales" {
+		t.Errorf("unexpected roots %s", codes)
+	}
+	if codes := categoryCodes(tree.Descendants("master")); codes != "shoes,boots,sandals,shirts" {
+		t.Errorf("unexpected descendants %s", codes)
+	}
+	if codes := categoryCodes(tree.Ancestors("boots")); codes != "master,shoes" {
+		t.Errorf("unexpected ancestors %s", codes)
+	}
+	if path := strings.Join(tree.Path("boots"), "/"); path != "master/shoes/boots" {
+		t.Errorf("unexpected path %s", path)
+	}
+	if breadcrumb := tree.Breadcrumb("boots", "en_US", " > "); breadcrumb != "Master > Shoes > boots" {
+		t.Errorf("unexpected breadcrumb %s", breadcrumb)
+	}
+
+	subtree := tree.Subtree("shoes")
+	if codes := categoryCodes(subtree.Roots()); codes != "shoes" || tree.Get("shoes").Category.Parent == nil {
+		t.Errorf("unexpected subtree roots %s, or the tree was changed", codes)
+	}
+}
+
+func TestNewCategoryTreeRejectsInvalidParents(t *testing.T) {
+	a, b, missing := "a", "b", "missing"
+
+	invalid := [][]*Category{
+		{{Code: "a"}, {Code: "a"}},
+		{{Code: "a", Parent: &missing}},
+		{{Code: "a", Parent: &b}, {Code: "b", Parent: &a}},
+	}
+	for _, categories := range invalid {
+		if _, err := NewCategoryTree(categories); err == nil {
+			t.Errorf("expected an error for %v", categories)
+		}
+	}
+}
+
+func TestCategoryTreeMove(t *testing.T) {
+	tree := newTestCategoryTree(t)
+
+	if err := tree.Move("shoes", "boots"); err != ErrCategoryCycle {
+		t.Errorf("expected ErrCategoryCycle, got %v", err)
+	}
+	if err := tree.Move("shoes", "missing"); err == nil {
+		t.Error("expected an error for a missing parent")
+	}
+
+	if err := tree.Move("boots", "sales"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Add(&Category{Code: "outlet"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if codes := categoryCodes(tree.Children("shoes")); codes != "sandals" {
+		t.Errorf("unexpected children %s", codes)
+	}
+	if path := strings.Join(tree.Path("boots"), "/"); path != "sales/boots" {
+		t.Errorf("unexpected path %s", path)
+	}
+
+	var changes []string
+	for _, category := range tree.Changes() {
+		changes = append(changes, category.Code)
+	}
+	if joined := strings.Join(changes, ","); joined != "boots,outlet" {
+		t.Errorf("unexpected changes %s", joined)
+	}
+}
+
+func TestCategoryTreeSaveSendsNullParentForRoots(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/oauth/v1/token":
+			fmt.Fprint(w, `{"access_token":"access","refresh_token":"refresh","token_type":"bearer","expires_in":3600}`)
+		case "/api/rest/v1/categories":
+			data, _ := ioutil.ReadAll(r.Body)
+			body = string(data)
+			w.Header().Set("Content-Type", "application/vnd.akeneo.collection+json")
+			for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+				code := line[strings.Index(line, `"code":"`)+8:]
+				fmt.Fprintf(w, `{"line":1,"code":"%s","status_code":204}`+"\n", code[:strings.Index(code, `"`)])
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := NewAkeneoApi(NewClient(&ClientConfig{BaseUrl: server.URL, ClientId: "client", SecretKey: "secret", Username: "admin", Password: "admin"}))
+
+	tree := newTestCategoryTree(t)
+	if err := tree.Move("shoes", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Move("shirts", "shoes"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tree.Save(context.Background(), api.Category); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"code":"shoes","labels":{"en_US":"Shoes"},"parent":null}` + "\n" + `{"code":"shirts","parent":"shoes"}` + "\n"
+	if body != expected {
+		t.Errorf("unexpected body\n got: %s\nwant: %s", body, expected)
+	}
+	if changes := tree.Changes(); len(changes) != 0 {
+		t.Errorf("expected the saved changes to be cleared, got %v", changes)
+	}
+
+}
+
+func TestCategoryMarshalJSON(t *testing.T) {
+	root, shoes := "", "shoes"
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		// A nil parent is left out, which keeps the parent on a PATCH.
+		{&Category{Code: "sales"}, `{"code":"sales"}`},
+		{&Category{Code: "shoes", Parent: &root}, `{"code":"shoes","parent":null}`},
+		{Category{Code: "boots", Parent: &shoes}, `{"code":"boots","parent":"shoes"}`},
+		{
+			&CategoryItem{Category: Category{Code: "shoes", Parent: &root}, ResponseLinks: ResponseLinks{Self: ResponseLink{Href: "http://localhost/api/rest/v1/categories/shoes"}}},
+			`{"code":"shoes","parent":null,"_links":{"self":{"href":"http://localhost/api/rest/v1/categories/shoes"}`,
+		},
+	}
+
+	for _, test := range tests {
+		encoded, err := json.Marshal(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(encoded), test.expected) {
+			t.Errorf("unexpected JSON %s, expected %s", encoded, test.expected)
+		}
+	}
+
+	item := &CategoryItem{}
+	if err := json.Unmarshal([]byte(`{"code":"boots","parent":"shoes","_links":{"self":{"href":"http://localhost"}}}`), item); err != nil {
+		t.Fatal(err)
+	}
+	if item.Code != "boots" || *item.Parent != "shoes" || item.Self.Href != "http://localhost" {
+		t.Errorf("unexpected item %+v", item)
+	}
+}
@@ -3,10 +3,9 @@ package akeneo
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
 type Channel struct {
@@ -32,101 +31,162 @@ type ChannelResponse struct {
 
 type ChannelApi ApiService
 
-func (service *ChannelApi) GetAll(opts RequestOpts) (*ChannelResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *ChannelApi) GetAll(opts QueryOptions) (*ChannelResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+func (service *ChannelApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*ChannelResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceChannel, OperationGetAll)
+
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "channels", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "channels", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &ChannelResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type ChannelIterator struct {
+	*pager
+}
+
+func (service *ChannelApi) Iterate(opts QueryOptions) *ChannelIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *ChannelApi) IterateWithContext(ctx context.Context, opts QueryOptions) *ChannelIterator {
+	ctx = withOperation(ctx, ResourceChannel, OperationIterate)
+
+	return &ChannelIterator{pager: newPager(ctx, service.client, "channels", opts, pageQueryKeys, func() collectionPage { return &ChannelResponse{} })}
+}
+
+func (it *ChannelIterator) Item() *ChannelItem {
+	item, _ := it.item.(*ChannelItem)
+
+	return item
+}
+
+// NewChannelIterator returns an iterator over items already in memory, e.g. for
+// a test double of ChannelService.
+func NewChannelIterator(items []ChannelItem) *ChannelIterator {
+	page := &ChannelResponse{}
+	page.Data.Items = items
+
+	return &ChannelIterator{pager: newItemPager(page)}
+}
+
+func (page *ChannelResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *ChannelApi) Get(code string) (*Channel, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *ChannelApi) GetWithContext(ctx context.Context, code string) (*Channel, *ApiError) {
+	ctx = withOperation(ctx, ResourceChannel, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("channels/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var successResponse = &Channel{}
 	if err = json.NewDecoder(response.Body).Decode(&successResponse); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return successResponse, nil
 }
 
 func (service *ChannelApi) Create(category *Channel) *ApiError {
+	return service.CreateWithContext(context.Background(), category)
+}
+
+func (service *ChannelApi) CreateWithContext(ctx context.Context, category *Channel) *ApiError {
+	ctx = withOperation(ctx, ResourceChannel, OperationCreate)
+
 	headers := service.client.getHeadersForRequest()
 	body, _ := json.Marshal(category)
 
-	response, err := service.client.DoRequest("POST", "channels", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", "channels", headers, body, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *ChannelApi) Upsert(category *Channel) *ApiError {
+	return service.UpsertWithContext(context.Background(), category)
+}
+
+func (service *ChannelApi) UpsertWithContext(ctx context.Context, category *Channel) *ApiError {
+	ctx = withOperation(ctx, ResourceChannel, OperationUpsert)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("channels/%s", category.Code)
 	body, _ := json.Marshal(category)
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Code: 0, Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *ChannelApi) BatchUpsert(categories []*Channel) ([]*ResponseBody, *ApiError) {
+	return service.BatchUpsertWithContext(context.Background(), categories)
+}
+
+func (service *ChannelApi) BatchUpsertWithContext(ctx context.Context, categories []*Channel) ([]*ResponseBody, *ApiError) {
+	ctx = withOperation(ctx, ResourceChannel, OperationBatchUpsert)
+
 	headers := service.client.getHeadersForBatchRequest()
 	var body []byte
 
@@ -136,16 +196,15 @@ func (service *ChannelApi) BatchUpsert(categories []*Channel) ([]*ResponseBody,
 		body = append(body, '\n')
 	}
 
-	response, err := service.client.DoRequest("PATCH", "channels", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", "channels", headers, body, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var apiResponse []*ResponseBody
@@ -155,11 +214,17 @@ func (service *ChannelApi) BatchUpsert(categories []*Channel) ([]*ResponseBody,
 		var responseLine *ResponseBody
 		var reader = bytes.NewReader(scanner.Bytes())
 		if err = json.NewDecoder(reader).Decode(&responseLine); err != nil {
-			return nil, &ApiError{Message: err.Error()}
+			return nil, newApiError(err)
 		}
 
 		apiResponse = append(apiResponse, responseLine)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, newApiError(err)
+	}
+
+	service.client.observers.batchLines(ctx, apiResponse)
+
 	return apiResponse, nil
 }
\ No newline at end of file
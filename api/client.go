@@ -2,6 +2,7 @@ package akeneo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"golang.org/x/oauth2"
@@ -16,31 +17,69 @@ const (
 )
 
 type Client struct {
-	httpClient *http.Client
-	auth       *ClientAuth
-	baseUrl    string
-	apiVersion string
-	userAgent  string
+	httpClient  *http.Client
+	auth        Authenticator
+	baseUrl     string
+	apiVersion  string
+	userAgent   string
+	retryPolicy *RetryPolicy
+	rateLimiter *RateLimiter
+	transport   RoundTripFunc
+	observers   observers
 }
 
 type ClientConfig struct {
-	BaseUrl        string
-	UserAgent      string
-	Username       string
-	Password       string
-	ClientId       string
-	SecretKey      string
-	RequestTimeout time.Duration
+	BaseUrl            string
+	UserAgent          string
+	Username           string
+	Password           string
+	ClientId           string
+	SecretKey          string
+	RequestTimeout     time.Duration
+	RetryPolicy        *RetryPolicy
+	RateLimit          *RateLimitConfig
+	TokenRefreshMargin time.Duration
+	TokenStore         TokenStore
+	Authenticator      Authenticator
+	HttpClient         *http.Client
+	Transport          http.RoundTripper
+	Middlewares        []Middleware
+	Observers          []Observer
 }
 
 func NewClient(config *ClientConfig) *Client {
 
-	httpClient := &http.Client{
-		Timeout: config.RequestTimeout,
+	httpClient := config.HttpClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout:   config.RequestTimeout,
+			Transport: config.Transport,
+		}
 	}
 
-	return &Client{
-		auth: &ClientAuth{
+	transport := chainMiddlewares(httpClient.Do, config.Middlewares)
+
+	refreshMargin := config.TokenRefreshMargin
+	if refreshMargin <= 0 {
+		refreshMargin = DefaultTokenRefreshMargin
+	}
+
+	var rateLimiter *RateLimiter
+	if config.RateLimit != nil {
+		rateLimiter = NewRateLimiter(config.RateLimit)
+	}
+
+	tokenStore := config.TokenStore
+	if tokenStore == nil {
+		tokenStore = NewMemoryTokenStore()
+	}
+
+	var auth Authenticator = config.Authenticator
+	if appAuth, ok := auth.(*AppAuth); ok {
+		appAuth.useTransport(transport, config.Observers)
+	}
+	if auth == nil {
+		auth = &ClientAuth{
 			authURL:   config.BaseUrl + "/api/oauth/v1/token",
 			clientId:  config.ClientId,
 			secretKey: config.SecretKey,
@@ -48,19 +87,97 @@ func NewClient(config *ClientConfig) *Client {
 			body: &AuthBody{
 				Username:  config.Username,
 				Password:  config.Password,
-				GrantType: "password",
+				GrantType: GrantTypePassword,
 			},
-			client: httpClient,
-		},
-		baseUrl:    config.BaseUrl,
-		apiVersion: API_VERSION_V1,
-		userAgent:  config.BaseUrl,
-		httpClient: httpClient,
+			transport:     transport,
+			observers:     config.Observers,
+			store:         tokenStore,
+			refreshMargin: refreshMargin,
+		}
+	}
+
+	return &Client{
+		auth:        auth,
+		baseUrl:     config.BaseUrl,
+		apiVersion:  API_VERSION_V1,
+		userAgent:   config.BaseUrl,
+		httpClient:  httpClient,
+		retryPolicy: config.RetryPolicy,
+		rateLimiter: rateLimiter,
+		transport:   transport,
+		observers:   config.Observers,
 	}
 }
 
-func (c *Client) callAPI(request *http.Request) (*http.Response, error) {
-	return c.httpClient.Do(request)
+func (c *Client) RateLimiter() *RateLimiter {
+	return c.rateLimiter
+}
+
+func (c *Client) callAPI(request *http.Request, attempt int) (*http.Response, error) {
+	release := func() {}
+
+	if c.rateLimiter != nil {
+		var err error
+		if release, err = c.rateLimiter.Wait(request.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	op, _ := OperationFromContext(request.Context())
+	if op == nil {
+		op = &Operation{}
+	}
+
+	event := &RequestEvent{
+		Resource:  op.Resource,
+		Operation: op.Name,
+		Method:    request.Method,
+		Url:       request.URL.String(),
+		Attempt:   attempt,
+	}
+
+	c.observers.requestStart(event)
+	started := time.Now()
+
+	response, err := c.transport(request)
+
+	end := *event
+	end.Duration = time.Since(started)
+	end.Err = err
+	if response != nil {
+		end.StatusCode = response.StatusCode
+	}
+
+	c.observers.requestEnd(&end)
+	holdUntilClosed(response, release)
+
+	return response, err
+}
+
+func (c *Client) observeRetry(request *http.Request, attempt int, delay time.Duration, response *http.Response, err error) {
+	if len(c.observers) == 0 {
+		return
+	}
+
+	op, _ := OperationFromContext(request.Context())
+	if op == nil {
+		op = &Operation{}
+	}
+
+	event := &RetryEvent{
+		Resource:  op.Resource,
+		Operation: op.Name,
+		Method:    request.Method,
+		Attempt:   attempt + 1,
+		Delay:     delay,
+		Err:       err,
+	}
+
+	if response != nil {
+		event.StatusCode = response.StatusCode
+	}
+
+	c.observers.retry(event)
 }
 
 func (c *Client) prepareRequestUrl(path string) string {
@@ -86,20 +203,24 @@ func (c *Client) getHeadersForBatchRequest() *http.Header {
 }
 
 func (c *Client) DoRequest(method string, uri string, headers *http.Header, bodyParams []byte, queryParams *url.Values) (response *http.Response, err error) {
-	var body = &bytes.Buffer{}
-	uri = c.prepareRequestUrl(uri)
+	return c.DoRequestWithContext(context.Background(), method, uri, headers, bodyParams, queryParams)
+}
 
-	if bodyParams != nil {
-		_, err := body.Write(bodyParams)
-		if err != nil {
-			return nil, err
-		}
+func (c *Client) DoRequestWithContext(ctx context.Context, method string, uri string, headers *http.Header, bodyParams []byte, queryParams *url.Values) (response *http.Response, err error) {
+	reqUrl, err := c.buildRequestUrl(uri, queryParams)
+
+	if err != nil {
+		return nil, err
 	}
 
-	reqUrl, err := url.Parse(uri)
+	return c.doRequestUrl(ctx, method, reqUrl, headers, bodyParams)
+}
+
+func (c *Client) buildRequestUrl(uri string, queryParams *url.Values) (string, error) {
+	reqUrl, err := url.Parse(c.prepareRequestUrl(uri))
 
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	if queryParams != nil {
@@ -113,21 +234,39 @@ func (c *Client) DoRequest(method string, uri string, headers *http.Header, body
 		reqUrl.RawQuery = query.Encode()
 	}
 
-	request, err := http.NewRequest(method, reqUrl.String(), body)
+	return reqUrl.String(), nil
+}
 
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) doRequestUrl(ctx context.Context, method string, reqUrl string, headers *http.Header, bodyParams []byte) (response *http.Response, err error) {
+	for attempt := 0; ; attempt++ {
+		request, err := http.NewRequestWithContext(ctx, method, reqUrl, bytes.NewReader(bodyParams))
 
-	request.Header = *headers
+		if err != nil {
+			return nil, err
+		}
 
-	token, err := c.auth.GetToken()
-	if err != nil {
-		return nil, err
-	}
+		request.Header = headers.Clone()
 
-	token.SetAuthHeader(request)
-	return c.callAPI(request)
+		token, err := c.auth.GetTokenWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		token.SetAuthHeader(request)
+		response, err = c.callAPI(request, attempt)
+
+		if !c.retryPolicy.shouldRetry(attempt, method, response, err) {
+			return response, err
+		}
+
+		delay := c.retryPolicy.delay(attempt, response)
+		c.observeRetry(request, attempt, delay, response, err)
+		discardBody(response)
+
+		if err := waitForRetry(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
 }
 
 type Token struct {
@@ -135,47 +274,159 @@ type Token struct {
 	ExpiresIn int `json:"expires_in"`
 }
 
+const (
+	GrantTypePassword          = "password"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeAuthorizationCode = "authorization_code"
+
+	DefaultTokenRefreshMargin = time.Minute
+)
+
 type AuthBody struct {
-	Username  string `json:"username"`
-	Password  string `json:"password"`
-	GrantType string `json:"grant_type"`
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	GrantType    string `json:"grant_type"`
 }
 
 type ClientAuth struct {
 	sync.RWMutex
-	authURL   string
-	clientId  string
-	secretKey string
-	userAgent string
-	body      *AuthBody
-	client    *http.Client
-	token     *Token
+	authURL       string
+	clientId      string
+	secretKey     string
+	userAgent     string
+	body          *AuthBody
+	transport     RoundTripFunc
+	observers     observers
+	token         *Token
+	store         TokenStore
+	refreshMargin time.Duration
 }
 
 func (ca *ClientAuth) GetToken() (*Token, error) {
-	var err error
+	return ca.GetTokenWithContext(context.Background())
+}
 
+// GetTokenWithContext returns the current token, renewing it once it is
+// within the refresh margin of its expiry. The token is shared through the
+// TokenStore: a token renewed by another holder of the store is picked up
+// instead of requesting a new one. Renewal uses the refresh_token grant when
+// possible and falls back to the password grant when the refresh token is
+// rejected.
+func (ca *ClientAuth) GetTokenWithContext(ctx context.Context) (*Token, error) {
 	ca.Lock()
 	defer ca.Unlock()
 
-	if ca.token == nil || !ca.token.Valid() {
-		ca.token, err = ca.receiveToken()
-		if err != nil {
-			return nil, err
+	if ca.token != nil && !ca.expiresSoon(ca.token) {
+		return ca.token, nil
+	}
+
+	if token, err := ca.store.Load(); err == nil && token != nil && !ca.expiresSoon(token) {
+		ca.token = token
+		return ca.token, nil
+	}
+
+	unlock, err := ca.store.Lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer unlock()
+
+	if token, err := ca.store.Load(); err == nil && token != nil {
+		ca.token = token
+		if !ca.expiresSoon(token) {
+			return ca.token, nil
 		}
 	}
 
+	token, err := ca.renewToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ca.token = token
+
+	if err := ca.store.Save(token); err != nil {
+		return nil, err
+	}
+
 	return ca.token, nil
 }
 
-func (ca *ClientAuth) receiveToken() (*Token, error) {
-	jsonBody, err := json.Marshal(ca.body)
+func (ca *ClientAuth) renewToken(ctx context.Context) (*Token, error) {
+	if ca.token != nil && ca.token.RefreshToken != "" {
+		token, err := ca.receiveToken(ctx, &AuthBody{
+			RefreshToken: ca.token.RefreshToken,
+			GrantType:    GrantTypeRefreshToken,
+		})
+
+		if err == nil {
+			return token, nil
+		}
+
+		// Only a rejected refresh token calls for the password grant: during
+		// an outage or a rate limit the credentials are not sent again, and
+		// the current token is used as long as it is valid.
+		if !isRejectedGrant(err) {
+			if ca.token.Valid() {
+				return ca.token, nil
+			}
+			return nil, err
+		}
+	}
+
+	return ca.receiveToken(ctx, ca.body)
+}
+
+// isRejectedGrant tells whether the token endpoint rejected the grant. Akeneo
+// answers an invalid or expired refresh token with a 422 and no OAuth2 error
+// code, so every client error status counts as a rejection.
+func isRejectedGrant(err error) bool {
+	apiErr, ok := AsApiError(err)
+	if !ok {
+		return false
+	}
+
+	switch apiErr.Code {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusUnprocessableEntity:
+		return true
+	}
+
+	return false
+}
+
+func (ca *ClientAuth) expiresSoon(token *Token) bool {
+	if !token.Valid() {
+		return true
+	}
+
+	if token.Expiry.IsZero() {
+		return false
+	}
+
+	margin := ca.refreshMargin
+	if lifetime := time.Duration(token.ExpiresIn) * time.Second / 2; lifetime < margin {
+		margin = lifetime
+	}
+
+	return time.Until(token.Expiry) < margin
+}
+
+func (ca *ClientAuth) receiveToken(ctx context.Context, body *AuthBody) (token *Token, err error) {
+	started := time.Now()
+	defer func() {
+		ca.observers.tokenRefresh(&TokenEvent{GrantType: body.GrantType, Duration: time.Since(started), Err: err})
+	}()
+
+	jsonBody, err := json.Marshal(body)
 
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", ca.authURL, bytes.NewBuffer(jsonBody))
+	ctx = withOperation(ctx, ResourceAuth, OperationToken)
+	req, err := http.NewRequestWithContext(ctx, "POST", ca.authURL, bytes.NewBuffer(jsonBody))
 
 	if err != nil {
 		return nil, err
@@ -187,7 +438,7 @@ func (ca *ClientAuth) receiveToken() (*Token, error) {
 
 	req.SetBasicAuth(ca.clientId, ca.secretKey)
 
-	resp, err := ca.client.Do(req)
+	resp, err := ca.transport(req)
 
 	if err != nil {
 		return nil, err
@@ -195,7 +446,11 @@ func (ca *ClientAuth) receiveToken() (*Token, error) {
 
 	defer resp.Body.Close()
 
-	token := &Token{}
+	if resp.StatusCode >= 300 {
+		return nil, newResponseError(resp)
+	}
+
+	token = &Token{}
 	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
 		return nil, err
 	}
@@ -203,4 +458,4 @@ func (ca *ClientAuth) receiveToken() (*Token, error) {
 	token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
 
 	return token, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,164 @@
+package akeneo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// tokenServer answers the token endpoint: the password grant always
+// succeeds, the refresh_token grant answers with refreshStatus and
+// refreshBody when they are set.
+type tokenServer struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	grants        []string
+	refreshTokens []string
+	refreshStatus int
+	refreshBody   string
+}
+
+func newTokenServer() *tokenServer {
+	ts := &tokenServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := &AuthBody{}
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		ts.mu.Lock()
+		ts.grants = append(ts.grants, body.GrantType)
+		ts.refreshTokens = append(ts.refreshTokens, body.RefreshToken)
+		count := len(ts.grants)
+		status, failure := ts.refreshStatus, ts.refreshBody
+		ts.mu.Unlock()
+
+		if body.GrantType == GrantTypeRefreshToken && status != 0 {
+			w.WriteHeader(status)
+			fmt.Fprint(w, failure)
+			return
+		}
+
+		fmt.Fprintf(w, `{"access_token":"access-%d","refresh_token":"refresh-%d","token_type":"bearer","expires_in":3600}`, count, count)
+	}))
+
+	return ts
+}
+
+func (ts *tokenServer) grantTypes() string {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	return strings.Join(ts.grants, ",")
+}
+
+// newTokenAuth returns the ClientAuth of a client whose store holds a token
+// expiring in expiresIn.
+func newTokenAuth(ts *tokenServer, expiresIn time.Duration) *ClientAuth {
+	store := NewMemoryTokenStore()
+	if expiresIn != 0 {
+		store.Save(&Token{
+			Token:     oauth2.Token{AccessToken: "current", RefreshToken: "current-refresh", TokenType: "bearer", Expiry: time.Now().Add(expiresIn)},
+			ExpiresIn: 3600,
+		})
+	}
+
+	client := NewClient(&ClientConfig{
+		BaseUrl:    ts.URL,
+		Username:   "admin",
+		Password:   "admin",
+		ClientId:   "client",
+		SecretKey:  "secret",
+		TokenStore: store,
+	})
+
+	return client.auth.(*ClientAuth)
+}
+
+func TestClientAuthRequestsTokenOnce(t *testing.T) {
+	ts := newTokenServer()
+	defer ts.Close()
+
+	auth := newTokenAuth(ts, 0)
+	for i := 0; i < 3; i++ {
+		token, err := auth.GetToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.AccessToken != "access-1" {
+			t.Fatalf("unexpected token %q", token.AccessToken)
+		}
+	}
+
+	if grants := ts.grantTypes(); grants != GrantTypePassword {
+		t.Errorf("expected a single password grant, got %s", grants)
+	}
+}
+
+func TestClientAuthRenewsWithRefreshToken(t *testing.T) {
+	ts := newTokenServer()
+	defer ts.Close()
+
+	auth := newTokenAuth(ts, 30*time.Second)
+	token, err := auth.GetToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "access-1" || ts.grantTypes() != GrantTypeRefreshToken || ts.refreshTokens[0] != "current-refresh" {
+		t.Errorf("expected a refresh_token grant, got %s giving %q", ts.grantTypes(), token.AccessToken)
+	}
+}
+
+func TestClientAuthDoesNotSendCredentialsOnOtherErrors(t *testing.T) {
+	failures := map[int]string{
+		http.StatusTooManyRequests:     `{"code":429,"message":"Too many requests"}`,
+		http.StatusServiceUnavailable:  `<html>maintenance</html>`,
+		http.StatusInternalServerError: `{"code":500,"message":"invalid_grant handling failed"}`,
+	}
+
+	for status, body := range failures {
+		ts := newTokenServer()
+		ts.refreshStatus = status
+		ts.refreshBody = body
+
+		// The current token is still valid: it keeps being used.
+		token, err := newTokenAuth(ts, 30*time.Second).GetToken()
+		if err != nil || token.AccessToken != "current" {
+			t.Errorf("status %d: expected the current token, got %v, %v", status, token, err)
+		}
+
+		// The current token has expired: the error is returned.
+		_, err = newTokenAuth(ts, -time.Second).GetToken()
+		if apiErr, ok := AsApiError(err); !ok || apiErr.Code != status {
+			t.Errorf("status %d: expected the token endpoint error, got %v", status, err)
+		}
+
+		if grants := ts.grantTypes(); strings.Contains(grants, GrantTypePassword) {
+			t.Errorf("status %d: credentials were sent again: %s", status, grants)
+		}
+		ts.Close()
+	}
+}
+
+func TestClientAuthDoesNotSendCredentialsOnNetworkErrors(t *testing.T) {
+	ts := newTokenServer()
+	auth := newTokenAuth(ts, -time.Second)
+	ts.Close()
+
+	if _, err := auth.GetToken(); err == nil {
+		t.Fatal("expected a network error")
+	}
+	if grants := ts.grantTypes(); grants != "" {
+		t.Errorf("expected no grant, got %s", grants)
+	}
+}
@@ -0,0 +1,179 @@
+package akeneo
+
+import (
+	"sort"
+)
+
+// Completeness is the completeness of a product for a channel and a locale,
+// shaped like the completenesses Akeneo returns: Data is the ratio of filled
+// required values, as a percentage rounded down.
+type Completeness struct {
+	Scope    string   `json:"scope"`
+	Locale   string   `json:"locale"`
+	Data     int      `json:"data"`
+	Required int      `json:"required"`
+	Missing  []string `json:"missing,omitempty"`
+}
+
+// CompletenessCalculator computes product completeness locally from the
+// family attribute requirements, the channels and the attribute definitions.
+// Product models added to it are used to resolve inherited values.
+type CompletenessCalculator struct {
+	attributes map[string]*Attribute
+	channels   map[string]*Channel
+	models     map[string]*ProductModel
+}
+
+func NewCompletenessCalculator(attributes []*Attribute, channels []*Channel) *CompletenessCalculator {
+	calculator := &CompletenessCalculator{
+		attributes: map[string]*Attribute{},
+		channels:   map[string]*Channel{},
+		models:     map[string]*ProductModel{},
+	}
+
+	for _, attribute := range attributes {
+		calculator.attributes[attribute.Code] = attribute
+	}
+	for _, channel := range channels {
+		calculator.channels[channel.Code] = channel
+	}
+
+	return calculator
+}
+
+func (c *CompletenessCalculator) AddProductModels(models ...*ProductModel) *CompletenessCalculator {
+	for _, model := range models {
+		c.models[model.Code] = model
+	}
+
+	return c
+}
+
+// Calculate returns the completeness of product for every channel of the
+// family requirements that is known to the calculator, ordered by channel
+// code and then by the locales of the channel.
+func (c *CompletenessCalculator) Calculate(product *Product, family *Family) []*Completeness {
+	return c.calculate(c.inherited(product), family)
+}
+
+// inherited returns the values of product followed by those of its product
+// model ancestors.
+func (c *CompletenessCalculator) inherited(product *Product) []ProductValues {
+	chain := []ProductValues{product.Values}
+	visited := map[string]bool{}
+
+	for parent := product.Parent; parent != "" && !visited[parent]; {
+		visited[parent] = true
+
+		model, known := c.models[parent]
+		if !known {
+			break
+		}
+
+		chain = append(chain, model.Values)
+		parent = model.Parent
+	}
+
+	return chain
+}
+
+func (c *CompletenessCalculator) calculate(chain []ProductValues, family *Family) []*Completeness {
+	var completenesses []*Completeness
+	if family == nil {
+		return completenesses
+	}
+
+	channelCodes := make([]string, 0, len(family.AttributeRequirements))
+	for channelCode := range family.AttributeRequirements {
+		if _, known := c.channels[channelCode]; known {
+			channelCodes = append(channelCodes, channelCode)
+		}
+	}
+	sort.Strings(channelCodes)
+
+	for _, channelCode := range channelCodes {
+		channel := c.channels[channelCode]
+
+		for _, locale := range channel.Locales {
+			completeness := &Completeness{Scope: channelCode, Locale: locale}
+
+			for _, code := range family.AttributeRequirements[channelCode] {
+				attribute, known := c.attributes[code]
+				if !known || !availableIn(attribute, locale) {
+					continue
+				}
+
+				completeness.Required++
+				if !c.filled(chain, attribute, channel, locale) {
+					completeness.Missing = append(completeness.Missing, code)
+				}
+			}
+
+			completeness.Data = 100
+			if completeness.Required > 0 {
+				completeness.Data = (completeness.Required - len(completeness.Missing)) * 100 / completeness.Required
+			}
+
+			completenesses = append(completenesses, completeness)
+		}
+	}
+
+	return completenesses
+}
+
+// filled tells whether the product has a value for attribute in the channel
+// and locale. The identifier is always filled and a price collection needs
+// a price in every currency of the channel.
+func (c *CompletenessCalculator) filled(chain []ProductValues, attribute *Attribute, channel *Channel, locale string) bool {
+	if attribute.Type_ == AkeneoTypeIdentifier {
+		return true
+	}
+
+	scope := ""
+	if attribute.Scopable {
+		scope = channel.Code
+	}
+	if !attribute.Localizable {
+		locale = ""
+	}
+
+	var value *ProductAttributeValue
+	for _, values := range chain {
+		if value = values.Value(attribute.Code, scope, locale); value != nil {
+			break
+		}
+	}
+
+	if value == nil || isEmptyData(value.Data) {
+		return false
+	}
+
+	switch attribute.Type_ {
+	case AkeneoTypeMetric:
+		metric := &MetricData{}
+		return decodeData(value.Data, metric) == nil && metric.Amount != "" && metric.Unit != ""
+	case AkeneoTypePriceCollection:
+		var prices []PriceData
+		if decodeData(value.Data, &prices) != nil {
+			return false
+		}
+
+		for _, currency := range channel.Currencies {
+			priced := false
+			for _, price := range prices {
+				priced = priced || (price.Currency == currency && price.Amount != "")
+			}
+			if !priced {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// availableIn tells whether a locale specific attribute can have a value in
+// locale.
+func availableIn(attribute *Attribute, locale string) bool {
+	return len(attribute.AvailableLocales) == 0 || containsString(attribute.AvailableLocales, locale)
+}
@@ -0,0 +1,92 @@
+package akeneo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestCompletenessCalculator() (*CompletenessCalculator, *Family) {
+	calculator := NewCompletenessCalculator(
+		[]*Attribute{
+			{Code: "sku", Type_: AkeneoTypeIdentifier},
+			{Code: "name", Type_: AkeneoTypeText, Localizable: true},
+			{Code: "description", Type_: AkeneoTypeTextArea, Localizable: true, Scopable: true},
+			{Code: "price", Type_: AkeneoTypePriceCollection, Scopable: true},
+			{Code: "weight", Type_: AkeneoTypeMetric},
+			{Code: "legal", Type_: AkeneoTypeText, Localizable: true, AvailableLocales: []string{"fr_FR"}},
+		},
+		[]*Channel{
+			{Code: "ecommerce", Locales: []string{"en_US", "fr_FR"}, Currencies: []string{"EUR", "USD"}},
+			{Code: "print", Locales: []string{"fr_FR"}, Currencies: []string{"EUR"}},
+		},
+	)
+
+	family := &Family{
+		Code: "shoes",
+		AttributeRequirements: map[string][]string{
+			"print":     {"sku", "name", "legal"},
+			"ecommerce": {"sku", "name", "description", "price", "weight", "legal"},
+			"unknown":   {"sku"},
+		},
+	}
+
+	return calculator, family
+}
+
+func TestCompletenessCalculate(t *testing.T) {
+	calculator, family := newTestCompletenessCalculator()
+
+	product := &Product{Identifier: "sku-1"}
+	product.Values.SetText("name", "", "en_US", "Shoe")
+	product.Values.SetText("name", "", "fr_FR", "Chaussure")
+	product.Values.SetText("description", "ecommerce", "en_US", "A shoe")
+	product.Values.SetPrices("price", "ecommerce", "", []PriceData{{Amount: "10", Currency: "EUR"}})
+	product.Values.SetMetric("weight", "", "", MetricData{Amount: "1", Unit: ""})
+	product.Values.SetText("legal", "", "fr_FR", "")
+
+	expected := []*Completeness{
+		{Scope: "ecommerce", Locale: "en_US", Data: 60, Required: 5, Missing: []string{"price", "weight"}},
+		{Scope: "ecommerce", Locale: "fr_FR", Data: 33, Required: 6, Missing: []string{"description", "price", "weight", "legal"}},
+		{Scope: "print", Locale: "fr_FR", Data: 66, Required: 3, Missing: []string{"legal"}},
+	}
+
+	completenesses := calculator.Calculate(product, family)
+	if !reflect.DeepEqual(completenesses, expected) {
+		for _, completeness := range completenesses {
+			t.Logf("%+v", *completeness)
+		}
+		t.Error("unexpected completenesses")
+	}
+}
+
+func TestCompletenessUsesInheritedValues(t *testing.T) {
+	calculator, family := newTestCompletenessCalculator()
+
+	root := &ProductModel{Code: "root"}
+	root.Values.SetText("name", "", "fr_FR", "Chaussure")
+	root.Values.SetText("legal", "", "fr_FR", "Mentions")
+	child := &ProductModel{Code: "child", Parent: "root"}
+	calculator.AddProductModels(root, child)
+
+	product := &Product{Identifier: "sku-1", Parent: "child"}
+
+	completenesses := calculator.Calculate(product, family)
+	printing := completenesses[len(completenesses)-1]
+	if printing.Scope != "print" || printing.Data != 100 || len(printing.Missing) != 0 {
+		t.Errorf("unexpected print completeness %+v", *printing)
+	}
+}
+
+func TestCompletenessWithoutFamily(t *testing.T) {
+	calculator, _ := newTestCompletenessCalculator()
+
+	if completenesses := calculator.Calculate(&Product{Identifier: "sku-1"}, nil); len(completenesses) != 0 {
+		t.Errorf("expected no completeness, got %v", completenesses)
+	}
+
+	family := &Family{Code: "empty", AttributeRequirements: map[string][]string{"print": {}}}
+	completenesses := calculator.Calculate(&Product{Identifier: "sku-1"}, family)
+	if len(completenesses) != 1 || completenesses[0].Data != 100 {
+		t.Errorf("expected a complete product without requirements, got %v", completenesses)
+	}
+}
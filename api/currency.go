@@ -1,10 +1,9 @@
 package akeneo
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
 type Currency struct {
@@ -26,56 +25,101 @@ type CurrencyResponse struct {
 
 type CurrencyApi ApiService
 
-func (service *CurrencyApi) GetAll(opts RequestOpts) (*CurrencyResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *CurrencyApi) GetAll(opts QueryOptions) (*CurrencyResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+func (service *CurrencyApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*CurrencyResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceCurrency, OperationGetAll)
+
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "currencies", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "currencies", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &CurrencyResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type CurrencyIterator struct {
+	*pager
+}
+
+func (service *CurrencyApi) Iterate(opts QueryOptions) *CurrencyIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *CurrencyApi) IterateWithContext(ctx context.Context, opts QueryOptions) *CurrencyIterator {
+	ctx = withOperation(ctx, ResourceCurrency, OperationIterate)
+
+	return &CurrencyIterator{pager: newPager(ctx, service.client, "currencies", opts, pageQueryKeys, func() collectionPage { return &CurrencyResponse{} })}
+}
+
+func (it *CurrencyIterator) Item() *CurrencyItem {
+	item, _ := it.item.(*CurrencyItem)
+
+	return item
+}
+
+// NewCurrencyIterator returns an iterator over items already in memory, e.g. for
+// a test double of CurrencyService.
+func NewCurrencyIterator(items []CurrencyItem) *CurrencyIterator {
+	page := &CurrencyResponse{}
+	page.Data.Items = items
+
+	return &CurrencyIterator{pager: newItemPager(page)}
+}
+
+func (page *CurrencyResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *CurrencyApi) Get(code string) (*Currency, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *CurrencyApi) GetWithContext(ctx context.Context, code string) (*Currency, *ApiError) {
+	ctx = withOperation(ctx, ResourceCurrency, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("currencies/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var successResponse = &Currency{}
 	if err = json.NewDecoder(response.Body).Decode(&successResponse); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return successResponse, nil
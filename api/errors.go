@@ -0,0 +1,218 @@
+package akeneo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrBadRequest    = errors.New("akeneo: bad request")
+	ErrUnauthorized  = errors.New("akeneo: unauthorized")
+	ErrForbidden     = errors.New("akeneo: forbidden")
+	ErrNotFound      = errors.New("akeneo: not found")
+	ErrUnprocessable = errors.New("akeneo: unprocessable entity")
+	ErrRateLimited   = errors.New("akeneo: too many requests")
+	ErrServer        = errors.New("akeneo: server error")
+)
+
+// ApiError is returned by every resource method. Code is zero when the
+// request never got a response, in which case Err holds the cause.
+//
+// Resource methods return the concrete *ApiError, which is nil on success.
+// Assigning that result to a variable of type error gives a non-nil error
+// even on success, so check it against nil first:
+//
+//	if apiErr := api.Product.Create(product); apiErr != nil {
+//		return apiErr
+//	}
+type ApiError struct {
+	Code          int
+	Status        string
+	Message       string
+	Errors        []*ValidationError
+	Documentation string
+	Body          string
+	Err           error
+}
+
+// ValidationError is one entry of the `errors` list Akeneo sends along with
+// a 422 response or a failed batch line.
+type ValidationError struct {
+	Property  string  `json:"property"`
+	Message   string  `json:"message"`
+	Attribute string  `json:"attribute,omitempty"`
+	Locale    *string `json:"locale,omitempty"`
+	Scope     *string `json:"scope,omitempty"`
+}
+
+type errorPayload struct {
+	Code    int                `json:"code"`
+	Message string             `json:"message"`
+	Errors  []*ValidationError `json:"errors"`
+	Links   ResponseBodyLinks  `json:"_links"`
+}
+
+func newApiError(err error) *ApiError {
+	if apiErr, ok := AsApiError(err); ok {
+		return apiErr
+	}
+
+	return &ApiError{Message: err.Error(), Err: err}
+}
+
+func newResponseError(response *http.Response) *ApiError {
+	body, _ := ioutil.ReadAll(response.Body)
+
+	return parseApiError(response.StatusCode, response.Status, body)
+}
+
+func parseApiError(code int, status string, body []byte) *ApiError {
+	apiErr := &ApiError{Code: code, Status: status, Message: string(body), Body: string(body)}
+
+	payload := &errorPayload{}
+	if err := json.Unmarshal(body, payload); err == nil {
+		if payload.Message != "" {
+			apiErr.Message = payload.Message
+		}
+		apiErr.Errors = payload.Errors
+		apiErr.Documentation = payload.Links.Documentation.Href
+	}
+
+	return apiErr
+}
+
+// Error describes the error. A nil *ApiError, as found in an error holding
+// the result of a successful call, reads "akeneo: <nil>" instead of panicking.
+func (e *ApiError) Error() string {
+	if e == nil {
+		return "akeneo: <nil>"
+	}
+
+	var b strings.Builder
+
+	b.WriteString("akeneo: ")
+	if e.Code != 0 {
+		b.WriteString(fmt.Sprintf("%d ", e.Code))
+	}
+	b.WriteString(e.Message)
+
+	for _, v := range e.Errors {
+		b.WriteString("; ")
+		b.WriteString(v.Error())
+	}
+
+	return b.String()
+}
+
+func (e *ApiError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+
+	return e.Err
+}
+
+// Is matches the sentinel error corresponding to the HTTP status, so that
+// errors.Is(err, ErrNotFound) works on wrapped API errors.
+func (e *ApiError) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+
+	switch {
+	case e.Code == http.StatusBadRequest:
+		return target == ErrBadRequest
+	case e.Code == http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case e.Code == http.StatusForbidden:
+		return target == ErrForbidden
+	case e.Code == http.StatusNotFound:
+		return target == ErrNotFound
+	case e.Code == http.StatusUnprocessableEntity:
+		return target == ErrUnprocessable
+	case e.Code == http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	case e.Code >= http.StatusInternalServerError:
+		return target == ErrServer
+	}
+
+	return false
+}
+
+func (v *ValidationError) Error() string {
+	var b strings.Builder
+
+	if v.Property != "" {
+		b.WriteString(v.Property)
+	}
+
+	if v.Attribute != "" {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString("[" + v.Attribute)
+		if v.Scope != nil {
+			b.WriteString(" scope=" + *v.Scope)
+		}
+		if v.Locale != nil {
+			b.WriteString(" locale=" + *v.Locale)
+		}
+		b.WriteString("]")
+	}
+
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	b.WriteString(v.Message)
+
+	return b.String()
+}
+
+// AsApiError extracts the *ApiError from err. A nil *ApiError stored in an
+// error is not an error: it returns false.
+func AsApiError(err error) (*ApiError, bool) {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) && apiErr != nil {
+		return apiErr, true
+	}
+
+	return nil, false
+}
+
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
+
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+func IsUnprocessable(err error) bool {
+	return hasStatus(err, http.StatusUnprocessableEntity)
+}
+
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+func IsServerError(err error) bool {
+	apiErr, ok := AsApiError(err)
+	return ok && apiErr.Code >= http.StatusInternalServerError
+}
+
+func hasStatus(err error, code int) bool {
+	apiErr, ok := AsApiError(err)
+	return ok && apiErr.Code == code
+}
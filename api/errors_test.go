@@ -0,0 +1,72 @@
+package akeneo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestParseApiErrorDecodesPayload(t *testing.T) {
+	body := `{"code":422,"message":"Validation failed.","errors":[{"property":"values","message":"This value should not be blank.","attribute":"name","locale":"en_US","scope":null}],"_links":{"documentation":{"href":"http://api.akeneo.com/api-reference.html"}}}`
+
+	apiErr := parseApiError(http.StatusUnprocessableEntity, "422 Unprocessable Entity", []byte(body))
+
+	if apiErr.Message != "Validation failed." || len(apiErr.Errors) != 1 || apiErr.Documentation == "" {
+		t.Fatalf("unexpected error %+v", apiErr)
+	}
+	if expected := "akeneo: 422 Validation failed.; values [name locale=en_US]: This value should not be blank."; apiErr.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, apiErr.Error())
+	}
+	if !errors.Is(apiErr, ErrUnprocessable) || !IsUnprocessable(fmt.Errorf("wrapped: %w", apiErr)) {
+		t.Error("expected the error to match ErrUnprocessable")
+	}
+}
+
+func TestParseApiErrorKeepsRawBody(t *testing.T) {
+	apiErr := parseApiError(http.StatusBadGateway, "502 Bad Gateway", []byte("<html>bad gateway</html>"))
+
+	if apiErr.Message != "<html>bad gateway</html>" || !IsServerError(apiErr) || !errors.Is(apiErr, ErrServer) {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+}
+
+func TestNilApiErrorInError(t *testing.T) {
+	var apiErr *ApiError
+	var err error = apiErr
+
+	if err.Error() != "akeneo: <nil>" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if errors.Unwrap(err) != nil || errors.Is(err, ErrNotFound) {
+		t.Error("a nil *ApiError should not wrap or match anything")
+	}
+	if _, ok := AsApiError(err); ok {
+		t.Error("AsApiError should return false for a nil *ApiError")
+	}
+}
+
+func TestApiErrorUnwrapsCause(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := newApiError(cause)
+
+	if !errors.Is(err, cause) || err.Code != 0 {
+		t.Errorf("unexpected error %+v", err)
+	}
+}
+
+func TestNewApiErrorKeepsApiErrors(t *testing.T) {
+	apiErr := parseApiError(http.StatusUnprocessableEntity, "422 Unprocessable Entity", []byte(`{"code":422,"message":"Invalid data"}`))
+
+	err := newApiError(apiErr)
+	if err != apiErr {
+		t.Fatalf("expected the same error, got %+v", err)
+	}
+	if err.Error() != "akeneo: 422 Invalid data" || !IsUnprocessable(err) {
+		t.Errorf("unexpected error %q", err.Error())
+	}
+
+	if err := newApiError(fmt.Errorf("decode: %w", apiErr)); err != apiErr {
+		t.Errorf("expected the wrapped error, got %+v", err)
+	}
+}
@@ -3,10 +3,9 @@ package akeneo
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
 type Family struct {
@@ -32,102 +31,162 @@ type FamiliesResponse struct {
 	} `json:"_embedded"`
 }
 
-func (service *FamilyApi) GetAll(opts RequestOpts) (*FamiliesResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *FamilyApi) GetAll(opts QueryOptions) (*FamiliesResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+func (service *FamilyApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*FamiliesResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceFamily, OperationGetAll)
+
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "families", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "families", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &FamiliesResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type FamilyIterator struct {
+	*pager
+}
+
+func (service *FamilyApi) Iterate(opts QueryOptions) *FamilyIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *FamilyApi) IterateWithContext(ctx context.Context, opts QueryOptions) *FamilyIterator {
+	ctx = withOperation(ctx, ResourceFamily, OperationIterate)
+
+	return &FamilyIterator{pager: newPager(ctx, service.client, "families", opts, pageQueryKeys, func() collectionPage { return &FamiliesResponse{} })}
+}
+
+func (it *FamilyIterator) Item() *FamilyItem {
+	item, _ := it.item.(*FamilyItem)
+
+	return item
+}
+
+// NewFamilyIterator returns an iterator over items already in memory, e.g. for
+// a test double of FamilyService.
+func NewFamilyIterator(items []FamilyItem) *FamilyIterator {
+	page := &FamiliesResponse{}
+	page.Data.Items = items
+
+	return &FamilyIterator{pager: newItemPager(page)}
+}
+
+func (page *FamiliesResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *FamilyApi) Get(code string) (*Family, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *FamilyApi) GetWithContext(ctx context.Context, code string) (*Family, *ApiError) {
+	ctx = withOperation(ctx, ResourceFamily, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("families/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var family = &Family{}
 	if err = json.NewDecoder(response.Body).Decode(&family); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return family, nil
 }
 
 func (service *FamilyApi) Create(family *Family) *ApiError {
+	return service.CreateWithContext(context.Background(), family)
+}
+
+func (service *FamilyApi) CreateWithContext(ctx context.Context, family *Family) *ApiError {
+	ctx = withOperation(ctx, ResourceFamily, OperationCreate)
+
 	headers := service.client.getHeadersForRequest()
 	body, _ := json.Marshal(family)
 
-	response, err := service.client.DoRequest("POST", "families", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", "families", headers, body, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *FamilyApi) Upsert(family *Family) *ApiError {
+	return service.UpsertWithContext(context.Background(), family)
+}
+
+func (service *FamilyApi) UpsertWithContext(ctx context.Context, family *Family) *ApiError {
+	ctx = withOperation(ctx, ResourceFamily, OperationUpsert)
 
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("families/%s", family.Code)
 	body, _ := json.Marshal(family)
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Code: 0, Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *FamilyApi) BatchUpsert(families []*Family) ([]*ResponseBody, *ApiError) {
+	return service.BatchUpsertWithContext(context.Background(), families)
+}
+
+func (service *FamilyApi) BatchUpsertWithContext(ctx context.Context, families []*Family) ([]*ResponseBody, *ApiError) {
+	ctx = withOperation(ctx, ResourceFamily, OperationBatchUpsert)
+
 	headers := service.client.getHeadersForBatchRequest()
 	var body []byte
 
@@ -137,16 +196,15 @@ func (service *FamilyApi) BatchUpsert(families []*Family) ([]*ResponseBody, *Api
 		body = append(body, '\n')
 	}
 
-	response, err := service.client.DoRequest("PATCH", "families", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", "families", headers, body, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var apiResponse []*ResponseBody
@@ -156,11 +214,17 @@ func (service *FamilyApi) BatchUpsert(families []*Family) ([]*ResponseBody, *Api
 		var responseLine *ResponseBody
 		var reader = bytes.NewReader(scanner.Bytes())
 		if err = json.NewDecoder(reader).Decode(&responseLine); err != nil {
-			return nil, &ApiError{Message: err.Error()}
+			return nil, newApiError(err)
 		}
 
 		apiResponse = append(apiResponse, responseLine)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, newApiError(err)
+	}
+
+	service.client.observers.batchLines(ctx, apiResponse)
+
 	return apiResponse, nil
 }
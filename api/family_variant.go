@@ -3,10 +3,9 @@ package akeneo
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
 type FamilyVariant struct {
@@ -35,77 +34,127 @@ type FamilyVariantsResponse struct {
 	} `json:"_embedded"`
 }
 
-func (service *FamilyVariantApi) GetAll(code string, opts RequestOpts) (*FamilyVariantsResponse, *ApiError) {
+func (service *FamilyVariantApi) GetAll(code string, opts QueryOptions) (*FamilyVariantsResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), code, opts)
+}
+
+func (service *FamilyVariantApi) GetAllWithContext(ctx context.Context, code string, opts QueryOptions) (*FamilyVariantsResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceFamilyVariant, OperationGetAll)
+
 	uri := fmt.Sprintf("families/%s/variants", code)
 	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
-
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &FamilyVariantsResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type FamilyVariantIterator struct {
+	*pager
+}
+
+func (service *FamilyVariantApi) Iterate(code string, opts QueryOptions) *FamilyVariantIterator {
+	return service.IterateWithContext(context.Background(), code, opts)
+}
+
+func (service *FamilyVariantApi) IterateWithContext(ctx context.Context, code string, opts QueryOptions) *FamilyVariantIterator {
+	ctx = withOperation(ctx, ResourceFamilyVariant, OperationIterate)
+
+	return &FamilyVariantIterator{pager: newPager(ctx, service.client, fmt.Sprintf("families/%s/variants", code), opts, pageQueryKeys, func() collectionPage { return &FamilyVariantsResponse{} })}
+}
+
+func (it *FamilyVariantIterator) Item() *FamilyVariant {
+	item, _ := it.item.(*FamilyVariant)
+
+	return item
+}
+
+// NewFamilyVariantIterator returns an iterator over items already in memory, e.g. for
+// a test double of FamilyVariantService.
+func NewFamilyVariantIterator(items []FamilyVariant) *FamilyVariantIterator {
+	page := &FamilyVariantsResponse{}
+	page.Data.Items = items
+
+	return &FamilyVariantIterator{pager: newItemPager(page)}
+}
+
+func (page *FamilyVariantsResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *FamilyVariantApi) Get(familyCode string, variantCode string) (*FamilyVariant, *ApiError) {
+	return service.GetWithContext(context.Background(), familyCode, variantCode)
+}
+
+func (service *FamilyVariantApi) GetWithContext(ctx context.Context, familyCode string, variantCode string) (*FamilyVariant, *ApiError) {
+	ctx = withOperation(ctx, ResourceFamilyVariant, OperationGet)
+
 	uri := fmt.Sprintf("families/%s/variants/%s", familyCode, variantCode)
 	headers := service.client.getHeadersForRequest()
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var family = &FamilyVariant{}
 	if err = json.NewDecoder(response.Body).Decode(&family); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return family, nil
 }
 
 func (service *FamilyVariantApi) Create(familyCode string, variant *FamilyVariant) *ApiError {
+	return service.CreateWithContext(context.Background(), familyCode, variant)
+}
+
+func (service *FamilyVariantApi) CreateWithContext(ctx context.Context, familyCode string, variant *FamilyVariant) *ApiError {
+	ctx = withOperation(ctx, ResourceFamilyVariant, OperationCreate)
+
 	uri := fmt.Sprintf("families/%s/variants", familyCode)
 	headers := service.client.getHeadersForRequest()
 	body, _ := json.Marshal(variant)
 
-	response, err := service.client.DoRequest("POST", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
@@ -113,27 +162,37 @@ func (service *FamilyVariantApi) Create(familyCode string, variant *FamilyVarian
 
 
 func (service *FamilyVariantApi) Upsert(familyCode string, variant *FamilyVariant) *ApiError {
+	return service.UpsertWithContext(context.Background(), familyCode, variant)
+}
+
+func (service *FamilyVariantApi) UpsertWithContext(ctx context.Context, familyCode string, variant *FamilyVariant) *ApiError {
+	ctx = withOperation(ctx, ResourceFamilyVariant, OperationUpsert)
 
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("families/%s/variants/%s", familyCode, variant.Code)
 	body, _ := json.Marshal(variant)
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Code: 0, Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *FamilyVariantApi) BatchUpsert(code string, variants []*FamilyVariant) ([]*ResponseBody, *ApiError) {
+	return service.BatchUpsertWithContext(context.Background(), code, variants)
+}
+
+func (service *FamilyVariantApi) BatchUpsertWithContext(ctx context.Context, code string, variants []*FamilyVariant) ([]*ResponseBody, *ApiError) {
+	ctx = withOperation(ctx, ResourceFamilyVariant, OperationBatchUpsert)
+
 	headers := service.client.getHeadersForBatchRequest()
 	uri := fmt.Sprintf("families/%s/variants", code)
 
@@ -145,16 +204,15 @@ func (service *FamilyVariantApi) BatchUpsert(code string, variants []*FamilyVari
 		body = append(body, '\n')
 	}
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var apiResponse []*ResponseBody
@@ -164,11 +222,17 @@ func (service *FamilyVariantApi) BatchUpsert(code string, variants []*FamilyVari
 		var responseLine *ResponseBody
 		var reader = bytes.NewReader(scanner.Bytes())
 		if err = json.NewDecoder(reader).Decode(&responseLine); err != nil {
-			return nil, &ApiError{Message: err.Error()}
+			return nil, newApiError(err)
 		}
 
 		apiResponse = append(apiResponse, responseLine)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, newApiError(err)
+	}
+
+	service.client.observers.batchLines(ctx, apiResponse)
+
 	return apiResponse, nil
 }
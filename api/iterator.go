@@ -0,0 +1,129 @@
+package akeneo
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// collectionPage is a decoded page of a HAL collection. Each resource
+// response implements items to hand its items to the pager.
+type collectionPage interface {
+	links() *Response
+	items() []interface{}
+}
+
+func (r *Response) links() *Response {
+	return r
+}
+
+// pager walks a HAL collection by following the `_links.next` link of each
+// page until the last one is reached. Typed iterators embed it and only
+// convert the current item back to their item type.
+type pager struct {
+	ctx     context.Context
+	client  *Client
+	next    string
+	stopped bool
+	err     *ApiError
+	newPage func() collectionPage
+	items   []interface{}
+	item    interface{}
+}
+
+func newPager(ctx context.Context, client *Client, uri string, opts QueryOptions, keys []string, newPage func() collectionPage) *pager {
+	p := &pager{ctx: ctx, client: client, newPage: newPage}
+
+	queryParams, err := encodeQuery(opts, keys)
+	if err != nil {
+		p.err = newApiError(err)
+		return p
+	}
+
+	next, err := client.buildRequestUrl(uri, queryParams)
+	if err != nil {
+		p.err = newApiError(err)
+	}
+
+	p.next = next
+
+	return p
+}
+
+// newItemPager returns a pager over the items of a page already in memory.
+func newItemPager(page collectionPage) *pager {
+	return &pager{items: page.items()}
+}
+
+// Next advances to the next item, fetching the next page when the current
+// one is exhausted. It returns false at the end or on error.
+func (p *pager) Next() bool {
+	if p.stopped {
+		p.item = nil
+		return false
+	}
+
+	for len(p.items) == 0 {
+		page, ok := p.fetch()
+		if !ok {
+			p.item = nil
+			return false
+		}
+
+		p.items = page.items()
+	}
+
+	p.item = p.items[0]
+	p.items = p.items[1:]
+
+	return true
+}
+
+func (p *pager) fetch() (collectionPage, bool) {
+	if p.stopped || p.err != nil || p.next == "" || p.newPage == nil {
+		return nil, false
+	}
+
+	if err := p.ctx.Err(); err != nil {
+		p.err = newApiError(err)
+		return nil, false
+	}
+
+	headers := p.client.getHeadersForRequest()
+
+	response, err := p.client.doRequestUrl(p.ctx, "GET", p.next, headers, nil)
+	if err != nil {
+		p.err = newApiError(err)
+		return nil, false
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		p.err = newResponseError(response)
+		return nil, false
+	}
+
+	page := p.newPage()
+	if err = json.NewDecoder(response.Body).Decode(page); err != nil {
+		p.err = newApiError(err)
+		return nil, false
+	}
+
+	if next := page.links().Links.Next.Href; next == p.next {
+		p.next = ""
+	} else {
+		p.next = next
+	}
+
+	return page, true
+}
+
+// Stop ends the iteration early; Next returns false afterwards.
+func (p *pager) Stop() {
+	p.stopped = true
+}
+
+// Err returns the error that ended the iteration, if any.
+func (p *pager) Err() *ApiError {
+	return p.err
+}
@@ -0,0 +1,131 @@
+package akeneo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newPagedServer serves the given product identifiers two by two, with
+// search_after pagination.
+func newPagedServer(t *testing.T, identifiers []string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("pagination_type") != PaginationTypeSearchAfter || query.Get("limit") != "2" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+
+		start := 0
+		if after := query.Get("search_after"); after != "" {
+			for i, identifier := range identifiers {
+				if identifier == after {
+					start = i + 1
+				}
+			}
+		}
+
+		end := start + 2
+		if end > len(identifiers) {
+			end = len(identifiers)
+		}
+
+		var items []string
+		for _, identifier := range identifiers[start:end] {
+			items = append(items, fmt.Sprintf(`{"identifier":%q,"enabled":true}`, identifier))
+		}
+
+		self := server.URL + r.URL.String()
+		next := ""
+		if end < len(identifiers) {
+			next = fmt.Sprintf(`,"next":{"href":"%s/api/rest/v1/products?pagination_type=search_after&limit=2&search_after=%s"}`, server.URL, identifiers[end-1])
+		}
+
+		fmt.Fprintf(w, `{"_links":{"self":{"href":%q}%s},"_embedded":{"items":[%s]}}`, self, next, strings.Join(items, ","))
+	}))
+
+	return server
+}
+
+func TestProductIteratorFollowsSearchAfter(t *testing.T) {
+	server := newPagedServer(t, []string{"a", "b", "c", "d", "e"})
+	defer server.Close()
+
+	api := NewAkeneoApi(newTestClient(server, nil))
+	it := api.Product.Iterate(ProductOptions{PaginationType: PaginationTypeSearchAfter, Limit: 2})
+
+	var identifiers []string
+	for it.Next() {
+		identifiers = append(identifiers, it.Item().Identifier)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(identifiers, ",") != "a,b,c,d,e" {
+		t.Errorf("unexpected identifiers %v", identifiers)
+	}
+	if it.Item() != nil {
+		t.Error("Item should be nil once the iteration is over")
+	}
+}
+
+func TestIteratorStop(t *testing.T) {
+	server := newPagedServer(t, []string{"a", "b", "c", "d"})
+	defer server.Close()
+
+	api := NewAkeneoApi(newTestClient(server, nil))
+	it := api.Product.Iterate(ProductOptions{PaginationType: PaginationTypeSearchAfter, Limit: 2})
+
+	if !it.Next() || it.Item().Identifier != "a" {
+		t.Fatal("expected a first item")
+	}
+
+	it.Stop()
+	if it.Next() {
+		t.Error("Next should return false after Stop")
+	}
+}
+
+func TestIteratorReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code":404,"message":"Resource not found."}`)
+	}))
+	defer server.Close()
+
+	api := NewAkeneoApi(newTestClient(server, nil))
+	it := api.Category.Iterate(PageOptions{})
+
+	if it.Next() {
+		t.Fatal("expected no item")
+	}
+	if err := it.Err(); err == nil || err.Code != http.StatusNotFound {
+		t.Errorf("expected a 404 error, got %v", err)
+	}
+}
+
+func TestIteratorRejectsInvalidOptions(t *testing.T) {
+	api := NewAkeneoApi(NewClient(&ClientConfig{BaseUrl: "http://localhost", Authenticator: NewStaticTokenAuth("token")}))
+	it := api.Category.IterateWithContext(context.Background(), RequestOpts{"search_after": "x"})
+
+	if it.Next() || it.Err() == nil {
+		t.Error("expected an error for an unsupported option")
+	}
+}
+
+func TestIteratorOverItemsInMemory(t *testing.T) {
+	it := NewCategoryIterator([]CategoryItem{{Category: Category{Code: "master"}}, {Category: Category{Code: "shoes"}}})
+
+	var codes []string
+	for it.Next() {
+		codes = append(codes, it.Item().Code)
+	}
+
+	if strings.Join(codes, ",") != "master,shoes" || it.Err() != nil {
+		t.Errorf("unexpected codes %v", codes)
+	}
+}
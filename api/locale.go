@@ -1,10 +1,9 @@
 package akeneo
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
 type Locale struct {
@@ -27,56 +26,101 @@ type LocaleResponse struct {
 type LocaleApi ApiService
 
 
-func (service *LocaleApi) GetAll(opts RequestOpts) (*LocaleResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *LocaleApi) GetAll(opts QueryOptions) (*LocaleResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+func (service *LocaleApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*LocaleResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceLocale, OperationGetAll)
+
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "locales", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "locales", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &LocaleResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type LocaleIterator struct {
+	*pager
+}
+
+func (service *LocaleApi) Iterate(opts QueryOptions) *LocaleIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *LocaleApi) IterateWithContext(ctx context.Context, opts QueryOptions) *LocaleIterator {
+	ctx = withOperation(ctx, ResourceLocale, OperationIterate)
+
+	return &LocaleIterator{pager: newPager(ctx, service.client, "locales", opts, pageQueryKeys, func() collectionPage { return &LocaleResponse{} })}
+}
+
+func (it *LocaleIterator) Item() *LocaleItem {
+	item, _ := it.item.(*LocaleItem)
+
+	return item
+}
+
+// NewLocaleIterator returns an iterator over items already in memory, e.g. for
+// a test double of LocaleService.
+func NewLocaleIterator(items []LocaleItem) *LocaleIterator {
+	page := &LocaleResponse{}
+	page.Data.Items = items
+
+	return &LocaleIterator{pager: newItemPager(page)}
+}
+
+func (page *LocaleResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *LocaleApi) Get(code string) (*Locale, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *LocaleApi) GetWithContext(ctx context.Context, code string) (*Locale, *ApiError) {
+	ctx = withOperation(ctx, ResourceLocale, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("locales/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var successResponse = &Locale{}
 	if err = json.NewDecoder(response.Body).Decode(&successResponse); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return successResponse, nil
@@ -1,10 +1,11 @@
 package akeneo
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
+	"sort"
 )
 
 type MeasureFamily struct {
@@ -17,6 +18,145 @@ type FamilyUnit struct {
 	Code    string            `json:"code"`
 	Symbol  string            `json:"symbol"`
 	Convert map[string]string `json:"convert"`
+
+	// Operations are the operations of Convert in the order Akeneo applies
+	// them, which the map loses. They are filled when decoding and, when
+	// building a unit by hand, UnitConverter requires them as soon as
+	// Convert holds more than one operation.
+	Operations []ConversionOperation `json:"-"`
+}
+
+// ConversionOperation is a step of the conversion of a unit to the standard
+// unit of its family: Operator is one of add, sub, mul and div.
+type ConversionOperation struct {
+	Operator string
+	Value    string
+}
+
+// UnmarshalJSON reads the convert object keeping the order of its
+// operations. A list of single operation objects is accepted too.
+func (unit *FamilyUnit) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Code    string          `json:"code"`
+		Symbol  string          `json:"symbol"`
+		Convert json.RawMessage `json:"convert"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	unit.Code = raw.Code
+	unit.Symbol = raw.Symbol
+	unit.Convert = nil
+	unit.Operations = nil
+
+	convert := bytes.TrimSpace(raw.Convert)
+	if len(convert) == 0 || bytes.Equal(convert, []byte("null")) {
+		return nil
+	}
+
+	if convert[0] == '[' {
+		var steps []json.RawMessage
+		if err := json.Unmarshal(convert, &steps); err != nil {
+			return err
+		}
+
+		for _, step := range steps {
+			if err := unit.addOperations(step); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return unit.addOperations(convert)
+}
+
+// MarshalJSON writes the convert object in the order of Operations, so that
+// it decodes to the same unit. Operations repeating an operator are written
+// as a list of single operation objects. Without Operations, the operations
+// of Convert are written sorted by operator.
+func (unit FamilyUnit) MarshalJSON() ([]byte, error) {
+	operations := unit.operations()
+
+	pairs := make([][]byte, 0, len(operations))
+	seen := map[string]bool{}
+	repeated := false
+
+	for _, operation := range operations {
+		operator, _ := json.Marshal(operation.Operator)
+		value, _ := json.Marshal(operation.Value)
+		pairs = append(pairs, append(append(operator, ':'), value...))
+
+		repeated = repeated || seen[operation.Operator]
+		seen[operation.Operator] = true
+	}
+
+	convert := append(append([]byte("{"), bytes.Join(pairs, []byte(","))...), '}')
+	if repeated {
+		convert = append(append([]byte("[{"), bytes.Join(pairs, []byte("},{"))...), "}]"...)
+	}
+	if len(operations) == 0 && unit.Convert == nil {
+		convert = []byte("null")
+	}
+
+	return json.Marshal(struct {
+		Code    string          `json:"code"`
+		Symbol  string          `json:"symbol"`
+		Convert json.RawMessage `json:"convert"`
+	}{unit.Code, unit.Symbol, convert})
+}
+
+// operations returns Operations, or else the operations of Convert sorted by
+// operator.
+func (unit *FamilyUnit) operations() []ConversionOperation {
+	if len(unit.Operations) > 0 {
+		return unit.Operations
+	}
+
+	operators := make([]string, 0, len(unit.Convert))
+	for operator := range unit.Convert {
+		operators = append(operators, operator)
+	}
+	sort.Strings(operators)
+
+	operations := make([]ConversionOperation, len(operators))
+	for i, operator := range operators {
+		operations[i] = ConversionOperation{Operator: operator, Value: unit.Convert[operator]}
+	}
+
+	return operations
+}
+
+func (unit *FamilyUnit) addOperations(object []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(object))
+	decoder.UseNumber()
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	if unit.Convert == nil {
+		unit.Convert = map[string]string{}
+	}
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+
+		operator, operand := fmt.Sprint(key), fmt.Sprint(value)
+		unit.Convert[operator] = operand
+		unit.Operations = append(unit.Operations, ConversionOperation{Operator: operator, Value: operand})
+	}
+
+	return nil
 }
 
 type MeasureFamilyItem struct {
@@ -33,56 +173,101 @@ type MeasureFamilyResponse struct {
 
 type MeasureFamilyApi ApiService
 
-func (service *MeasureFamilyApi) GetAll(opts RequestOpts) (*MeasureFamilyResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *MeasureFamilyApi) GetAll(opts QueryOptions) (*MeasureFamilyResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	for _, key := range []string{"page", "limit", "withCount"} {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+func (service *MeasureFamilyApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*MeasureFamilyResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceMeasureFamily, OperationGetAll)
+
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, pageQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "measure-families", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "measure-families", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &MeasureFamilyResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type MeasureFamilyIterator struct {
+	*pager
+}
+
+func (service *MeasureFamilyApi) Iterate(opts QueryOptions) *MeasureFamilyIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *MeasureFamilyApi) IterateWithContext(ctx context.Context, opts QueryOptions) *MeasureFamilyIterator {
+	ctx = withOperation(ctx, ResourceMeasureFamily, OperationIterate)
+
+	return &MeasureFamilyIterator{pager: newPager(ctx, service.client, "measure-families", opts, pageQueryKeys, func() collectionPage { return &MeasureFamilyResponse{} })}
+}
+
+func (it *MeasureFamilyIterator) Item() *MeasureFamilyItem {
+	item, _ := it.item.(*MeasureFamilyItem)
+
+	return item
+}
+
+// NewMeasureFamilyIterator returns an iterator over items already in memory, e.g. for
+// a test double of MeasureFamilyService.
+func NewMeasureFamilyIterator(items []MeasureFamilyItem) *MeasureFamilyIterator {
+	page := &MeasureFamilyResponse{}
+	page.Data.Items = items
+
+	return &MeasureFamilyIterator{pager: newItemPager(page)}
+}
+
+func (page *MeasureFamilyResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *MeasureFamilyApi) Get(code string) (*MeasureFamily, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *MeasureFamilyApi) GetWithContext(ctx context.Context, code string) (*MeasureFamily, *ApiError) {
+	ctx = withOperation(ctx, ResourceMeasureFamily, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("measure-families/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var successResponse = &MeasureFamily{}
 	if err = json.NewDecoder(response.Body).Decode(&successResponse); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return successResponse, nil
@@ -0,0 +1,193 @@
+package akeneo
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var DefaultDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// MetricsCollector is an Observer that aggregates counters and request
+// durations and serves them in the Prometheus text exposition format.
+type MetricsCollector struct {
+	NopObserver
+	mu            sync.Mutex
+	buckets       []float64
+	inFlight      int64
+	requests      map[string]float64
+	durations     map[string]*histogram
+	tokens        map[string]float64
+	retries       map[string]float64
+	batchFailures map[string]float64
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func NewMetricsCollector() *MetricsCollector {
+	return &MetricsCollector{
+		buckets:       DefaultDurationBuckets,
+		requests:      map[string]float64{},
+		durations:     map[string]*histogram{},
+		tokens:        map[string]float64{},
+		retries:       map[string]float64{},
+		batchFailures: map[string]float64{},
+	}
+}
+
+func (mc *MetricsCollector) OnRequestStart(event *RequestEvent) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.inFlight++
+}
+
+func (mc *MetricsCollector) OnRequestEnd(event *RequestEvent) {
+	status := "error"
+	if event.Err == nil {
+		status = strconv.Itoa(event.StatusCode)
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.inFlight--
+	mc.requests[metricLabels("resource", event.Resource, "operation", event.Operation, "method", event.Method, "status", status)]++
+
+	key := metricLabels("resource", event.Resource, "operation", event.Operation)
+	h, ok := mc.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(mc.buckets))}
+		mc.durations[key] = h
+	}
+
+	seconds := event.Duration.Seconds()
+	for i, bound := range mc.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (mc *MetricsCollector) OnTokenRefresh(event *TokenEvent) {
+	result := "success"
+	if event.Err != nil {
+		result = "error"
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.tokens[metricLabels("grant_type", event.GrantType, "result", result)]++
+}
+
+func (mc *MetricsCollector) OnRetry(event *RetryEvent) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.retries[metricLabels("resource", event.Resource, "operation", event.Operation)]++
+}
+
+func (mc *MetricsCollector) OnBatchLineFailure(event *BatchLineEvent) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.batchFailures[metricLabels("resource", event.Resource, "status", strconv.Itoa(int(event.Line.StatusCode)))]++
+}
+
+func (mc *MetricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = mc.WriteMetrics(w)
+}
+
+// WriteMetrics writes every metric in the Prometheus text exposition format.
+func (mc *MetricsCollector) WriteMetrics(w io.Writer) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	var b strings.Builder
+
+	writeHeader(&b, "akeneo_requests_in_flight", "gauge", "Requests sent to Akeneo and not answered yet.")
+	fmt.Fprintf(&b, "akeneo_requests_in_flight %d\n", mc.inFlight)
+
+	writeCounter(&b, "akeneo_requests_total", "HTTP requests sent to Akeneo.", mc.requests)
+
+	writeHeader(&b, "akeneo_request_duration_seconds", "histogram", "Duration of HTTP requests sent to Akeneo.")
+	for _, key := range sortedKeys(mc.durations) {
+		h := mc.durations[key]
+		for i, bound := range mc.buckets {
+			fmt.Fprintf(&b, "akeneo_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", key, formatFloat(bound), h.counts[i])
+		}
+		fmt.Fprintf(&b, "akeneo_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", key, h.count)
+		fmt.Fprintf(&b, "akeneo_request_duration_seconds_sum{%s} %s\n", key, formatFloat(h.sum))
+		fmt.Fprintf(&b, "akeneo_request_duration_seconds_count{%s} %d\n", key, h.count)
+	}
+
+	writeCounter(&b, "akeneo_token_refresh_total", "OAuth token requests.", mc.tokens)
+	writeCounter(&b, "akeneo_retries_total", "Requests replayed by the retry policy.", mc.retries)
+	writeCounter(&b, "akeneo_batch_line_failures_total", "Batch upsert lines rejected by Akeneo.", mc.batchFailures)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func writeHeader(b *strings.Builder, name string, kind string, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeCounter(b *strings.Builder, name string, help string, values map[string]float64) {
+	writeHeader(b, name, "counter", help)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s{%s} %s\n", name, key, formatFloat(values[key]))
+	}
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+
+	switch values := m.(type) {
+	case map[string]float64:
+		for key := range values {
+			keys = append(keys, key)
+		}
+	case map[string]*histogram:
+		for key := range values {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func metricLabels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", pairs[i], escapeLabel(pairs[i+1])))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func escapeLabel(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "\n", `\n`, -1)
+
+	return strings.Replace(value, `"`, `\"`, -1)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,68 @@
+package akeneo
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	ResourceAuth            = "auth"
+	ResourceAssociationType = "association_type"
+	ResourceAttribute       = "attribute"
+	ResourceAttributeGroup  = "attribute_group"
+	ResourceAttributeOption = "attribute_option"
+	ResourceCategory        = "category"
+	ResourceChannel         = "channel"
+	ResourceCurrency        = "currency"
+	ResourceFamily          = "family"
+	ResourceFamilyVariant   = "family_variant"
+	ResourceLocale          = "locale"
+	ResourceMeasureFamily   = "measure_family"
+	ResourceMediaFile       = "media_file"
+	ResourceProduct         = "product"
+	ResourceProductModel    = "product_model"
+
+	OperationGetAll      = "get_all"
+	OperationIterate     = "iterate"
+	OperationGet         = "get"
+	OperationCreate      = "create"
+	OperationUpsert      = "upsert"
+	OperationBatchUpsert = "batch_upsert"
+	OperationDelete      = "delete"
+	OperationDownload    = "download"
+	OperationToken       = "token"
+)
+
+// Operation describes the client call an HTTP request belongs to.
+type Operation struct {
+	Resource string
+	Name     string
+}
+
+type RoundTripFunc func(request *http.Request) (*http.Response, error)
+
+// Middleware wraps the function sending a request. Middlewares passed in
+// ClientConfig run in order, the first one being the outermost, around every
+// API call and every token request of the default ClientAuth or of an AppAuth.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+type operationKey struct{}
+
+func withOperation(ctx context.Context, resource string, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, &Operation{Resource: resource, Name: name})
+}
+
+// OperationFromContext returns the operation attached to a request context,
+// typically request.Context() inside a Middleware.
+func OperationFromContext(ctx context.Context) (*Operation, bool) {
+	op, ok := ctx.Value(operationKey{}).(*Operation)
+	return op, ok
+}
+
+func chainMiddlewares(transport RoundTripFunc, middlewares []Middleware) RoundTripFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+
+	return transport
+}
@@ -0,0 +1,99 @@
+package akeneo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// traceMiddleware records when the request enters and leaves the middleware,
+// along with the operation read from the request context.
+func traceMiddleware(name string, mu *sync.Mutex, trace *[]string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			op, ok := OperationFromContext(request.Context())
+			if !ok {
+				op = &Operation{Name: "none"}
+			}
+
+			mu.Lock()
+			*trace = append(*trace, fmt.Sprintf("%s>%s.%s", name, op.Resource, op.Name))
+			mu.Unlock()
+
+			request.Header.Add("X-Middleware", name)
+			response, err := next(request)
+
+			mu.Lock()
+			*trace = append(*trace, "<"+name)
+			mu.Unlock()
+
+			return response, err
+		}
+	}
+}
+
+func TestMiddlewaresWrapTokenAndApiRequests(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.URL.Path+" "+strings.Join(r.Header.Values("X-Middleware"), ","))
+
+		switch r.URL.Path {
+		case "/api/oauth/v1/token":
+			fmt.Fprint(w, `{"access_token":"access","refresh_token":"refresh","token_type":"bearer","expires_in":3600}`)
+		case "/api/rest/v1/locales/en_US":
+			fmt.Fprint(w, `{"code":"en_US","enabled":true}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var trace []string
+	api := NewAkeneoApi(NewClient(&ClientConfig{
+		BaseUrl:   server.URL,
+		ClientId:  "client",
+		SecretKey: "secret",
+		Username:  "admin",
+		Password:  "admin",
+		Middlewares: []Middleware{
+			traceMiddleware("outer", &mu, &trace),
+			traceMiddleware("inner", &mu, &trace),
+		},
+	}))
+
+	if _, err := api.Locale.Get("en_US"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "outer>auth.token inner>auth.token <inner <outer outer>locale.get inner>locale.get <inner <outer"
+	if joined := strings.Join(trace, " "); joined != expected {
+		t.Errorf("unexpected trace\n got: %s\nwant: %s", joined, expected)
+	}
+
+	expected = "/api/oauth/v1/token outer,inner|/api/rest/v1/locales/en_US outer,inner"
+	if joined := strings.Join(seen, "|"); joined != expected {
+		t.Errorf("unexpected requests\n got: %s\nwant: %s", joined, expected)
+	}
+}
+
+func TestOperationFromContext(t *testing.T) {
+	if _, ok := OperationFromContext(context.Background()); ok {
+		t.Error("expected no operation on a bare context")
+	}
+
+	ctx := withOperation(context.Background(), ResourceProduct, OperationBatchUpsert)
+	op, ok := OperationFromContext(ctx)
+	if !ok || op.Resource != ResourceProduct || op.Name != OperationBatchUpsert {
+		t.Errorf("unexpected operation %+v", op)
+	}
+
+	request, _ := http.NewRequestWithContext(ctx, http.MethodPatch, "http://localhost/api/rest/v1/products", nil)
+	if op, _ := OperationFromContext(request.Context()); op == nil || op.Name != OperationBatchUpsert {
+		t.Errorf("the request context lost the operation: %+v", op)
+	}
+}
@@ -0,0 +1,152 @@
+package akeneo
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type RequestEvent struct {
+	Resource   string
+	Operation  string
+	Method     string
+	Url        string
+	Attempt    int
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+type TokenEvent struct {
+	GrantType string
+	Duration  time.Duration
+	Err       error
+}
+
+type RetryEvent struct {
+	Resource   string
+	Operation  string
+	Method     string
+	Attempt    int
+	StatusCode int
+	Delay      time.Duration
+	Err        error
+}
+
+type BatchLineEvent struct {
+	Resource  string
+	Operation string
+	Line      *ResponseBody
+}
+
+// Observer receives notifications about the work done by a Client. Embed
+// NopObserver to implement only the callbacks you need.
+type Observer interface {
+	OnRequestStart(event *RequestEvent)
+	OnRequestEnd(event *RequestEvent)
+	OnTokenRefresh(event *TokenEvent)
+	OnRetry(event *RetryEvent)
+	OnBatchLineFailure(event *BatchLineEvent)
+}
+
+type NopObserver struct{}
+
+func (NopObserver) OnRequestStart(event *RequestEvent)       {}
+func (NopObserver) OnRequestEnd(event *RequestEvent)         {}
+func (NopObserver) OnTokenRefresh(event *TokenEvent)         {}
+func (NopObserver) OnRetry(event *RetryEvent)                {}
+func (NopObserver) OnBatchLineFailure(event *BatchLineEvent) {}
+
+type observers []Observer
+
+func (o observers) requestStart(event *RequestEvent) {
+	for _, observer := range o {
+		observer.OnRequestStart(event)
+	}
+}
+
+func (o observers) requestEnd(event *RequestEvent) {
+	for _, observer := range o {
+		observer.OnRequestEnd(event)
+	}
+}
+
+func (o observers) tokenRefresh(event *TokenEvent) {
+	for _, observer := range o {
+		observer.OnTokenRefresh(event)
+	}
+}
+
+func (o observers) retry(event *RetryEvent) {
+	for _, observer := range o {
+		observer.OnRetry(event)
+	}
+}
+
+func (o observers) batchLines(ctx context.Context, lines []*ResponseBody) {
+	if len(o) == 0 {
+		return
+	}
+
+	op, _ := OperationFromContext(ctx)
+	if op == nil {
+		op = &Operation{}
+	}
+
+	for _, line := range lines {
+		if line == nil || line.StatusCode < 300 {
+			continue
+		}
+
+		event := &BatchLineEvent{Resource: op.Resource, Operation: op.Name, Line: line}
+		for _, observer := range o {
+			observer.OnBatchLineFailure(event)
+		}
+	}
+}
+
+// LogObserver writes one line per finished request, token refresh, retry and
+// failed batch line.
+type LogObserver struct {
+	NopObserver
+	logger *log.Logger
+}
+
+func NewLogObserver(logger *log.Logger) *LogObserver {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &LogObserver{logger: logger}
+}
+
+func (lo *LogObserver) OnRequestEnd(event *RequestEvent) {
+	if event.Err != nil {
+		lo.logger.Printf("[AKENEO_REQUEST_ERROR]: %s %s (%s/%s) after %s: %s", event.Method, event.Url, event.Resource, event.Operation, event.Duration, event.Err)
+		return
+	}
+
+	lo.logger.Printf("[AKENEO_REQUEST]: %s %s (%s/%s) %d in %s", event.Method, event.Url, event.Resource, event.Operation, event.StatusCode, event.Duration)
+}
+
+func (lo *LogObserver) OnTokenRefresh(event *TokenEvent) {
+	if event.Err != nil {
+		lo.logger.Printf("[AKENEO_TOKEN_ERROR]: %s grant after %s: %s", event.GrantType, event.Duration, event.Err)
+		return
+	}
+
+	lo.logger.Printf("[AKENEO_TOKEN]: %s grant in %s", event.GrantType, event.Duration)
+}
+
+func (lo *LogObserver) OnRetry(event *RetryEvent) {
+	lo.logger.Printf("[AKENEO_RETRY]: %s/%s attempt %d (status %d) in %s", event.Resource, event.Operation, event.Attempt, event.StatusCode, event.Delay)
+}
+
+func (lo *LogObserver) OnBatchLineFailure(event *BatchLineEvent) {
+	key := event.Line.Identifier
+	if key == "" {
+		key = event.Line.Code
+	}
+
+	lo.logger.Printf("[AKENEO_BATCH_LINE_ERROR]: %s line %d %s => %d %s", event.Resource, event.Line.Line, key, event.Line.StatusCode, event.Line.Message)
+}
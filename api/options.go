@@ -0,0 +1,291 @@
+package akeneo
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	PaginationTypePage        = "page"
+	PaginationTypeSearchAfter = "search_after"
+
+	maxPageLimit = 100
+)
+
+// QueryOptions is what the list methods (GetAll, Iterate) accept: the
+// RequestOpts map, kept for compatibility, or one of the typed option
+// structs. Options an endpoint does not support are reported as errors.
+type QueryOptions interface {
+	QueryValues() (url.Values, error)
+}
+
+// PageOptions are the options of the endpoints that only paginate.
+type PageOptions struct {
+	Page      int
+	Limit     int
+	WithCount bool
+}
+
+func (opts PageOptions) QueryValues() (url.Values, error) {
+	values := url.Values{}
+
+	if opts.Page < 0 {
+		return nil, fmt.Errorf("akeneo: option \"page\" must be positive, %d given", opts.Page)
+	}
+	if opts.Page > 0 {
+		values.Set("page", strconv.Itoa(opts.Page))
+	}
+
+	if opts.Limit < 0 || opts.Limit > maxPageLimit {
+		return nil, fmt.Errorf("akeneo: option \"limit\" must be between 1 and %d, %d given", maxPageLimit, opts.Limit)
+	}
+	if opts.Limit > 0 {
+		values.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	if opts.WithCount {
+		values.Set("withCount", "true")
+	}
+
+	return values, nil
+}
+
+// MediaFileOptions are the options of the media file list, which paginates
+// by page or with search_after.
+type MediaFileOptions struct {
+	Page           int
+	Limit          int
+	WithCount      bool
+	PaginationType string
+	SearchAfter    string
+}
+
+func (opts MediaFileOptions) QueryValues() (url.Values, error) {
+	values, err := PageOptions{Page: opts.Page, Limit: opts.Limit, WithCount: opts.WithCount}.QueryValues()
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.PaginationType {
+	case "", PaginationTypePage:
+		if opts.SearchAfter != "" {
+			return nil, fmt.Errorf("akeneo: option \"search_after\" needs the %q pagination type", PaginationTypeSearchAfter)
+		}
+	case PaginationTypeSearchAfter:
+		if opts.Page > 0 || opts.WithCount {
+			return nil, fmt.Errorf("akeneo: options \"page\" and \"withCount\" are not available with the %q pagination type", PaginationTypeSearchAfter)
+		}
+	default:
+		return nil, fmt.Errorf("akeneo: unknown pagination type %q", opts.PaginationType)
+	}
+
+	setString(values, "pagination_type", opts.PaginationType)
+	setString(values, "search_after", opts.SearchAfter)
+
+	return values, nil
+}
+
+// ProductModelOptions are the options of the product model list.
+type ProductModelOptions struct {
+	Page                 int
+	Limit                int
+	WithCount            bool
+	PaginationType       string
+	SearchAfter          string
+	Search               *Search
+	Scope                string
+	Locales              []string
+	Attributes           []string
+	WithAttributeOptions bool
+	WithQualityScores    bool
+}
+
+func (opts ProductModelOptions) QueryValues() (url.Values, error) {
+	values, err := MediaFileOptions{
+		Page:           opts.Page,
+		Limit:          opts.Limit,
+		WithCount:      opts.WithCount,
+		PaginationType: opts.PaginationType,
+		SearchAfter:    opts.SearchAfter,
+	}.QueryValues()
+	if err != nil {
+		return nil, err
+	}
+
+	setString(values, "scope", opts.Scope)
+	setString(values, "locales", strings.Join(opts.Locales, ","))
+	setString(values, "attributes", strings.Join(opts.Attributes, ","))
+	setBool(values, "with_attribute_options", opts.WithAttributeOptions)
+	setBool(values, "with_quality_scores", opts.WithQualityScores)
+
+	if err := opts.Search.setQueryValues(values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// ProductOptions are the options of the product list.
+type ProductOptions struct {
+	Page                 int
+	Limit                int
+	WithCount            bool
+	PaginationType       string
+	SearchAfter          string
+	Search               *Search
+	Scope                string
+	Locales              []string
+	Attributes           []string
+	WithAttributeOptions bool
+	WithQualityScores    bool
+	WithCompletenesses   bool
+}
+
+func (opts ProductOptions) QueryValues() (url.Values, error) {
+	values, err := ProductModelOptions{
+		Page:                 opts.Page,
+		Limit:                opts.Limit,
+		WithCount:            opts.WithCount,
+		PaginationType:       opts.PaginationType,
+		SearchAfter:          opts.SearchAfter,
+		Search:               opts.Search,
+		Scope:                opts.Scope,
+		Locales:              opts.Locales,
+		Attributes:           opts.Attributes,
+		WithAttributeOptions: opts.WithAttributeOptions,
+		WithQualityScores:    opts.WithQualityScores,
+	}.QueryValues()
+	if err != nil {
+		return nil, err
+	}
+
+	setBool(values, "with_completenesses", opts.WithCompletenesses)
+
+	return values, nil
+}
+
+// QueryValues converts the map values to query parameters: strings are
+// kept, numbers and booleans formatted, string lists joined with commas and
+// a *Search encoded, a nil one being left out. Any other type is an error.
+func (opts RequestOpts) QueryValues() (url.Values, error) {
+	values := url.Values{}
+
+	for _, key := range sortedOptionKeys(opts) {
+		switch value := opts[key].(type) {
+		case string:
+			values.Set(key, value)
+		case int:
+			values.Set(key, strconv.Itoa(value))
+		case int32:
+			values.Set(key, strconv.FormatInt(int64(value), 10))
+		case int64:
+			values.Set(key, strconv.FormatInt(value, 10))
+		case float64:
+			values.Set(key, strconv.FormatFloat(value, 'f', -1, 64))
+		case bool:
+			values.Set(key, strconv.FormatBool(value))
+		case []string:
+			values.Set(key, strings.Join(value, ","))
+		case *Search:
+			if key != "search" {
+				return nil, fmt.Errorf("akeneo: option %q cannot hold a search", key)
+			}
+			if err := value.setQueryValues(values); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("akeneo: option %q has unsupported type %T", key, value)
+		}
+	}
+
+	return values, nil
+}
+
+// encodeQuery returns the query parameters of opts, checking that the
+// endpoint supports each of them.
+func encodeQuery(opts QueryOptions, keys []string) (*url.Values, error) {
+	queryParams := &url.Values{}
+	if opts == nil {
+		return queryParams, nil
+	}
+
+	values, err := opts.QueryValues()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range sortedValueKeys(values) {
+		if !containsString(keys, key) {
+			return nil, fmt.Errorf("akeneo: option %q is not supported by this endpoint", key)
+		}
+		(*queryParams)[key] = values[key]
+	}
+
+	return queryParams, nil
+}
+
+func (s *Search) setQueryValues(values url.Values) error {
+	if s == nil {
+		return nil
+	}
+
+	search, err := s.Encode()
+	if err != nil {
+		return err
+	}
+
+	if len(s.filters) > 0 {
+		values.Set("search", search)
+	}
+	setString(values, "search_locale", s.locale)
+	setString(values, "search_scope", s.scope)
+
+	return nil
+}
+
+func setString(values url.Values, key string, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
+
+func setBool(values url.Values, key string, value bool) {
+	if value {
+		values.Set(key, "true")
+	}
+}
+
+func sortedOptionKeys(opts RequestOpts) []string {
+	keys := make([]string, 0, len(opts))
+	for key := range opts {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedValueKeys(values url.Values) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
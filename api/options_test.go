@@ -0,0 +1,134 @@
+package akeneo
+
+import "testing"
+
+func TestRequestOptsQueryValues(t *testing.T) {
+	values, err := RequestOpts{
+		"page":       2,
+		"limit":      int64(50),
+		"withCount":  true,
+		"locales":    []string{"en_US", "fr_FR"},
+		"scope":      "ecommerce",
+		"min_amount": 12.5,
+		"max_amount": float64(100),
+	}.QueryValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "limit=50&locales=en_US%2Cfr_FR&max_amount=100&min_amount=12.5&page=2&scope=ecommerce&withCount=true"
+	if encoded := values.Encode(); encoded != expected {
+		t.Errorf("unexpected query %s", encoded)
+	}
+
+	if _, err := (RequestOpts{"limit": uint(10)}).QueryValues(); err == nil {
+		t.Error("expected an error for an unsupported type")
+	}
+	if _, err := (RequestOpts{"scope": NewSearch()}).QueryValues(); err == nil {
+		t.Error("expected an error for a search outside the search option")
+	}
+}
+
+func TestNilSearchIsLeftOut(t *testing.T) {
+	queries := []QueryOptions{
+		RequestOpts{"search": (*Search)(nil), "limit": 10},
+		ProductModelOptions{Limit: 10},
+		ProductOptions{Limit: 10},
+	}
+
+	for _, opts := range queries {
+		values, err := opts.QueryValues()
+		if err != nil {
+			t.Errorf("%T: %v", opts, err)
+			continue
+		}
+		if encoded := values.Encode(); encoded != "limit=10" {
+			t.Errorf("%T: unexpected query %s", opts, encoded)
+		}
+	}
+}
+
+func TestPageOptionsQueryValues(t *testing.T) {
+	values, err := PageOptions{Page: 3, Limit: 100, WithCount: true}.QueryValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded := values.Encode(); encoded != "limit=100&page=3&withCount=true" {
+		t.Errorf("unexpected query %s", encoded)
+	}
+
+	for _, opts := range []PageOptions{{Page: -1}, {Limit: -1}, {Limit: 101}} {
+		if _, err := opts.QueryValues(); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+}
+
+func TestMediaFileOptionsQueryValues(t *testing.T) {
+	values, err := MediaFileOptions{PaginationType: PaginationTypeSearchAfter, SearchAfter: "abc", Limit: 10}.QueryValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded := values.Encode(); encoded != "limit=10&pagination_type=search_after&search_after=abc" {
+		t.Errorf("unexpected query %s", encoded)
+	}
+
+	invalid := []MediaFileOptions{
+		{SearchAfter: "abc"},
+		{PaginationType: PaginationTypeSearchAfter, Page: 2},
+		{PaginationType: PaginationTypeSearchAfter, WithCount: true},
+		{PaginationType: "cursor"},
+	}
+	for _, opts := range invalid {
+		if _, err := opts.QueryValues(); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+
+	if _, err := encodeQuery(MediaFileOptions{PaginationType: PaginationTypeSearchAfter}, mediaFileQueryKeys); err != nil {
+		t.Errorf("media file options rejected by the media file endpoint: %v", err)
+	}
+}
+
+func TestProductOptionsQueryValues(t *testing.T) {
+	values, err := ProductOptions{
+		Limit:              20,
+		Search:             NewSearch().Locale("en_US").Enabled(true),
+		Attributes:         []string{"name", "price"},
+		WithCompletenesses: true,
+	}.QueryValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"limit":               "20",
+		"search":              `{"enabled":[{"operator":"=","value":true}]}`,
+		"search_locale":       "en_US",
+		"attributes":          "name,price",
+		"with_completenesses": "true",
+	}
+	if len(values) != len(expected) {
+		t.Errorf("unexpected query %v", values)
+	}
+	for key, value := range expected {
+		if values.Get(key) != value {
+			t.Errorf("%s: expected %q, got %q", key, value, values.Get(key))
+		}
+	}
+
+	if _, err := (ProductOptions{Search: NewSearch().Family("LIKE", "shoes")}).QueryValues(); err == nil {
+		t.Error("expected the search error")
+	}
+}
+
+func TestEncodeQueryRejectsUnsupportedOptions(t *testing.T) {
+	if _, err := encodeQuery(ProductOptions{WithCompletenesses: true}, productModelQueryKeys); err == nil {
+		t.Error("expected an error for an option the endpoint does not support")
+	}
+
+	params, err := encodeQuery(nil, productQueryKeys)
+	if err != nil || len(*params) != 0 {
+		t.Errorf("unexpected query %v, %v", params, err)
+	}
+}
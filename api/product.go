@@ -3,24 +3,25 @@ package akeneo
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
+var productQueryKeys = []string{"page", "limit", "withCount", "scope", "search", "locales", "attributes", "pagination_type", "search_after", "search_locale", "search_scope", "with_attribute_options", "with_quality_scores", "with_completenesses"}
+
 type Product struct {
-	Identifier   string                              `json:"identifier"`
-	Enabled      bool                                `json:"enabled"`
-	FamilyCode   string                              `json:"family,omitempty"`
-	Categories   []string                            `json:"categories,omitempty"`
-	Groups       []string                            `json:"groups,omitempty"`
-	Parent       string                              `json:"parent,omitempty"`
-	Values       map[string][]*ProductAttributeValue `json:"values,omitempty"`
-	Associations map[string]*ProductAssociation      `json:"associations,omitempty"`
-	Created      string                              `json:"created,omitempty"`
-	Updated      string                              `json:"updated,omitempty"`
-	Metadata     *ProductsMetadata                   `json:"metadata,omitempty"`
+	Identifier   string                         `json:"identifier"`
+	Enabled      bool                           `json:"enabled"`
+	FamilyCode   string                         `json:"family,omitempty"`
+	Categories   []string                       `json:"categories,omitempty"`
+	Groups       []string                       `json:"groups,omitempty"`
+	Parent       string                         `json:"parent,omitempty"`
+	Values       ProductValues                  `json:"values,omitempty"`
+	Associations map[string]*ProductAssociation `json:"associations,omitempty"`
+	Created      string                         `json:"created,omitempty"`
+	Updated      string                         `json:"updated,omitempty"`
+	Metadata     *ProductsMetadata              `json:"metadata,omitempty"`
 }
 
 type ProductAttributeValue struct {
@@ -32,7 +33,7 @@ type ProductAttributeValue struct {
 type ProductAssociation struct {
 	Groups        []string `json:"groups,omitempty"`
 	Products      []string `json:"products,omitempty"`
-	ProductModels []string `json:"products,omitempty"`
+	ProductModels []string `json:"product_models,omitempty"`
 }
 
 type ProductsMetadata struct {
@@ -53,103 +54,162 @@ type ProductsResponse struct {
 	} `json:"_embedded"`
 }
 
-func (service *ProductApi) GetAll(opts RequestOpts) (*ProductsResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *ProductApi) GetAll(opts QueryOptions) (*ProductsResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	keyList := []string{"page", "limit", "withCount", "scope", "search", "locales", "attributes", "pagination_type", "search_after"}
+func (service *ProductApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*ProductsResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceProduct, OperationGetAll)
 
-	for _, key := range keyList {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, productQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "products", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "products", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &ProductsResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type ProductIterator struct {
+	*pager
+}
+
+func (service *ProductApi) Iterate(opts QueryOptions) *ProductIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *ProductApi) IterateWithContext(ctx context.Context, opts QueryOptions) *ProductIterator {
+	ctx = withOperation(ctx, ResourceProduct, OperationIterate)
+
+	return &ProductIterator{pager: newPager(ctx, service.client, "products", opts, productQueryKeys, func() collectionPage { return &ProductsResponse{} })}
+}
+
+func (it *ProductIterator) Item() *ProductItem {
+	item, _ := it.item.(*ProductItem)
+
+	return item
+}
+
+// NewProductIterator returns an iterator over items already in memory, e.g. for
+// a test double of ProductService.
+func NewProductIterator(items []ProductItem) *ProductIterator {
+	page := &ProductsResponse{}
+	page.Data.Items = items
+
+	return &ProductIterator{pager: newItemPager(page)}
+}
+
+func (page *ProductsResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *ProductApi) Get(code string) (*Product, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *ProductApi) GetWithContext(ctx context.Context, code string) (*Product, *ApiError) {
+	ctx = withOperation(ctx, ResourceProduct, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("products/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var product = &Product{}
 	if err = json.NewDecoder(response.Body).Decode(&product); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return product, nil
 }
 
 func (service *ProductApi) Create(product *Product) *ApiError {
+	return service.CreateWithContext(context.Background(), product)
+}
+
+func (service *ProductApi) CreateWithContext(ctx context.Context, product *Product) *ApiError {
+	ctx = withOperation(ctx, ResourceProduct, OperationCreate)
+
 	headers := service.client.getHeadersForRequest()
 	body, _ := json.Marshal(product)
 
-	response, err := service.client.DoRequest("POST", "products", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", "products", headers, body, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *ProductApi) Upsert(product *Product) *ApiError {
+	return service.UpsertWithContext(context.Background(), product)
+}
+
+func (service *ProductApi) UpsertWithContext(ctx context.Context, product *Product) *ApiError {
+	ctx = withOperation(ctx, ResourceProduct, OperationUpsert)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("products/%s", product.Identifier)
 	body, _ := json.Marshal(product)
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Code: 0, Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *ProductApi) BatchUpsert(products []*Product) ([]*ResponseBody, *ApiError) {
+	return service.BatchUpsertWithContext(context.Background(), products)
+}
+
+func (service *ProductApi) BatchUpsertWithContext(ctx context.Context, products []*Product) ([]*ResponseBody, *ApiError) {
+	ctx = withOperation(ctx, ResourceProduct, OperationBatchUpsert)
+
 	headers := service.client.getHeadersForBatchRequest()
 	var body []byte
 
@@ -159,16 +219,15 @@ func (service *ProductApi) BatchUpsert(products []*Product) ([]*ResponseBody, *A
 		body = append(body, '\n')
 	}
 
-	response, err := service.client.DoRequest("PATCH", "products", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", "products", headers, body, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var apiResponse []*ResponseBody
@@ -178,29 +237,40 @@ func (service *ProductApi) BatchUpsert(products []*Product) ([]*ResponseBody, *A
 		var responseLine *ResponseBody
 		var reader = bytes.NewReader(scanner.Bytes())
 		if err = json.NewDecoder(reader).Decode(&responseLine); err != nil {
-			return nil, &ApiError{Message: err.Error()}
+			return nil, newApiError(err)
 		}
 
 		apiResponse = append(apiResponse, responseLine)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, newApiError(err)
+	}
+
+	service.client.observers.batchLines(ctx, apiResponse)
+
 	return apiResponse, nil
 }
 
 func (service *ProductApi) Delete(code string) *ApiError {
+	return service.DeleteWithContext(context.Background(), code)
+}
+
+func (service *ProductApi) DeleteWithContext(ctx context.Context, code string) *ApiError {
+	ctx = withOperation(ctx, ResourceProduct, OperationDelete)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("products/%s", code)
 
-	response, err := service.client.DoRequest("DELETE", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "DELETE", uri, headers, nil, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
@@ -2,16 +2,17 @@ package akeneo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"mime/multipart"
-	"net/url"
 	"os"
 	"path/filepath"
 )
 
+var mediaFileQueryKeys = []string{"scope", "search", "locales", "attributes", "pagination_type", "page", "search_after", "limit", "withCount"}
+
 type MediaFileBody struct {
 	Product      *MediaFileProduct
 	ProductModel *MediaFileProductModel
@@ -60,134 +61,186 @@ type ProductMediaFileResponse struct {
 	} `json:"_embedded"`
 }
 
-func (service *MediaFileApi) GetAll(opts RequestOpts) (*ProductMediaFileResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *MediaFileApi) GetAll(opts QueryOptions) (*ProductMediaFileResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	keyList := []string{"scope", "search", "locales", "attributes", "pagination_type", "page", "search_after", "limit", "withCount"}
+func (service *MediaFileApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*ProductMediaFileResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceMediaFile, OperationGetAll)
 
-	for _, key := range keyList {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, mediaFileQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "media-files", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "media-files", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &ProductMediaFileResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type MediaFileIterator struct {
+	*pager
+}
+
+func (service *MediaFileApi) Iterate(opts QueryOptions) *MediaFileIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *MediaFileApi) IterateWithContext(ctx context.Context, opts QueryOptions) *MediaFileIterator {
+	ctx = withOperation(ctx, ResourceMediaFile, OperationIterate)
+
+	return &MediaFileIterator{pager: newPager(ctx, service.client, "media-files", opts, mediaFileQueryKeys, func() collectionPage { return &ProductMediaFileResponse{} })}
+}
+
+func (it *MediaFileIterator) Item() *ProductMediaFileItem {
+	item, _ := it.item.(*ProductMediaFileItem)
+
+	return item
+}
+
+// NewMediaFileIterator returns an iterator over items already in memory, e.g. for
+// a test double of MediaFileService.
+func NewMediaFileIterator(items []ProductMediaFileItem) *MediaFileIterator {
+	page := &ProductMediaFileResponse{}
+	page.Data.Items = items
+
+	return &MediaFileIterator{pager: newItemPager(page)}
+}
+
+func (page *ProductMediaFileResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *MediaFileApi) Get(code string) (*MediaFile, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *MediaFileApi) GetWithContext(ctx context.Context, code string) (*MediaFile, *ApiError) {
+	ctx = withOperation(ctx, ResourceMediaFile, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("media-files/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var product = &MediaFile{}
 	if err = json.NewDecoder(response.Body).Decode(&product); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return product, nil
 }
 
 func (service *MediaFileApi) Create(mediaFile *MediaFileBody) *ApiError {
+	return service.CreateWithContext(context.Background(), mediaFile)
+}
+
+func (service *MediaFileApi) CreateWithContext(ctx context.Context, mediaFile *MediaFileBody) *ApiError {
+	ctx = withOperation(ctx, ResourceMediaFile, OperationCreate)
+
 	var body = &bytes.Buffer{}
 	form := multipart.NewWriter(body)
 
 	if mediaFile.Product != nil {
 		productJson, err := json.Marshal(*mediaFile.Product)
 		if err != nil {
-			return &ApiError{Message: err.Error()}
+			return newApiError(err)
 		}
 
 		if err := form.WriteField("product", fmt.Sprintf("%s", productJson)); err != nil {
-			return &ApiError{Message: err.Error()}
+			return newApiError(err)
 		}
 	} else if mediaFile.ProductModel != nil {
 		productModelJson, err := json.Marshal(*mediaFile.ProductModel)
 		if err != nil {
-			return &ApiError{Message: err.Error()}
+			return newApiError(err)
 		}
 
 		if err := form.WriteField("product_model", fmt.Sprintf("%s", productModelJson)); err != nil {
-			return &ApiError{Message: err.Error()}
+			return newApiError(err)
 		}
 	}
 
 	file, err := form.CreateFormFile("file", mediaFile.FileName)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	reader := bytes.NewReader(mediaFile.File)
 	if _, err := io.Copy(file, reader); err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	if err := form.Close(); err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	headers := service.client.getHeadersForRequest()
 	headers.Set("Content-Type", form.FormDataContentType())
 
-	response, err := service.client.DoRequest("POST", "media-files", headers, body.Bytes(), nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", "media-files", headers, body.Bytes(), nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *MediaFileApi) Download(code string, folderPath string) *ApiError {
+	return service.DownloadWithContext(context.Background(), code, folderPath)
+}
+
+func (service *MediaFileApi) DownloadWithContext(ctx context.Context, code string, folderPath string) *ApiError {
+	ctx = withOperation(ctx, ResourceMediaFile, OperationDownload)
 
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("media-files/%s/download", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	imagePath := fmt.Sprintf("%s/%s", folderPath, code)
@@ -196,18 +249,18 @@ func (service *MediaFileApi) Download(code string, folderPath string) *ApiError
 
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		if err := os.MkdirAll(dir, 0775); err != nil {
-			return &ApiError{Message: err.Error()}
+			return newApiError(err)
 		}
 	}
 
 	out, err := os.Create(imagePath)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	_, err = io.Copy(out, response.Body)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	return nil
@@ -3,21 +3,22 @@ package akeneo
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 )
 
+var productModelQueryKeys = []string{"scope", "search", "locales", "attributes", "pagination_type", "page", "search_after", "limit", "withCount", "search_locale", "search_scope", "with_attribute_options", "with_quality_scores"}
+
 type ProductModel struct {
-	Code          string                              `json:"code"`
-	FamilyVariant string                              `json:"family_variant"`
-	Parent        string                              `json:"parent,omitempty"`
-	Categories    []string                            `json:"categories,omitempty"`
-	Values        map[string][]*ProductAttributeValue `json:"values,omitempty"`
-	Created       string                              `json:"created,omitempty"`
-	Updated       string                              `json:"updated,omitempty"`
-	Metadata      *ProductsMetadata                   `json:"metadata,omitempty"`
+	Code          string            `json:"code"`
+	FamilyVariant string            `json:"family_variant"`
+	Parent        string            `json:"parent,omitempty"`
+	Categories    []string          `json:"categories,omitempty"`
+	Values        ProductValues     `json:"values,omitempty"`
+	Created       string            `json:"created,omitempty"`
+	Updated       string            `json:"updated,omitempty"`
+	Metadata      *ProductsMetadata `json:"metadata,omitempty"`
 }
 
 type ProductModelApi ApiService
@@ -34,103 +35,162 @@ type ProductModelResponse struct {
 	} `json:"_embedded"`
 }
 
-func (service *ProductModelApi) GetAll(opts RequestOpts) (*ProductModelResponse, *ApiError) {
-	headers := service.client.getHeadersForRequest()
-	queryParams := &url.Values{}
+func (service *ProductModelApi) GetAll(opts QueryOptions) (*ProductModelResponse, *ApiError) {
+	return service.GetAllWithContext(context.Background(), opts)
+}
 
-	keyList := []string{"scope", "search", "locales", "attributes", "pagination_type", "page", "search_after", "limit", "withCount"}
+func (service *ProductModelApi) GetAllWithContext(ctx context.Context, opts QueryOptions) (*ProductModelResponse, *ApiError) {
+	ctx = withOperation(ctx, ResourceProductModel, OperationGetAll)
 
-	for _, key := range keyList {
-		if value, ok := opts[key].(string); ok {
-			queryParams.Add(key, value)
-		}
+	headers := service.client.getHeadersForRequest()
+	queryParams, err := encodeQuery(opts, productModelQueryKeys)
+	if err != nil {
+		return nil, newApiError(err)
 	}
 
-	response, err := service.client.DoRequest("GET", "product-models", headers, nil, queryParams)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", "product-models", headers, nil, queryParams)
 
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	resp := &ProductModelResponse{}
 
 	if err = json.NewDecoder(response.Body).Decode(&resp); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return resp, nil
 }
 
+type ProductModelIterator struct {
+	*pager
+}
+
+func (service *ProductModelApi) Iterate(opts QueryOptions) *ProductModelIterator {
+	return service.IterateWithContext(context.Background(), opts)
+}
+
+func (service *ProductModelApi) IterateWithContext(ctx context.Context, opts QueryOptions) *ProductModelIterator {
+	ctx = withOperation(ctx, ResourceProductModel, OperationIterate)
+
+	return &ProductModelIterator{pager: newPager(ctx, service.client, "product-models", opts, productModelQueryKeys, func() collectionPage { return &ProductModelResponse{} })}
+}
+
+func (it *ProductModelIterator) Item() *ProductModelItem {
+	item, _ := it.item.(*ProductModelItem)
+
+	return item
+}
+
+// NewProductModelIterator returns an iterator over items already in memory, e.g. for
+// a test double of ProductModelService.
+func NewProductModelIterator(items []ProductModelItem) *ProductModelIterator {
+	page := &ProductModelResponse{}
+	page.Data.Items = items
+
+	return &ProductModelIterator{pager: newItemPager(page)}
+}
+
+func (page *ProductModelResponse) items() []interface{} {
+	items := make([]interface{}, len(page.Data.Items))
+	for i := range page.Data.Items {
+		items[i] = &page.Data.Items[i]
+	}
+
+	return items
+}
+
 func (service *ProductModelApi) Get(code string) (*ProductModel, *ApiError) {
+	return service.GetWithContext(context.Background(), code)
+}
+
+func (service *ProductModelApi) GetWithContext(ctx context.Context, code string) (*ProductModel, *ApiError) {
+	ctx = withOperation(ctx, ResourceProductModel, OperationGet)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("product-models/%s", code)
 
-	response, err := service.client.DoRequest("GET", uri, headers, nil, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "GET", uri, headers, nil, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var product = &ProductModel{}
 	if err = json.NewDecoder(response.Body).Decode(&product); err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	return product, nil
 }
 
 func (service *ProductModelApi) Create(productModel *ProductModel) *ApiError {
+	return service.CreateWithContext(context.Background(), productModel)
+}
+
+func (service *ProductModelApi) CreateWithContext(ctx context.Context, productModel *ProductModel) *ApiError {
+	ctx = withOperation(ctx, ResourceProductModel, OperationCreate)
+
 	headers := service.client.getHeadersForRequest()
 	body, _ := json.Marshal(productModel)
 
-	response, err := service.client.DoRequest("POST", "product-models", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "POST", "product-models", headers, body, nil)
 	if err != nil {
-		return &ApiError{Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *ProductModelApi) Upsert(productModel *ProductModel) *ApiError {
+	return service.UpsertWithContext(context.Background(), productModel)
+}
+
+func (service *ProductModelApi) UpsertWithContext(ctx context.Context, productModel *ProductModel) *ApiError {
+	ctx = withOperation(ctx, ResourceProductModel, OperationUpsert)
+
 	headers := service.client.getHeadersForRequest()
 	uri := fmt.Sprintf("product-models/%s", productModel.Code)
 	body, _ := json.Marshal(productModel)
 
-	response, err := service.client.DoRequest("PATCH", uri, headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", uri, headers, body, nil)
 	if err != nil {
-		return &ApiError{Code: 0, Message: err.Error()}
+		return newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return newResponseError(response)
 	}
 
 	return nil
 }
 
 func (service *ProductModelApi) BatchUpsert(productModels []*ProductModel) ([]*ResponseBody, *ApiError) {
+	return service.BatchUpsertWithContext(context.Background(), productModels)
+}
+
+func (service *ProductModelApi) BatchUpsertWithContext(ctx context.Context, productModels []*ProductModel) ([]*ResponseBody, *ApiError) {
+	ctx = withOperation(ctx, ResourceProductModel, OperationBatchUpsert)
+
 	headers := service.client.getHeadersForBatchRequest()
 	var body []byte
 
@@ -140,16 +200,15 @@ func (service *ProductModelApi) BatchUpsert(productModels []*ProductModel) ([]*R
 		body = append(body, '\n')
 	}
 
-	response, err := service.client.DoRequest("PATCH", "product-models", headers, body, nil)
+	response, err := service.client.DoRequestWithContext(ctx, "PATCH", "product-models", headers, body, nil)
 	if err != nil {
-		return nil, &ApiError{Message: err.Error()}
+		return nil, newApiError(err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode >= 300 {
-		msg, _ := ioutil.ReadAll(response.Body)
-		return nil, &ApiError{Code: response.StatusCode, Status: response.Status, Message: fmt.Sprintf("%s", msg)}
+		return nil, newResponseError(response)
 	}
 
 	var apiResponse []*ResponseBody
@@ -159,11 +218,17 @@ func (service *ProductModelApi) BatchUpsert(productModels []*ProductModel) ([]*R
 		var responseLine *ResponseBody
 		var reader = bytes.NewReader(scanner.Bytes())
 		if err = json.NewDecoder(reader).Decode(&responseLine); err != nil {
-			return nil, &ApiError{Message: err.Error()}
+			return nil, newApiError(err)
 		}
 
 		apiResponse = append(apiResponse, responseLine)
 	}
 
+	if err = scanner.Err(); err != nil {
+		return nil, newApiError(err)
+	}
+
+	service.client.observers.batchLines(ctx, apiResponse)
+
 	return apiResponse, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,51 @@
+package akeneo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProductAssociationKeepsProductModels(t *testing.T) {
+	association := &ProductAssociation{Products: []string{"sku-1"}, ProductModels: []string{"model-1"}}
+
+	encoded, err := json.Marshal(association)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"products":["sku-1"],"product_models":["model-1"]}` {
+		t.Errorf("unexpected association %s", encoded)
+	}
+
+	decoded := &ProductAssociation{}
+	if err := json.Unmarshal(encoded, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Products) != 1 || len(decoded.ProductModels) != 1 || decoded.ProductModels[0] != "model-1" {
+		t.Errorf("unexpected association %+v", decoded)
+	}
+}
+
+func TestProductBatchUpsertReportsBrokenResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/oauth/v1/token" {
+			fmt.Fprint(w, `{"access_token":"access","refresh_token":"refresh","token_type":"bearer","expires_in":3600}`)
+			return
+		}
+
+		// The connection closes before the announced length is sent.
+		line := `{"line":1,"identifier":"a","status_code":204}` + "\n"
+		w.Header().Set("Content-Length", fmt.Sprint(len(line)+100))
+		fmt.Fprint(w, line)
+	}))
+	defer server.Close()
+
+	api := NewAkeneoApi(NewClient(&ClientConfig{BaseUrl: server.URL, ClientId: "client", SecretKey: "secret", Username: "admin", Password: "admin"}))
+
+	lines, err := api.Product.BatchUpsert([]*Product{{Identifier: "a"}, {Identifier: "b"}})
+	if err == nil {
+		t.Errorf("expected an error for an incomplete response, got %d lines", len(lines))
+	}
+}
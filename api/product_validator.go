@@ -0,0 +1,594 @@
+package akeneo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation is a problem found on a product or product model payload. The
+// attribute is empty for problems that are not about a value.
+type Violation struct {
+	Attribute string
+	Scope     string
+	Locale    string
+	Message   string
+}
+
+func (v Violation) String() string {
+	if v.Attribute == "" {
+		return v.Message
+	}
+
+	context := v.Attribute
+	if v.Scope != "" {
+		context += fmt.Sprintf(" [channel %s]", v.Scope)
+	}
+	if v.Locale != "" {
+		context += fmt.Sprintf(" [locale %s]", v.Locale)
+	}
+
+	return fmt.Sprintf("%s: %s", context, v.Message)
+}
+
+type Violations []Violation
+
+// Err returns nil when there is no violation, or an error listing them all.
+func (violations Violations) Err() error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.String()
+	}
+
+	return fmt.Errorf("akeneo: invalid payload: %s", strings.Join(messages, "; "))
+}
+
+// ProductValidator checks product and product model payloads against the
+// attribute, family, channel and measure family definitions it was given, so
+// most of the 422 of a batch upsert can be caught before sending it.
+type ProductValidator struct {
+	// CheckRequirements also reports the values a product misses for the
+	// attribute requirements of its family. Akeneo saves such products and
+	// only counts them as incomplete.
+	CheckRequirements bool
+
+	attributes      map[string]*Attribute
+	families        map[string]*Family
+	variantFamilies map[string]string
+	channels        map[string]*Channel
+	measureFamilies map[string]*MeasureFamily
+}
+
+func NewProductValidator() *ProductValidator {
+	return &ProductValidator{
+		attributes:      map[string]*Attribute{},
+		families:        map[string]*Family{},
+		variantFamilies: map[string]string{},
+		channels:        map[string]*Channel{},
+		measureFamilies: map[string]*MeasureFamily{},
+	}
+}
+
+// LoadProductValidator builds a validator from every attribute, family,
+// family variant, channel and measure family of the PIM.
+func LoadProductValidator(ctx context.Context, api *Api) (*ProductValidator, *ApiError) {
+	validator := NewProductValidator()
+	opts := PageOptions{Limit: maxPageLimit}
+
+	attributes := api.Attribute.IterateWithContext(ctx, opts)
+	for attributes.Next() {
+		attribute := attributes.Item().Attribute
+		validator.AddAttributes(&attribute)
+	}
+	if err := attributes.Err(); err != nil {
+		return nil, err
+	}
+
+	families := api.Family.IterateWithContext(ctx, opts)
+	for families.Next() {
+		family := families.Item().Family
+		validator.AddFamilies(&family)
+	}
+	if err := families.Err(); err != nil {
+		return nil, err
+	}
+
+	for code := range validator.families {
+		variants := api.FamilyVariant.IterateWithContext(ctx, code, opts)
+		for variants.Next() {
+			validator.AddFamilyVariants(code, variants.Item())
+		}
+		if err := variants.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	channels := api.Channel.IterateWithContext(ctx, opts)
+	for channels.Next() {
+		channel := channels.Item().Channel
+		validator.AddChannels(&channel)
+	}
+	if err := channels.Err(); err != nil {
+		return nil, err
+	}
+
+	measureFamilies := api.MeasureFamily.IterateWithContext(ctx, opts)
+	for measureFamilies.Next() {
+		measureFamily := measureFamilies.Item().MeasureFamily
+		validator.AddMeasureFamilies(&measureFamily)
+	}
+	if err := measureFamilies.Err(); err != nil {
+		return nil, err
+	}
+
+	return validator, nil
+}
+
+func (v *ProductValidator) AddAttributes(attributes ...*Attribute) *ProductValidator {
+	for _, attribute := range attributes {
+		v.attributes[attribute.Code] = attribute
+	}
+
+	return v
+}
+
+func (v *ProductValidator) AddFamilies(families ...*Family) *ProductValidator {
+	for _, family := range families {
+		v.families[family.Code] = family
+	}
+
+	return v
+}
+
+func (v *ProductValidator) AddFamilyVariants(familyCode string, variants ...*FamilyVariant) *ProductValidator {
+	for _, variant := range variants {
+		v.variantFamilies[variant.Code] = familyCode
+	}
+
+	return v
+}
+
+func (v *ProductValidator) AddChannels(channels ...*Channel) *ProductValidator {
+	for _, channel := range channels {
+		v.channels[channel.Code] = channel
+	}
+
+	return v
+}
+
+func (v *ProductValidator) AddMeasureFamilies(measureFamilies ...*MeasureFamily) *ProductValidator {
+	for _, measureFamily := range measureFamilies {
+		v.measureFamilies[measureFamily.Code] = measureFamily
+	}
+
+	return v
+}
+
+func (v *ProductValidator) ValidateProduct(product *Product) Violations {
+	var violations Violations
+
+	if product.Identifier == "" {
+		violations = append(violations, Violation{Message: "the identifier is required"})
+	}
+
+	family, known := v.families[product.FamilyCode]
+	if product.FamilyCode != "" && !known {
+		violations = append(violations, Violation{Message: fmt.Sprintf("family %q does not exist", product.FamilyCode)})
+	}
+
+	violations = append(violations, v.validateValues(product.Values, family)...)
+
+	if v.CheckRequirements && family != nil {
+		violations = append(violations, v.validateRequirements(product.Values, family)...)
+	}
+
+	return violations
+}
+
+func (v *ProductValidator) ValidateProductModel(model *ProductModel) Violations {
+	var violations Violations
+
+	if model.Code == "" {
+		violations = append(violations, Violation{Message: "the code is required"})
+	}
+
+	var family *Family
+	if familyCode, known := v.variantFamilies[model.FamilyVariant]; known {
+		family = v.families[familyCode]
+	} else if len(v.variantFamilies) > 0 {
+		violations = append(violations, Violation{Message: fmt.Sprintf("family variant %q does not exist", model.FamilyVariant)})
+	}
+
+	return append(violations, v.validateValues(model.Values, family)...)
+}
+
+func (v *ProductValidator) validateValues(values ProductValues, family *Family) Violations {
+	var violations Violations
+
+	codes := make([]string, 0, len(values))
+	for code := range values {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		attribute, known := v.attributes[code]
+		if !known {
+			violations = append(violations, Violation{Attribute: code, Message: "the attribute does not exist"})
+			continue
+		}
+
+		if family != nil && len(family.Attributes) > 0 && !containsString(family.Attributes, code) {
+			violations = append(violations, Violation{Attribute: code, Message: fmt.Sprintf("the attribute does not belong to the family %q", family.Code)})
+		}
+
+		seen := map[string]bool{}
+		for _, value := range values[code] {
+			if value == nil {
+				continue
+			}
+
+			check := &valueCheck{validator: v, attribute: attribute, violation: Violation{Attribute: code}}
+			if value.Scope != nil {
+				check.violation.Scope = *value.Scope
+			}
+			if value.Locale != nil {
+				check.violation.Locale = *value.Locale
+			}
+
+			key := check.violation.Scope + "/" + check.violation.Locale
+			if seen[key] {
+				check.fail("the value is given twice")
+			}
+			seen[key] = true
+
+			check.context(value)
+			if value.Data != nil {
+				check.data(value.Data)
+			}
+
+			violations = append(violations, check.violations...)
+		}
+	}
+
+	return violations
+}
+
+func (v *ProductValidator) validateRequirements(values ProductValues, family *Family) Violations {
+	var violations Violations
+
+	calculator := &CompletenessCalculator{attributes: v.attributes, channels: v.channels}
+	reported := map[Violation]bool{}
+
+	for _, completeness := range calculator.calculate([]ProductValues{values}, family) {
+		for _, code := range completeness.Missing {
+			violation := Violation{
+				Attribute: code,
+				Scope:     completeness.Scope,
+				Message:   fmt.Sprintf("a value is required by the family %q", family.Code),
+			}
+			if v.attributes[code].Localizable {
+				violation.Locale = completeness.Locale
+			}
+
+			if !reported[violation] {
+				reported[violation] = true
+				violations = append(violations, violation)
+			}
+		}
+	}
+
+	return violations
+}
+
+type valueCheck struct {
+	validator  *ProductValidator
+	attribute  *Attribute
+	violation  Violation
+	violations Violations
+}
+
+func (c *valueCheck) fail(message string) {
+	violation := c.violation
+	violation.Message = message
+	c.violations = append(c.violations, violation)
+}
+
+// context checks the scope and locale of a value against the attribute
+// flags and the channels.
+func (c *valueCheck) context(value *ProductAttributeValue) {
+	attribute := c.attribute
+	scope, locale := c.violation.Scope, c.violation.Locale
+
+	if attribute.Scopable && value.Scope == nil {
+		c.fail("the attribute is scopable, a channel is required")
+	}
+	if !attribute.Scopable && value.Scope != nil {
+		c.fail("the attribute is not scopable, the channel must be null")
+	}
+	if attribute.Localizable && value.Locale == nil {
+		c.fail("the attribute is localizable, a locale is required")
+	}
+	if !attribute.Localizable && value.Locale != nil {
+		c.fail("the attribute is not localizable, the locale must be null")
+	}
+
+	if locale != "" && len(attribute.AvailableLocales) > 0 && !containsString(attribute.AvailableLocales, locale) {
+		c.fail(fmt.Sprintf("the attribute is only available for the locales %s", strings.Join(attribute.AvailableLocales, ", ")))
+	}
+
+	channels := c.validator.channels
+	if len(channels) == 0 {
+		return
+	}
+
+	if scope != "" {
+		channel, known := channels[scope]
+		if !known {
+			c.fail("the channel does not exist")
+		} else if locale != "" && !containsString(channel.Locales, locale) {
+			c.fail("the locale is not activated for the channel")
+		}
+	} else if locale != "" {
+		activated := false
+		for _, channel := range channels {
+			activated = activated || containsString(channel.Locales, locale)
+		}
+		if !activated {
+			c.fail("the locale is not activated")
+		}
+	}
+}
+
+func (c *valueCheck) data(data interface{}) {
+	attribute := c.attribute
+
+	switch attribute.Type_ {
+	case AkeneoTypeIdentifier, AkeneoTypeText, AkeneoTypeTextArea:
+		var text string
+		if c.decode(data, &text, "a string") {
+			c.text(text)
+		}
+	case AkeneoTypeNumber:
+		var number json.Number
+		if c.decode(data, &number, "a number") {
+			c.number("", number)
+		}
+	case AkeneoTypeMetric:
+		metric := &MetricData{}
+		if c.decode(data, metric, "an object with an amount and a unit") {
+			c.metric(metric)
+		}
+	case AkeneoTypePriceCollection:
+		var prices []PriceData
+		if c.decode(data, &prices, "a list of prices") {
+			c.prices(prices)
+		}
+	case AkeneoTypeBoolean:
+		var boolean bool
+		c.decode(data, &boolean, "a boolean")
+	case AkeneoTypeDate:
+		var date string
+		if c.decode(data, &date, "a date") {
+			c.date(date)
+		}
+	case AkeneoTypeSimpleSelect, AkeneoTypeReferenceDataSimpleSelect, AkeneoTypeReferenceEntity:
+		var code string
+		c.decode(data, &code, "an option code")
+	case AkeneoTypeMultiSelect, AkeneoTypeReferenceDataMultiSelect, AkeneoTypeAssetCollection, AkeneoTypeReferenceEntityCollection:
+		var codes []string
+		c.decode(data, &codes, "a list of codes")
+	case AkeneoTypeImage, AkeneoTypeFile:
+		var file string
+		if c.decode(data, &file, "a file path") {
+			c.file(file)
+		}
+	case AkeneoTypeTable:
+		var rows []TableRow
+		if c.decode(data, &rows, "a list of rows") {
+			c.table(rows)
+		}
+	}
+}
+
+func (c *valueCheck) decode(data interface{}, dst interface{}, expected string) bool {
+	if err := decodeData(data, dst); err != nil {
+		c.fail(fmt.Sprintf("the data must be %s", expected))
+		return false
+	}
+
+	return true
+}
+
+func (c *valueCheck) text(text string) {
+	attribute := c.attribute
+
+	limit := int(attribute.MaxCharacters)
+	if limit == 0 && attribute.Type_ == AkeneoTypeTextArea {
+		limit = 65535
+	} else if limit == 0 {
+		limit = 255
+	}
+
+	if utf8.RuneCountInString(text) > limit {
+		c.fail(fmt.Sprintf("the text is longer than %d characters", limit))
+	}
+
+	switch attribute.ValidationRule {
+	case ValidationRuleEmail:
+		if _, err := mail.ParseAddress(text); err != nil {
+			c.fail("the text is not a valid email address")
+		}
+	case ValidationRuleUrl:
+		if parsed, err := url.ParseRequestURI(text); err != nil || parsed.Host == "" {
+			c.fail("the text is not a valid URL")
+		}
+	case ValidationRuleRegexp:
+		pattern, err := compileValidationRegexp(attribute.ValidationRegexp)
+		if err != nil {
+			c.fail(fmt.Sprintf("the text cannot be checked: validation_regexp %q %s", attribute.ValidationRegexp, err))
+		} else if !pattern.MatchString(text) {
+			c.fail(fmt.Sprintf("the text does not match %s", attribute.ValidationRegexp))
+		}
+	}
+}
+
+func (c *valueCheck) number(label string, number json.Number) {
+	attribute := c.attribute
+
+	amount, err := strconv.ParseFloat(number.String(), 64)
+	if err != nil {
+		c.fail(fmt.Sprintf("%s%q is not a number", label, number))
+		return
+	}
+
+	if !attribute.DecimalsAllowed && amount != math.Trunc(amount) {
+		c.fail(fmt.Sprintf("%s%s must be an integer", label, number))
+	}
+	if !attribute.NegativeAllowed && attribute.Type_ != AkeneoTypePriceCollection && amount < 0 {
+		c.fail(fmt.Sprintf("%s%s cannot be negative", label, number))
+	}
+	if min, err := strconv.ParseFloat(attribute.NumberMin, 64); err == nil && amount < min {
+		c.fail(fmt.Sprintf("%s%s is lower than the minimum %s", label, number, attribute.NumberMin))
+	}
+	if max, err := strconv.ParseFloat(attribute.NumberMax, 64); err == nil && amount > max {
+		c.fail(fmt.Sprintf("%s%s is greater than the maximum %s", label, number, attribute.NumberMax))
+	}
+}
+
+func (c *valueCheck) metric(metric *MetricData) {
+	if metric.Amount == "" {
+		c.fail("the metric amount is required")
+	} else {
+		c.number("", metric.Amount)
+	}
+
+	if metric.Unit == "" {
+		c.fail("the metric unit is required")
+		return
+	}
+
+	measureFamily, known := c.validator.measureFamilies[c.attribute.MetricFamily]
+	if !known {
+		return
+	}
+
+	for _, unit := range measureFamily.Units {
+		if unit.Code == metric.Unit {
+			return
+		}
+	}
+
+	c.fail(fmt.Sprintf("the unit %q does not belong to the measure family %q", metric.Unit, measureFamily.Code))
+}
+
+func (c *valueCheck) prices(prices []PriceData) {
+	currencies := map[string]bool{}
+
+	for _, price := range prices {
+		if price.Currency == "" {
+			c.fail("a price has no currency")
+			continue
+		}
+		if currencies[price.Currency] {
+			c.fail(fmt.Sprintf("the currency %s is given twice", price.Currency))
+		}
+		currencies[price.Currency] = true
+
+		if price.Amount != "" {
+			c.number(price.Currency+" ", price.Amount)
+		}
+	}
+}
+
+func (c *valueCheck) date(date string) {
+	parsed, ok := parseDate(date)
+	if !ok {
+		c.fail(fmt.Sprintf("%q is not a date", date))
+		return
+	}
+
+	if min := c.attribute.DateMin; min != nil && parsed.Before(*min) {
+		c.fail(fmt.Sprintf("the date is before %s", min.Format("2006-01-02")))
+	}
+	if max := c.attribute.DateMax; max != nil && parsed.After(*max) {
+		c.fail(fmt.Sprintf("the date is after %s", max.Format("2006-01-02")))
+	}
+}
+
+func (c *valueCheck) file(file string) {
+	allowed := c.attribute.AllowedExtensions
+	if len(allowed) == 0 {
+		return
+	}
+
+	extension := strings.ToLower(strings.TrimPrefix(path.Ext(file), "."))
+	for _, candidate := range allowed {
+		if strings.ToLower(candidate) == extension {
+			return
+		}
+	}
+
+	c.fail(fmt.Sprintf("the file extension must be one of %s", strings.Join(allowed, ", ")))
+}
+
+func (c *valueCheck) table(rows []TableRow) {
+	columns := c.attribute.TableConfiguration
+	if len(columns) == 0 {
+		return
+	}
+
+	known := map[string]bool{}
+	for _, column := range columns {
+		known[column.Code] = true
+	}
+
+	for i, row := range rows {
+		if _, ok := row[columns[0].Code]; !ok {
+			c.fail(fmt.Sprintf("row %d has no value for the first column %q", i, columns[0].Code))
+		}
+
+		for _, code := range sortedRowKeys(row) {
+			if !known[code] {
+				c.fail(fmt.Sprintf("row %d has an unknown column %q", i, code))
+			}
+		}
+	}
+}
+
+func sortedRowKeys(row TableRow) []string {
+	keys := make([]string, 0, len(row))
+	for key := range row {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func isEmptyData(data interface{}) bool {
+	switch typed := data.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	case []interface{}:
+		return len(typed) == 0
+	case []string:
+		return len(typed) == 0
+	}
+
+	return false
+}
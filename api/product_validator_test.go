@@ -0,0 +1,132 @@
+package akeneo
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestProductValidator() *ProductValidator {
+	return NewProductValidator().
+		AddAttributes(
+			&Attribute{Code: "sku", Type_: AkeneoTypeIdentifier},
+			&Attribute{Code: "name", Type_: AkeneoTypeText, Localizable: true, MaxCharacters: 10},
+			&Attribute{Code: "ean", Type_: AkeneoTypeText, ValidationRule: ValidationRuleRegexp, ValidationRegexp: "/^[0-9]{13}$/"},
+			&Attribute{Code: "reference", Type_: AkeneoTypeText, ValidationRule: ValidationRuleRegexp, ValidationRegexp: "/^ref-[a-z]+$/i"},
+			&Attribute{Code: "contact", Type_: AkeneoTypeText, ValidationRule: ValidationRuleEmail},
+			&Attribute{Code: "weight", Type_: AkeneoTypeMetric, MetricFamily: "Weight", DecimalsAllowed: true},
+			&Attribute{Code: "price", Type_: AkeneoTypePriceCollection, Scopable: true, DecimalsAllowed: true},
+			&Attribute{Code: "brand", Type_: AkeneoTypeReferenceEntity},
+			&Attribute{Code: "picture", Type_: AkeneoTypeImage, AllowedExtensions: []string{"jpg", "png"}},
+		).
+		AddFamilies(&Family{
+			Code:                  "shoes",
+			Attributes:            []string{"sku", "name", "ean", "reference", "contact", "weight", "price", "brand", "picture"},
+			AttributeRequirements: map[string][]string{"ecommerce": {"sku", "name", "price"}},
+		}).
+		AddChannels(&Channel{Code: "ecommerce", Locales: []string{"en_US", "fr_FR"}}).
+		AddMeasureFamilies(&MeasureFamily{Code: "Weight", Units: []*FamilyUnit{{Code: "KILOGRAM"}, {Code: "GRAM"}}})
+}
+
+func violationMessages(violations Violations) string {
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.String()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func TestProductValidatorAcceptsValidProduct(t *testing.T) {
+	product := &Product{Identifier: "sku-1", FamilyCode: "shoes"}
+	product.Values.SetText("name", "", "en_US", "Shoe")
+	product.Values.SetText("ean", "", "", "1234567890123")
+	product.Values.SetText("reference", "", "", "REF-abc")
+	product.Values.SetText("contact", "", "", "shop@example.com")
+	product.Values.SetMetric("weight", "", "", MetricData{Amount: "1.5", Unit: "GRAM"})
+	product.Values.SetPrices("price", "ecommerce", "", []PriceData{{Amount: "10.50", Currency: "EUR"}})
+	product.Values.SetReferenceData("brand", "", "", "acme")
+	product.Values.SetFile("picture", "", "", "a/b/shoe.PNG")
+
+	if violations := newTestProductValidator().ValidateProduct(product); len(violations) > 0 {
+		t.Errorf("unexpected violations:\n%s", violationMessages(violations))
+	}
+}
+
+func TestProductValidatorReportsViolations(t *testing.T) {
+	product := &Product{Identifier: "sku-1", FamilyCode: "shoes"}
+	product.Values.SetText("name", "", "", "A name that is too long")
+	product.Values.SetText("ean", "", "", "12345")
+	product.Values.SetText("contact", "", "", "not an email")
+	product.Values.SetMetric("weight", "", "", MetricData{Amount: "1", Unit: "METER"})
+	product.Values.SetPrices("price", "mobile", "", []PriceData{{Amount: "1", Currency: "EUR"}, {Amount: "2", Currency: "EUR"}})
+	product.Values.SetMultiSelect("brand", "", "", []string{"acme"})
+	product.Values.SetFile("picture", "", "", "shoe.gif")
+	product.Values.SetText("color", "", "", "red")
+
+	messages := violationMessages(newTestProductValidator().ValidateProduct(product))
+
+	expected := []string{
+		"name: the attribute is localizable, a locale is required",
+		"name: the text is longer than 10 characters",
+		"ean: the text does not match /^[0-9]{13}$/",
+		"contact: the text is not a valid email address",
+		`weight: the unit "METER" does not belong to the measure family "Weight"`,
+		"price [channel mobile]: the channel does not exist",
+		"price [channel mobile]: the currency EUR is given twice",
+		"brand: the data must be an option code",
+		"picture: the file extension must be one of jpg, png",
+		"color: the attribute does not exist",
+	}
+	for _, message := range expected {
+		if !strings.Contains(messages, message) {
+			t.Errorf("%q not reported in:\n%s", message, messages)
+		}
+	}
+}
+
+func TestProductValidatorReportsUnsupportedRegexp(t *testing.T) {
+	validator := NewProductValidator().AddAttributes(&Attribute{Code: "code", Type_: AkeneoTypeText, ValidationRule: ValidationRuleRegexp, ValidationRegexp: "/^[a-z ]+$/x"})
+
+	product := &Product{Identifier: "sku-1"}
+	product.Values.SetText("code", "", "", "abc")
+
+	messages := violationMessages(validator.ValidateProduct(product))
+	if !strings.Contains(messages, "the text cannot be checked") || !strings.Contains(messages, "unsupported flag") {
+		t.Errorf("expected the pattern to be reported, got:\n%s", messages)
+	}
+}
+
+func TestProductValidatorChecksRequirements(t *testing.T) {
+	validator := newTestProductValidator()
+	validator.CheckRequirements = true
+
+	product := &Product{Identifier: "sku-1", FamilyCode: "shoes"}
+	product.Values.SetText("sku", "", "", "sku-1")
+	product.Values.SetText("name", "", "en_US", "Shoe")
+
+	messages := violationMessages(validator.ValidateProduct(product))
+
+	expected := []string{
+		`name [channel ecommerce] [locale fr_FR]: a value is required by the family "shoes"`,
+		`price [channel ecommerce]: a value is required by the family "shoes"`,
+	}
+	for _, message := range expected {
+		if !strings.Contains(messages, message) {
+			t.Errorf("%q not reported in:\n%s", message, messages)
+		}
+	}
+	if strings.Contains(messages, "[locale en_US]") {
+		t.Errorf("the en_US name was reported missing:\n%s", messages)
+	}
+}
+
+func TestProductValidatorProductModel(t *testing.T) {
+	validator := newTestProductValidator().AddFamilyVariants("shoes", &FamilyVariant{Code: "shoes_by_size"})
+
+	if violations := validator.ValidateProductModel(&ProductModel{Code: "model", FamilyVariant: "unknown"}); len(violations) != 1 {
+		t.Errorf("expected the unknown family variant, got:\n%s", violationMessages(violations))
+	}
+	if err := validator.ValidateProductModel(&ProductModel{FamilyVariant: "shoes_by_size"}).Err(); err == nil || !strings.Contains(err.Error(), "the code is required") {
+		t.Errorf("expected the missing code, got %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package akeneo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNoValue = errors.New("akeneo: no value for this attribute, scope and locale")
+
+// ProductValues are the values of a product or a product model, keyed by
+// attribute code. The accessors take the channel (scope) and locale to read
+// or write; pass "" for an attribute that is not scopable or not localizable.
+type ProductValues map[string][]*ProductAttributeValue
+
+// MetricData is the data of a metric value. Akeneo sends decimal amounts as
+// strings, so the amount is kept as a json.Number to stay exact.
+type MetricData struct {
+	Amount json.Number `json:"amount"`
+	Unit   string      `json:"unit"`
+}
+
+// PriceData is one price of a price collection value.
+type PriceData struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// TableRow is a row of a table value, keyed by column code.
+type TableRow map[string]interface{}
+
+// Value returns the value of attribute that applies to scope and locale. As in
+// Akeneo, a value without scope applies to every channel and a value without
+// locale to every locale; an exact match wins over those.
+func (values ProductValues) Value(attribute string, scope string, locale string) *ProductAttributeValue {
+	var fallback *ProductAttributeValue
+
+	for _, value := range values[attribute] {
+		if value == nil || !appliesTo(value.Scope, scope) || !appliesTo(value.Locale, locale) {
+			continue
+		}
+
+		if sameContext(value.Scope, scope) && sameContext(value.Locale, locale) {
+			return value
+		}
+
+		if fallback == nil {
+			fallback = value
+		}
+	}
+
+	return fallback
+}
+
+// Text reads a text, textarea or identifier value.
+func (values ProductValues) Text(attribute string, scope string, locale string) (string, error) {
+	var text string
+	err := values.decode(attribute, scope, locale, "text", &text)
+
+	return text, err
+}
+
+func (values ProductValues) Number(attribute string, scope string, locale string) (json.Number, error) {
+	var number json.Number
+	err := values.decode(attribute, scope, locale, "number", &number)
+
+	return number, err
+}
+
+func (values ProductValues) Metric(attribute string, scope string, locale string) (*MetricData, error) {
+	metric := &MetricData{}
+	if err := values.decode(attribute, scope, locale, "metric", metric); err != nil {
+		return nil, err
+	}
+
+	return metric, nil
+}
+
+func (values ProductValues) Prices(attribute string, scope string, locale string) ([]PriceData, error) {
+	var prices []PriceData
+	err := values.decode(attribute, scope, locale, "price collection", &prices)
+
+	return prices, err
+}
+
+// Price returns the price in currency of a price collection value.
+func (values ProductValues) Price(attribute string, scope string, locale string, currency string) (*PriceData, error) {
+	prices, err := values.Prices(attribute, scope, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range prices {
+		if prices[i].Currency == currency {
+			return &prices[i], nil
+		}
+	}
+
+	return nil, ErrNoValue
+}
+
+func (values ProductValues) Boolean(attribute string, scope string, locale string) (bool, error) {
+	var boolean bool
+	err := values.decode(attribute, scope, locale, "boolean", &boolean)
+
+	return boolean, err
+}
+
+// Date reads a date value, sent by Akeneo as an ISO 8601 date time.
+func (values ProductValues) Date(attribute string, scope string, locale string) (time.Time, error) {
+	var date string
+	if err := values.decode(attribute, scope, locale, "date", &date); err != nil {
+		return time.Time{}, err
+	}
+
+	parsed, ok := parseDate(date)
+	if !ok {
+		return time.Time{}, fmt.Errorf("akeneo: value of %q is not a date: %q", attribute, date)
+	}
+
+	return parsed, nil
+}
+
+// SimpleSelect returns the option code of a simple select value.
+func (values ProductValues) SimpleSelect(attribute string, scope string, locale string) (string, error) {
+	var option string
+	err := values.decode(attribute, scope, locale, "simple select", &option)
+
+	return option, err
+}
+
+// MultiSelect returns the option codes of a multi select value.
+func (values ProductValues) MultiSelect(attribute string, scope string, locale string) ([]string, error) {
+	var options []string
+	err := values.decode(attribute, scope, locale, "multi select", &options)
+
+	return options, err
+}
+
+// File returns the media file code of an image or file value.
+func (values ProductValues) File(attribute string, scope string, locale string) (string, error) {
+	var file string
+	err := values.decode(attribute, scope, locale, "file", &file)
+
+	return file, err
+}
+
+// ReferenceData returns the code of a reference data simple select value.
+func (values ProductValues) ReferenceData(attribute string, scope string, locale string) (string, error) {
+	var code string
+	err := values.decode(attribute, scope, locale, "reference data", &code)
+
+	return code, err
+}
+
+// ReferenceDataMulti returns the codes of a reference data multi select value.
+func (values ProductValues) ReferenceDataMulti(attribute string, scope string, locale string) ([]string, error) {
+	var codes []string
+	err := values.decode(attribute, scope, locale, "reference data collection", &codes)
+
+	return codes, err
+}
+
+// AssetCollection returns the asset codes of an asset collection value.
+func (values ProductValues) AssetCollection(attribute string, scope string, locale string) ([]string, error) {
+	var codes []string
+	err := values.decode(attribute, scope, locale, "asset collection", &codes)
+
+	return codes, err
+}
+
+func (values ProductValues) Table(attribute string, scope string, locale string) ([]TableRow, error) {
+	var rows []TableRow
+	err := values.decode(attribute, scope, locale, "table", &rows)
+
+	return rows, err
+}
+
+// Set replaces the value of attribute for exactly scope and locale, or adds
+// it. The typed setters below should be preferred.
+func (values *ProductValues) Set(attribute string, scope string, locale string, data interface{}) {
+	if *values == nil {
+		*values = ProductValues{}
+	}
+
+	for _, value := range (*values)[attribute] {
+		if value != nil && sameContext(value.Scope, scope) && sameContext(value.Locale, locale) {
+			value.Data = data
+			return
+		}
+	}
+
+	value := &ProductAttributeValue{Data: data}
+	if scope != "" {
+		value.Scope = &scope
+	}
+	if locale != "" {
+		value.Locale = &locale
+	}
+
+	(*values)[attribute] = append((*values)[attribute], value)
+}
+
+func (values *ProductValues) SetText(attribute string, scope string, locale string, text string) {
+	values.Set(attribute, scope, locale, text)
+}
+
+func (values *ProductValues) SetNumber(attribute string, scope string, locale string, number json.Number) {
+	values.Set(attribute, scope, locale, number)
+}
+
+func (values *ProductValues) SetMetric(attribute string, scope string, locale string, metric MetricData) {
+	values.Set(attribute, scope, locale, metric)
+}
+
+func (values *ProductValues) SetPrices(attribute string, scope string, locale string, prices []PriceData) {
+	values.Set(attribute, scope, locale, prices)
+}
+
+func (values *ProductValues) SetBoolean(attribute string, scope string, locale string, boolean bool) {
+	values.Set(attribute, scope, locale, boolean)
+}
+
+func (values *ProductValues) SetDate(attribute string, scope string, locale string, date time.Time) {
+	values.Set(attribute, scope, locale, date.Format(time.RFC3339))
+}
+
+func (values *ProductValues) SetSimpleSelect(attribute string, scope string, locale string, option string) {
+	values.Set(attribute, scope, locale, option)
+}
+
+func (values *ProductValues) SetMultiSelect(attribute string, scope string, locale string, options []string) {
+	values.Set(attribute, scope, locale, options)
+}
+
+func (values *ProductValues) SetFile(attribute string, scope string, locale string, file string) {
+	values.Set(attribute, scope, locale, file)
+}
+
+func (values *ProductValues) SetReferenceData(attribute string, scope string, locale string, code string) {
+	values.Set(attribute, scope, locale, code)
+}
+
+func (values *ProductValues) SetReferenceDataMulti(attribute string, scope string, locale string, codes []string) {
+	values.Set(attribute, scope, locale, codes)
+}
+
+func (values *ProductValues) SetAssetCollection(attribute string, scope string, locale string, codes []string) {
+	values.Set(attribute, scope, locale, codes)
+}
+
+func (values *ProductValues) SetTable(attribute string, scope string, locale string, rows []TableRow) {
+	values.Set(attribute, scope, locale, rows)
+}
+
+// decode converts the data of a value, either as decoded from JSON or as
+// given to a setter, into dst.
+func (values ProductValues) decode(attribute string, scope string, locale string, kind string, dst interface{}) error {
+	value := values.Value(attribute, scope, locale)
+	if value == nil || value.Data == nil {
+		return ErrNoValue
+	}
+
+	if err := decodeData(value.Data, dst); err != nil {
+		return fmt.Errorf("akeneo: value of %q is not a %s: %s", attribute, kind, err)
+	}
+
+	return nil
+}
+
+func decodeData(data interface{}, dst interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(encoded, dst); err != nil {
+		return fmt.Errorf("cannot read %s", encoded)
+	}
+
+	return nil
+}
+
+func parseDate(date string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if parsed, err := time.Parse(layout, date); err == nil {
+			return parsed, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func appliesTo(context *string, wanted string) bool {
+	return context == nil || *context == wanted
+}
+
+func sameContext(context *string, wanted string) bool {
+	if context == nil {
+		return wanted == ""
+	}
+
+	return *context == wanted
+}
@@ -0,0 +1,126 @@
+package akeneo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func decodeValues(t *testing.T, data string) ProductValues {
+	var product Product
+	if err := json.Unmarshal([]byte(`{"identifier":"sku-1","values":`+data+`}`), &product); err != nil {
+		t.Fatal(err)
+	}
+
+	return product.Values
+}
+
+func TestProductValuesReadDecodedJson(t *testing.T) {
+	values := decodeValues(t, `{
+		"name": [
+			{"scope": null, "locale": "en_US", "data": "Shoe"},
+			{"scope": null, "locale": "fr_FR", "data": "Chaussure"}
+		],
+		"weight": [{"scope": null, "locale": null, "data": {"amount": "1.2500", "unit": "KILOGRAM"}}],
+		"price": [{"scope": null, "locale": null, "data": [{"amount": "10.50", "currency": "EUR"}, {"amount": "12.00", "currency": "USD"}]}],
+		"release": [{"scope": "ecommerce", "locale": null, "data": "2021-03-04T00:00:00+00:00"}],
+		"colors": [{"scope": null, "locale": null, "data": ["red", "blue"]}],
+		"enabled": [{"scope": null, "locale": null, "data": true}]
+	}`)
+
+	if name, err := values.Text("name", "", "fr_FR"); err != nil || name != "Chaussure" {
+		t.Errorf("unexpected name %q, %v", name, err)
+	}
+
+	weight, err := values.Metric("weight", "ecommerce", "en_US")
+	if err != nil || weight.Amount != "1.2500" || weight.Unit != "KILOGRAM" {
+		t.Errorf("unexpected weight %+v, %v", weight, err)
+	}
+
+	price, err := values.Price("price", "", "", "USD")
+	if err != nil || price.Amount != "12.00" {
+		t.Errorf("unexpected price %+v, %v", price, err)
+	}
+	if _, err := values.Price("price", "", "", "GBP"); err != ErrNoValue {
+		t.Errorf("expected ErrNoValue for a missing currency, got %v", err)
+	}
+
+	release, err := values.Date("release", "ecommerce", "")
+	if err != nil || !release.Equal(time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected release %v, %v", release, err)
+	}
+	if _, err := values.Date("release", "mobile", ""); err != ErrNoValue {
+		t.Errorf("expected ErrNoValue on another channel, got %v", err)
+	}
+
+	if colors, err := values.MultiSelect("colors", "", ""); err != nil || len(colors) != 2 || colors[1] != "blue" {
+		t.Errorf("unexpected colors %v, %v", colors, err)
+	}
+	if enabled, err := values.Boolean("enabled", "", ""); err != nil || !enabled {
+		t.Errorf("unexpected enabled %v, %v", enabled, err)
+	}
+}
+
+func TestProductValuesPreferExactContext(t *testing.T) {
+	values := decodeValues(t, `{"description": [
+		{"scope": null, "locale": null, "data": "Any"},
+		{"scope": "ecommerce", "locale": "en_US", "data": "Ecommerce"}
+	]}`)
+
+	if text, _ := values.Text("description", "ecommerce", "en_US"); text != "Ecommerce" {
+		t.Errorf("expected the exact value, got %q", text)
+	}
+	if text, _ := values.Text("description", "mobile", "de_DE"); text != "Any" {
+		t.Errorf("expected the global value, got %q", text)
+	}
+}
+
+func TestProductValuesReportWrongKind(t *testing.T) {
+	values := decodeValues(t, `{"name": [{"scope": null, "locale": null, "data": "Shoe"}]}`)
+
+	if _, err := values.Metric("name", "", ""); err == nil || err == ErrNoValue {
+		t.Errorf("expected a decoding error, got %v", err)
+	}
+	if _, err := values.Date("name", "", ""); err == nil {
+		t.Error("expected an error for a text read as a date")
+	}
+	if _, err := values.Text("missing", "", ""); err != ErrNoValue {
+		t.Errorf("expected ErrNoValue, got %v", err)
+	}
+}
+
+func TestProductValuesSetters(t *testing.T) {
+	var values ProductValues
+
+	values.SetText("name", "", "en_US", "Shoe")
+	values.SetText("name", "", "en_US", "Boot")
+	values.SetText("name", "", "fr_FR", "Botte")
+	values.SetNumber("size", "", "", json.Number("42"))
+	values.SetPrices("price", "", "", []PriceData{{Amount: "10.50", Currency: "EUR"}})
+	values.SetDate("release", "ecommerce", "", time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC))
+	values.SetTable("sizes", "", "", []TableRow{{"eu": "42", "us": "9"}})
+
+	if count := len(values["name"]); count != 2 {
+		t.Errorf("expected 2 name values, got %d", count)
+	}
+	if name, _ := values.Text("name", "", "en_US"); name != "Boot" {
+		t.Errorf("expected the value to be replaced, got %q", name)
+	}
+	if size, err := values.Number("size", "", ""); err != nil || size != "42" {
+		t.Errorf("unexpected size %q, %v", size, err)
+	}
+	if release, err := values.Date("release", "ecommerce", ""); err != nil || release.Day() != 4 {
+		t.Errorf("unexpected release %v, %v", release, err)
+	}
+	if rows, err := values.Table("sizes", "", ""); err != nil || len(rows) != 1 || rows[0]["us"] != "9" {
+		t.Errorf("unexpected rows %v, %v", rows, err)
+	}
+
+	encoded, err := json.Marshal(values["price"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `[{"scope":null,"locale":null,"data":[{"amount":10.50,"currency":"EUR"}]}]`; string(encoded) != expected {
+		t.Errorf("unexpected JSON %s", encoded)
+	}
+}
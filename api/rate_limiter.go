@@ -0,0 +1,200 @@
+package akeneo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type RateLimitConfig struct {
+	RequestsPerSecond float64
+	Burst             int
+	MaxConcurrent     int
+}
+
+type RateLimiterStats struct {
+	Tokens    float64
+	InFlight  int
+	Waiting   int
+	NextDelay time.Duration
+	TotalWait time.Duration
+	LastWait  time.Duration
+}
+
+// RateLimiter is a token bucket shared by every request sent through a Client.
+// MaxConcurrent additionally caps the number of requests whose response body
+// has not been closed yet.
+type RateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	tokens    float64
+	last      time.Time
+	slots     chan struct{}
+	waiting   int
+	totalWait time.Duration
+	lastWait  time.Duration
+}
+
+func NewRateLimiter(config *RateLimitConfig) *RateLimiter {
+	burst := config.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	rl := &RateLimiter{
+		rate:   config.RequestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+
+	if config.MaxConcurrent > 0 {
+		rl.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+
+	return rl
+}
+
+// Wait blocks until the request may be sent. The returned release function
+// frees the concurrency slot and must be called once the request is done.
+func (rl *RateLimiter) Wait(ctx context.Context) (release func(), err error) {
+	started := time.Now()
+
+	rl.mu.Lock()
+	rl.waiting++
+	rl.mu.Unlock()
+
+	defer func() {
+		rl.mu.Lock()
+		rl.waiting--
+		if err == nil {
+			rl.lastWait = time.Since(started)
+			rl.totalWait += rl.lastWait
+		}
+		rl.mu.Unlock()
+	}()
+
+	release = func() {}
+
+	if rl.slots != nil {
+		select {
+		case rl.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		var once sync.Once
+		release = func() {
+			once.Do(func() { <-rl.slots })
+		}
+	}
+
+	delay := rl.reserve()
+	if delay <= 0 {
+		return release, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return release, nil
+	case <-ctx.Done():
+		rl.cancelReservation()
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// Delay returns how long a request issued now would wait for a token.
+func (rl *RateLimiter) Delay() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	return rl.delayFor(rl.refill(time.Now()))
+}
+
+func (rl *RateLimiter) Stats() RateLimiterStats {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	tokens := rl.refill(time.Now())
+
+	return RateLimiterStats{
+		Tokens:    tokens,
+		InFlight:  len(rl.slots),
+		Waiting:   rl.waiting,
+		NextDelay: rl.delayFor(tokens),
+		TotalWait: rl.totalWait,
+		LastWait:  rl.lastWait,
+	}
+}
+
+func (rl *RateLimiter) reserve() time.Duration {
+	if rl.rate <= 0 {
+		return 0
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	rl.tokens = rl.refill(now)
+	rl.last = now
+	rl.tokens--
+
+	return rl.delayFor(rl.tokens + 1)
+}
+
+func (rl *RateLimiter) cancelReservation() {
+	if rl.rate <= 0 {
+		return
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.tokens++
+}
+
+func (rl *RateLimiter) refill(now time.Time) float64 {
+	tokens := rl.tokens + now.Sub(rl.last).Seconds()*rl.rate
+	if tokens > rl.burst {
+		tokens = rl.burst
+	}
+
+	return tokens
+}
+
+func (rl *RateLimiter) delayFor(tokens float64) time.Duration {
+	if rl.rate <= 0 || tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - tokens) / rl.rate * float64(time.Second))
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+
+	return err
+}
+
+func holdUntilClosed(response *http.Response, release func()) {
+	if response == nil || response.Body == nil {
+		release()
+		return
+	}
+
+	response.Body = &releaseOnClose{ReadCloser: response.Body, release: release}
+}
@@ -0,0 +1,109 @@
+package akeneo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurstThenRate(t *testing.T) {
+	limiter := NewRateLimiter(&RateLimitConfig{RequestsPerSecond: 20, Burst: 3})
+
+	started := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := limiter.Wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if elapsed := time.Since(started); elapsed > 20*time.Millisecond {
+		t.Fatalf("the burst should not wait, waited %s", elapsed)
+	}
+
+	if delay := limiter.Delay(); delay <= 0 || delay > 50*time.Millisecond {
+		t.Fatalf("expected a delay of about 50ms, got %s", delay)
+	}
+
+	release, err := limiter.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	if elapsed := time.Since(started); elapsed < 40*time.Millisecond {
+		t.Errorf("the fourth request should wait for a token, waited %s", elapsed)
+	}
+	if stats := limiter.Stats(); stats.LastWait <= 0 || stats.TotalWait < stats.LastWait {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRateLimiterCancelledWaitGivesTokenBack(t *testing.T) {
+	limiter := NewRateLimiter(&RateLimitConfig{RequestsPerSecond: 1, Burst: 1})
+
+	release, err := limiter.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+
+	if tokens := limiter.Stats().Tokens; tokens < 0 {
+		t.Errorf("the cancelled reservation was not given back, %f tokens", tokens)
+	}
+}
+
+func TestRateLimiterMaxConcurrent(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientConfig{
+		BaseUrl:       server.URL,
+		RateLimit:     &RateLimitConfig{MaxConcurrent: 2},
+		Authenticator: NewStaticTokenAuth("token"),
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := client.DoRequest(http.MethodGet, "products", client.getHeadersForRequest(), nil, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			response.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxInFlight)
+	}
+	if stats := client.RateLimiter().Stats(); stats.InFlight != 0 {
+		t.Errorf("expected every slot to be released, %d in flight", stats.InFlight)
+	}
+}
@@ -0,0 +1,174 @@
+package akeneo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxRetries  int
+	MinDelay    time.Duration
+	MaxDelay    time.Duration
+	Methods     []string
+	StatusCodes []int
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: 3,
+		MinDelay:   time.Millisecond * 500,
+		MaxDelay:   time.Second * 30,
+		Methods:    []string{http.MethodGet, http.MethodPatch},
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func (rp *RetryPolicy) allowsMethod(method string) bool {
+	for _, m := range rp.Methods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (rp *RetryPolicy) shouldRetry(attempt int, method string, response *http.Response, err error) bool {
+	if rp == nil || attempt >= rp.MaxRetries || !rp.allowsMethod(method) {
+		return false
+	}
+
+	if err != nil {
+		return isTransientError(err)
+	}
+
+	for _, code := range rp.StatusCodes {
+		if response.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// delay returns the time to wait before the next attempt. A Retry-After
+// header sent by the PIM takes precedence over the exponential backoff.
+func (rp *RetryPolicy) delay(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if wait, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			if rp.MaxDelay > 0 && wait > rp.MaxDelay {
+				return rp.MaxDelay
+			}
+			return wait
+		}
+	}
+
+	backoff := rp.backoff(attempt)
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// backoff doubles MinDelay once per attempt, stopping at MaxDelay, or before
+// the delay overflows when there is no MaxDelay.
+func (rp *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := rp.MinDelay
+	if backoff <= 0 {
+		return rp.MaxDelay
+	}
+
+	for i := 0; i < attempt; i++ {
+		if rp.MaxDelay > 0 && backoff >= rp.MaxDelay {
+			break
+		}
+		if backoff > math.MaxInt64/2 {
+			return time.Duration(math.MaxInt64)
+		}
+		backoff *= 2
+	}
+
+	if rp.MaxDelay > 0 && backoff > rp.MaxDelay {
+		return rp.MaxDelay
+	}
+
+	return backoff
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+func waitForRetry(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func discardBody(response *http.Response) {
+	if response == nil || response.Body == nil {
+		return
+	}
+
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+	_ = response.Body.Close()
+}
@@ -0,0 +1,143 @@
+package akeneo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(server *httptest.Server, policy *RetryPolicy) *Client {
+	return NewClient(&ClientConfig{
+		BaseUrl:       server.URL,
+		RetryPolicy:   policy,
+		Authenticator: NewStaticTokenAuth("token"),
+	})
+}
+
+func TestRetryPolicyBackoffIsCapped(t *testing.T) {
+	policy := &RetryPolicy{MinDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second}
+
+	for attempt := 0; attempt < 200; attempt++ {
+		backoff := policy.backoff(attempt)
+		if backoff < policy.MinDelay || backoff > policy.MaxDelay {
+			t.Fatalf("attempt %d: backoff %s out of bounds", attempt, backoff)
+		}
+	}
+
+	if backoff := policy.backoff(3); backoff != 4*time.Second {
+		t.Errorf("expected 4s, got %s", backoff)
+	}
+}
+
+func TestRetryPolicyBackoffDoesNotOverflowWithoutMaxDelay(t *testing.T) {
+	policy := &RetryPolicy{MinDelay: time.Second}
+
+	previous := time.Duration(0)
+	for attempt := 0; attempt < 200; attempt++ {
+		backoff := policy.backoff(attempt)
+		if backoff < previous {
+			t.Fatalf("attempt %d: backoff %s decreased from %s", attempt, backoff, previous)
+		}
+		previous = backoff
+	}
+
+	if delay := policy.delay(100, nil); delay <= 0 {
+		t.Errorf("expected a positive delay, got %s", delay)
+	}
+}
+
+func TestRetryPolicyRetryAfter(t *testing.T) {
+	policy := &RetryPolicy{MinDelay: time.Millisecond, MaxDelay: 10 * time.Second}
+
+	response := &http.Response{Header: http.Header{}}
+	response.Header.Set("Retry-After", "3")
+	if delay := policy.delay(0, response); delay != 3*time.Second {
+		t.Errorf("expected 3s, got %s", delay)
+	}
+
+	response.Header.Set("Retry-After", "120")
+	if delay := policy.delay(0, response); delay != policy.MaxDelay {
+		t.Errorf("expected the delay to be capped at %s, got %s", policy.MaxDelay, delay)
+	}
+
+	response.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if delay := policy.delay(0, response); delay != 0 {
+		t.Errorf("expected no delay for a past date, got %s", delay)
+	}
+}
+
+func TestClientRetriesRateLimitedRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.MinDelay = time.Millisecond
+	client := newTestClient(server, policy)
+
+	response, err := client.DoRequest(http.MethodGet, "products", client.getHeadersForRequest(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("expected a success after 2 calls, got %d after %d", response.StatusCode, calls)
+	}
+}
+
+func TestClientDoesNotRetryPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.MinDelay = time.Millisecond
+	client := newTestClient(server, policy)
+
+	response, err := client.DoRequest(http.MethodPost, "products", client.getHeadersForRequest(), []byte("{}"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestClientGivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.MinDelay = time.Millisecond
+	policy.MaxDelay = time.Millisecond
+	client := newTestClient(server, policy)
+
+	response, err := client.DoRequest(http.MethodGet, "products", client.getHeadersForRequest(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusBadGateway || atomic.LoadInt32(&calls) != int32(policy.MaxRetries+1) {
+		t.Errorf("expected %d calls ending with 502, got %d ending with %d", policy.MaxRetries+1, calls, response.StatusCode)
+	}
+}
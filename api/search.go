@@ -0,0 +1,469 @@
+package akeneo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type SearchOperator string
+
+const (
+	OperatorIn                         SearchOperator = "IN"
+	OperatorNotIn                      SearchOperator = "NOT IN"
+	OperatorInChildren                 SearchOperator = "IN CHILDREN"
+	OperatorNotInChildren              SearchOperator = "NOT IN CHILDREN"
+	OperatorInOrUnclassified           SearchOperator = "IN OR UNCLASSIFIED"
+	OperatorUnclassified               SearchOperator = "UNCLASSIFIED"
+	OperatorEqual                      SearchOperator = "="
+	OperatorNotEqual                   SearchOperator = "!="
+	OperatorLower                      SearchOperator = "<"
+	OperatorLowerOrEqual               SearchOperator = "<="
+	OperatorGreater                    SearchOperator = ">"
+	OperatorGreaterOrEqual             SearchOperator = ">="
+	OperatorEmpty                      SearchOperator = "EMPTY"
+	OperatorNotEmpty                   SearchOperator = "NOT EMPTY"
+	OperatorBetween                    SearchOperator = "BETWEEN"
+	OperatorNotBetween                 SearchOperator = "NOT BETWEEN"
+	OperatorSinceLastNDays             SearchOperator = "SINCE LAST N DAYS"
+	OperatorStartsWith                 SearchOperator = "STARTS WITH"
+	OperatorContains                   SearchOperator = "CONTAINS"
+	OperatorDoesNotContain             SearchOperator = "DOES NOT CONTAIN"
+	OperatorGreaterOnAllLocales        SearchOperator = "GREATER THAN ON ALL LOCALES"
+	OperatorGreaterOrEqualOnAllLocales SearchOperator = "GREATER OR EQUALS THAN ON ALL LOCALES"
+	OperatorLowerOnAllLocales          SearchOperator = "LOWER THAN ON ALL LOCALES"
+	OperatorLowerOrEqualOnAllLocales   SearchOperator = "LOWER OR EQUALS THAN ON ALL LOCALES"
+)
+
+const (
+	searchDateFormat    = "2006-01-02 15:04:05"
+	attributeDateFormat = "2006-01-02"
+)
+
+var (
+	codeListOperators     = []SearchOperator{OperatorIn, OperatorNotIn, OperatorEmpty, OperatorNotEmpty}
+	comparisonOperators   = []SearchOperator{OperatorLower, OperatorLowerOrEqual, OperatorEqual, OperatorNotEqual, OperatorGreater, OperatorGreaterOrEqual, OperatorEmpty, OperatorNotEmpty}
+	textOperators         = []SearchOperator{OperatorStartsWith, OperatorContains, OperatorDoesNotContain, OperatorEqual, OperatorNotEqual, OperatorEmpty, OperatorNotEmpty}
+	dateOperators         = []SearchOperator{OperatorLower, OperatorEqual, OperatorNotEqual, OperatorGreater, OperatorBetween, OperatorNotBetween, OperatorEmpty, OperatorNotEmpty}
+	categoryOperators     = []SearchOperator{OperatorIn, OperatorNotIn, OperatorInOrUnclassified, OperatorInChildren, OperatorNotInChildren, OperatorUnclassified}
+	timestampOperators    = []SearchOperator{OperatorEqual, OperatorNotEqual, OperatorLower, OperatorGreater, OperatorBetween, OperatorNotBetween, OperatorSinceLastNDays}
+	completenessOperators = []SearchOperator{OperatorLower, OperatorLowerOrEqual, OperatorEqual, OperatorNotEqual, OperatorGreater, OperatorGreaterOrEqual, OperatorGreaterOnAllLocales, OperatorGreaterOrEqualOnAllLocales, OperatorLowerOnAllLocales, OperatorLowerOrEqualOnAllLocales}
+)
+
+// AttributeOperators lists the search operators Akeneo accepts for each
+// attribute type. Table and product link attributes cannot be searched
+// through Search.Attribute: Akeneo filters tables on one of their columns
+// and has no filter on product links.
+var AttributeOperators = map[string][]SearchOperator{
+	AkeneoTypeIdentifier:      append([]SearchOperator{OperatorIn, OperatorNotIn}, textOperators...),
+	AkeneoTypeText:            textOperators,
+	AkeneoTypeTextArea:        textOperators,
+	AkeneoTypeNumber:          comparisonOperators,
+	AkeneoTypeMetric:          comparisonOperators,
+	AkeneoTypePriceCollection: comparisonOperators,
+	AkeneoTypeBoolean:         {OperatorEqual, OperatorNotEqual},
+	AkeneoTypeSimpleSelect:    codeListOperators,
+	AkeneoTypeMultiSelect:     codeListOperators,
+	AkeneoTypeDate:            dateOperators,
+	AkeneoTypeImage:           textOperators,
+	AkeneoTypeFile:            textOperators,
+
+	AkeneoTypeReferenceDataSimpleSelect: codeListOperators,
+	AkeneoTypeReferenceDataMultiSelect:  codeListOperators,
+	AkeneoTypeAssetCollection:           codeListOperators,
+	AkeneoTypeReferenceEntity:           codeListOperators,
+	AkeneoTypeReferenceEntityCollection: codeListOperators,
+}
+
+// SearchFilter is one condition of the `search` query parameter.
+type SearchFilter struct {
+	Operator SearchOperator `json:"operator"`
+	Value    interface{}    `json:"value,omitempty"`
+	Scope    string         `json:"scope,omitempty"`
+	Locale   string         `json:"locale,omitempty"`
+	Locales  []string       `json:"locales,omitempty"`
+}
+
+// AttributeCondition filters on the value of an attribute. Type is one of
+// the AkeneoType constants and decides which operators and values are valid.
+type AttributeCondition struct {
+	Code     string
+	Type     string
+	Operator SearchOperator
+	Value    interface{}
+	Scope    string
+	Locale   string
+}
+
+// MetricValue and PriceValue are the values of metric and price conditions.
+type MetricValue struct {
+	Amount interface{} `json:"amount"`
+	Unit   string      `json:"unit"`
+}
+
+type PriceValue struct {
+	Amount   interface{} `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// Search builds the `search` parameter of the product and product model
+// lists. Invalid conditions are reported by Encode and Apply, so calls can
+// be chained:
+//
+//	opts, err := akeneo.NewSearch().
+//		Family(akeneo.OperatorIn, "shoes").
+//		UpdatedSinceLastNDays(4).
+//		Apply(akeneo.RequestOpts{"limit": "100"})
+//
+// The zero value is an empty search ready to use.
+type Search struct {
+	filters map[string][]*SearchFilter
+	locale  string
+	scope   string
+	errs    []string
+}
+
+func NewSearch() *Search {
+	return &Search{filters: map[string][]*SearchFilter{}}
+}
+
+// Locale sets search_locale, the default locale of localizable attribute
+// conditions.
+func (s *Search) Locale(locale string) *Search {
+	s.locale = locale
+	return s
+}
+
+// Scope sets search_scope, the default channel of scopable attribute
+// conditions.
+func (s *Search) Scope(scope string) *Search {
+	s.scope = scope
+	return s
+}
+
+// Identifier filters on the product identifier. IN and NOT IN take a list
+// of identifiers, the text operators a single one.
+func (s *Search) Identifier(operator SearchOperator, identifiers ...string) *Search {
+	operators := AttributeOperators[AkeneoTypeIdentifier]
+	switch operator {
+	case OperatorIn, OperatorNotIn, OperatorEmpty, OperatorNotEmpty:
+		return s.add("identifier", operator, operators, codesValue(operator, identifiers))
+	}
+
+	if !s.check("identifier", operator, operators) {
+		return s
+	}
+
+	if len(identifiers) != 1 {
+		s.errs = append(s.errs, fmt.Sprintf("identifier: operator %q takes a single identifier", operator))
+		return s
+	}
+
+	return s.add("identifier", operator, operators, identifiers[0])
+}
+
+func (s *Search) Family(operator SearchOperator, codes ...string) *Search {
+	return s.add("family", operator, codeListOperators, codesValue(operator, codes))
+}
+
+func (s *Search) Categories(operator SearchOperator, codes ...string) *Search {
+	return s.add("categories", operator, categoryOperators, codesValue(operator, codes))
+}
+
+func (s *Search) Groups(operator SearchOperator, codes ...string) *Search {
+	return s.add("groups", operator, codeListOperators, codesValue(operator, codes))
+}
+
+// Parent filters on the parent product model. With OperatorEqual a single
+// code is expected.
+func (s *Search) Parent(operator SearchOperator, codes ...string) *Search {
+	operators := []SearchOperator{OperatorEqual, OperatorIn, OperatorEmpty, OperatorNotEmpty}
+	if operator == OperatorEqual && len(codes) == 1 {
+		return s.add("parent", operator, operators, codes[0])
+	}
+
+	return s.add("parent", operator, operators, codesValue(operator, codes))
+}
+
+func (s *Search) Enabled(enabled bool) *Search {
+	return s.add("enabled", OperatorEqual, []SearchOperator{OperatorEqual}, enabled)
+}
+
+// Completeness filters on the completeness percentage on a channel. The
+// "ON ALL LOCALES" operators need at least one locale.
+func (s *Search) Completeness(operator SearchOperator, percent int, scope string, locales ...string) *Search {
+	if !s.check("completeness", operator, completenessOperators) {
+		return s
+	}
+
+	if scope == "" {
+		s.errs = append(s.errs, "completeness: a scope is required")
+		return s
+	}
+
+	if strings.HasSuffix(string(operator), "ON ALL LOCALES") && len(locales) == 0 {
+		s.errs = append(s.errs, fmt.Sprintf("completeness: operator %q needs locales", operator))
+		return s
+	}
+
+	return s.filter("completeness", &SearchFilter{Operator: operator, Value: percent, Scope: scope, Locales: locales})
+}
+
+// Updated compares the update date with one date, or with two for the
+// BETWEEN operators.
+func (s *Search) Updated(operator SearchOperator, dates ...time.Time) *Search {
+	return s.timestamp("updated", operator, dates)
+}
+
+func (s *Search) UpdatedSinceLastNDays(days int) *Search {
+	return s.add("updated", OperatorSinceLastNDays, timestampOperators, days)
+}
+
+func (s *Search) Created(operator SearchOperator, dates ...time.Time) *Search {
+	return s.timestamp("created", operator, dates)
+}
+
+func (s *Search) CreatedSinceLastNDays(days int) *Search {
+	return s.add("created", OperatorSinceLastNDays, timestampOperators, days)
+}
+
+func (s *Search) timestamp(property string, operator SearchOperator, dates []time.Time) *Search {
+	if operator == OperatorSinceLastNDays {
+		s.errs = append(s.errs, fmt.Sprintf("%s: operator %q takes a number of days, not dates", property, operator))
+		return s
+	}
+
+	values := make([]string, 0, len(dates))
+	for _, date := range dates {
+		values = append(values, date.Format(searchDateFormat))
+	}
+
+	if operator == OperatorBetween || operator == OperatorNotBetween {
+		if len(values) != 2 {
+			s.errs = append(s.errs, fmt.Sprintf("%s: operator %q needs two dates", property, operator))
+			return s
+		}
+
+		return s.add(property, operator, timestampOperators, values)
+	}
+
+	if len(values) != 1 {
+		s.errs = append(s.errs, fmt.Sprintf("%s: operator %q needs one date", property, operator))
+		return s
+	}
+
+	return s.add(property, operator, timestampOperators, values[0])
+}
+
+// Attribute adds a condition on an attribute value, checking the operator
+// and the shape of the value against the attribute type.
+func (s *Search) Attribute(condition AttributeCondition) *Search {
+	operators, ok := AttributeOperators[condition.Type]
+	if !ok {
+		s.errs = append(s.errs, fmt.Sprintf("%s: unsupported attribute type %q", condition.Code, condition.Type))
+		return s
+	}
+
+	if !s.check(condition.Code, condition.Operator, operators) {
+		return s
+	}
+
+	value, err := attributeValue(condition)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Sprintf("%s: %v", condition.Code, err))
+		return s
+	}
+
+	return s.filter(condition.Code, &SearchFilter{
+		Operator: condition.Operator,
+		Value:    value,
+		Scope:    condition.Scope,
+		Locale:   condition.Locale,
+	})
+}
+
+func attributeValue(condition AttributeCondition) (interface{}, error) {
+	operator, value := condition.Operator, condition.Value
+
+	if operator == OperatorEmpty || operator == OperatorNotEmpty {
+		if value != nil {
+			return nil, fmt.Errorf("operator %q takes no value", operator)
+		}
+		return nil, nil
+	}
+
+	if value == nil {
+		return nil, fmt.Errorf("operator %q needs a value", operator)
+	}
+
+	switch condition.Type {
+	case AkeneoTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return nil, fmt.Errorf("expects a boolean, %T given", value)
+		}
+	case AkeneoTypeNumber:
+		if !isNumber(value) {
+			return nil, fmt.Errorf("expects a number, %T given", value)
+		}
+	case AkeneoTypeMetric:
+		if _, ok := value.(MetricValue); !ok {
+			return nil, fmt.Errorf("expects a MetricValue, %T given", value)
+		}
+	case AkeneoTypePriceCollection:
+		if _, ok := value.(PriceValue); !ok {
+			return nil, fmt.Errorf("expects a PriceValue, %T given", value)
+		}
+	case AkeneoTypeSimpleSelect, AkeneoTypeMultiSelect:
+		if _, ok := value.([]string); !ok {
+			return nil, fmt.Errorf("expects a list of option codes, %T given", value)
+		}
+	case AkeneoTypeReferenceDataSimpleSelect, AkeneoTypeReferenceDataMultiSelect, AkeneoTypeAssetCollection, AkeneoTypeReferenceEntity, AkeneoTypeReferenceEntityCollection:
+		if _, ok := value.([]string); !ok {
+			return nil, fmt.Errorf("expects a list of codes, %T given", value)
+		}
+	case AkeneoTypeIdentifier:
+		if operator == OperatorIn || operator == OperatorNotIn {
+			if _, ok := value.([]string); !ok {
+				return nil, fmt.Errorf("expects a list of identifiers, %T given", value)
+			}
+			return value, nil
+		}
+		fallthrough
+	case AkeneoTypeText, AkeneoTypeTextArea, AkeneoTypeImage, AkeneoTypeFile:
+		if _, ok := value.(string); !ok {
+			return nil, fmt.Errorf("expects a string, %T given", value)
+		}
+	case AkeneoTypeDate:
+		return dateValue(operator, value)
+	}
+
+	return value, nil
+}
+
+func dateValue(operator SearchOperator, value interface{}) (interface{}, error) {
+	if operator == OperatorBetween || operator == OperatorNotBetween {
+		dates, ok := value.([]time.Time)
+		if !ok || len(dates) != 2 {
+			return nil, fmt.Errorf("operator %q expects two dates", operator)
+		}
+		return []string{dates[0].Format(attributeDateFormat), dates[1].Format(attributeDateFormat)}, nil
+	}
+
+	date, ok := value.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("expects a time.Time, %T given", value)
+	}
+
+	return date.Format(attributeDateFormat), nil
+}
+
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int32, int64, float32, float64, json.Number:
+		return true
+	}
+
+	return false
+}
+
+func codesValue(operator SearchOperator, codes []string) interface{} {
+	switch operator {
+	case OperatorEmpty, OperatorNotEmpty, OperatorUnclassified:
+		return nil
+	}
+
+	return codes
+}
+
+func (s *Search) check(property string, operator SearchOperator, allowed []SearchOperator) bool {
+	for _, candidate := range allowed {
+		if candidate == operator {
+			return true
+		}
+	}
+
+	s.errs = append(s.errs, fmt.Sprintf("%s: unsupported operator %q", property, operator))
+
+	return false
+}
+
+func (s *Search) add(property string, operator SearchOperator, allowed []SearchOperator, value interface{}) *Search {
+	if !s.check(property, operator, allowed) {
+		return s
+	}
+
+	if codes, ok := value.([]string); ok && len(codes) == 0 {
+		s.errs = append(s.errs, fmt.Sprintf("%s: operator %q needs at least one value", property, operator))
+		return s
+	}
+
+	return s.filter(property, &SearchFilter{Operator: operator, Value: value})
+}
+
+func (s *Search) filter(property string, filter *SearchFilter) *Search {
+	if s.filters == nil {
+		s.filters = map[string][]*SearchFilter{}
+	}
+
+	s.filters[property] = append(s.filters[property], filter)
+
+	return s
+}
+
+// Err returns the problems found in the conditions added so far.
+func (s *Search) Err() error {
+	if len(s.errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("akeneo: invalid search: %s", strings.Join(s.errs, "; "))
+}
+
+// Encode returns the JSON value of the `search` parameter.
+func (s *Search) Encode() (string, error) {
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+
+	filters := s.filters
+	if filters == nil {
+		filters = map[string][]*SearchFilter{}
+	}
+
+	var b strings.Builder
+
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(filters); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// Apply returns a copy of opts with search, search_locale and search_scope
+// set.
+func (s *Search) Apply(opts RequestOpts) (RequestOpts, error) {
+	search, err := s.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := RequestOpts{}
+	for key, value := range opts {
+		applied[key] = value
+	}
+
+	if len(s.filters) > 0 {
+		applied["search"] = search
+	}
+	if s.locale != "" {
+		applied["search_locale"] = s.locale
+	}
+	if s.scope != "" {
+		applied["search_scope"] = s.scope
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,165 @@
+package akeneo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchEncode(t *testing.T) {
+	search := NewSearch().
+		Family(OperatorIn, "shoes", "boots").
+		Enabled(true).
+		Completeness(OperatorGreaterOrEqual, 90, "ecommerce").
+		Updated(OperatorBetween, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)).
+		Attribute(AttributeCondition{Code: "name", Type: AkeneoTypeText, Operator: OperatorStartsWith, Value: "Red <b>", Locale: "en_US", Scope: "ecommerce"})
+
+	encoded, err := search.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"completeness":[{"operator":">=","value":90,"scope":"ecommerce"}],` +
+		`"enabled":[{"operator":"=","value":true}],` +
+		`"family":[{"operator":"IN","value":["shoes","boots"]}],` +
+		`"name":[{"operator":"STARTS WITH","value":"Red <b>","scope":"ecommerce","locale":"en_US"}],` +
+		`"updated":[{"operator":"BETWEEN","value":["2020-01-02 03:04:05","2020-02-01 00:00:00"]}]}`
+	if encoded != expected {
+		t.Errorf("unexpected search\n got: %s\nwant: %s", encoded, expected)
+	}
+}
+
+func TestSearchEmptyOperatorsTakeNoValue(t *testing.T) {
+	encoded, err := NewSearch().
+		Categories(OperatorUnclassified).
+		Attribute(AttributeCondition{Code: "color", Type: AkeneoTypeSimpleSelect, Operator: OperatorEmpty}).
+		Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"categories":[{"operator":"UNCLASSIFIED"}],"color":[{"operator":"EMPTY"}]}`
+	if encoded != expected {
+		t.Errorf("unexpected search %s", encoded)
+	}
+}
+
+func TestSearchAttributeValues(t *testing.T) {
+	date := time.Date(2021, 5, 6, 12, 0, 0, 0, time.UTC)
+
+	valid := []AttributeCondition{
+		{Code: "weight", Type: AkeneoTypeMetric, Operator: OperatorGreater, Value: MetricValue{Amount: 2, Unit: "KILOGRAM"}},
+		{Code: "price", Type: AkeneoTypePriceCollection, Operator: OperatorLower, Value: PriceValue{Amount: 9.99, Currency: "EUR"}},
+		{Code: "size", Type: AkeneoTypeNumber, Operator: OperatorEqual, Value: 42},
+		{Code: "release", Type: AkeneoTypeDate, Operator: OperatorGreater, Value: date},
+		{Code: "sku", Type: AkeneoTypeIdentifier, Operator: OperatorIn, Value: []string{"a", "b"}},
+		{Code: "brand", Type: AkeneoTypeReferenceDataSimpleSelect, Operator: OperatorIn, Value: []string{"acme"}},
+		{Code: "fabrics", Type: AkeneoTypeReferenceDataMultiSelect, Operator: OperatorNotIn, Value: []string{"wool"}},
+		{Code: "packshots", Type: AkeneoTypeAssetCollection, Operator: OperatorIn, Value: []string{"front"}},
+		{Code: "packshots", Type: AkeneoTypeAssetCollection, Operator: OperatorNotEmpty},
+	}
+	for _, condition := range valid {
+		if err := NewSearch().Attribute(condition).Err(); err != nil {
+			t.Errorf("%s %s: %v", condition.Type, condition.Operator, err)
+		}
+	}
+
+	invalid := []AttributeCondition{
+		{Code: "size", Type: AkeneoTypeNumber, Operator: OperatorEqual, Value: "42"},
+		{Code: "size", Type: AkeneoTypeNumber, Operator: OperatorStartsWith, Value: 42},
+		{Code: "name", Type: AkeneoTypeText, Operator: OperatorEmpty, Value: "x"},
+		{Code: "name", Type: AkeneoTypeText, Operator: OperatorEqual},
+		{Code: "release", Type: AkeneoTypeDate, Operator: OperatorBetween, Value: []time.Time{date}},
+		{Code: "brand", Type: AkeneoTypeReferenceDataSimpleSelect, Operator: OperatorIn, Value: "acme"},
+		{Code: "packshots", Type: AkeneoTypeAssetCollection, Operator: OperatorContains, Value: []string{"front"}},
+		{Code: "sizes", Type: AkeneoTypeTable, Operator: OperatorNotEmpty},
+		{Code: "related", Type: AkeneoTypeProductLink, Operator: OperatorNotEmpty},
+	}
+	for _, condition := range invalid {
+		if err := NewSearch().Attribute(condition).Err(); err == nil {
+			t.Errorf("%s %s %v: expected an error", condition.Type, condition.Operator, condition.Value)
+		}
+	}
+}
+
+func TestSearchIdentifier(t *testing.T) {
+	encoded, err := NewSearch().
+		Identifier(OperatorStartsWith, "boot-").
+		Identifier(OperatorNotIn, "boot-1", "boot-2").
+		Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"identifier":[{"operator":"STARTS WITH","value":"boot-"},{"operator":"NOT IN","value":["boot-1","boot-2"]}]}`
+	if encoded != expected {
+		t.Errorf("unexpected search %s", encoded)
+	}
+
+	for _, operator := range []SearchOperator{OperatorStartsWith, OperatorContains, OperatorDoesNotContain, OperatorEqual, OperatorNotEqual} {
+		if err := NewSearch().Identifier(operator, "a", "b").Err(); err == nil {
+			t.Errorf("expected an error for several identifiers with %q", operator)
+		}
+		if err := NewSearch().Identifier(operator).Err(); err == nil {
+			t.Errorf("expected an error for no identifier with %q", operator)
+		}
+	}
+}
+
+func TestSearchZeroValue(t *testing.T) {
+	var search Search
+	if encoded, err := search.Encode(); err != nil || encoded != "{}" {
+		t.Errorf("unexpected empty search %s, %v", encoded, err)
+	}
+
+	encoded, err := new(Search).
+		Family(OperatorIn, "shoes").
+		Completeness(OperatorEqual, 100, "ecommerce").
+		Attribute(AttributeCondition{Code: "name", Type: AkeneoTypeText, Operator: OperatorNotEmpty}).
+		Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"completeness":[{"operator":"=","value":100,"scope":"ecommerce"}],"family":[{"operator":"IN","value":["shoes"]}],"name":[{"operator":"NOT EMPTY"}]}`
+	if encoded != expected {
+		t.Errorf("unexpected search %s", encoded)
+	}
+}
+
+func TestSearchReportsEveryProblem(t *testing.T) {
+	err := NewSearch().
+		Family(OperatorIn).
+		Completeness(OperatorGreaterOnAllLocales, 100, "ecommerce").
+		Created(OperatorSinceLastNDays, time.Now()).
+		Err()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, property := range []string{"family:", "completeness:", "created:"} {
+		if !strings.Contains(err.Error(), property) {
+			t.Errorf("%q not reported in %v", property, err)
+		}
+	}
+}
+
+func TestSearchApply(t *testing.T) {
+	opts := RequestOpts{"limit": "10"}
+
+	applied, err := NewSearch().Locale("fr_FR").Scope("ecommerce").Enabled(false).Apply(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if applied["search"] != `{"enabled":[{"operator":"=","value":false}]}` || applied["search_locale"] != "fr_FR" || applied["search_scope"] != "ecommerce" || applied["limit"] != "10" {
+		t.Errorf("unexpected options %v", applied)
+	}
+	if _, ok := opts["search"]; ok {
+		t.Error("Apply changed the given options")
+	}
+
+	if _, err := NewSearch().Family("LIKE", "shoes").Apply(opts); err == nil {
+		t.Error("expected an error for an unsupported operator")
+	}
+}
@@ -0,0 +1,230 @@
+package akeneo
+
+import "context"
+
+// The interfaces below describe the method set of each resource API so that
+// code using Api can be given test doubles, such as those of the akeneotest
+// package, instead of a client talking to a PIM.
+
+// ProductService is implemented by ProductApi.
+type ProductService interface {
+	GetAll(opts QueryOptions) (*ProductsResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*ProductsResponse, *ApiError)
+	Iterate(opts QueryOptions) *ProductIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *ProductIterator
+	Get(code string) (*Product, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*Product, *ApiError)
+	Create(product *Product) *ApiError
+	CreateWithContext(ctx context.Context, product *Product) *ApiError
+	Upsert(product *Product) *ApiError
+	UpsertWithContext(ctx context.Context, product *Product) *ApiError
+	BatchUpsert(products []*Product) ([]*ResponseBody, *ApiError)
+	BatchUpsertWithContext(ctx context.Context, products []*Product) ([]*ResponseBody, *ApiError)
+	Delete(code string) *ApiError
+	DeleteWithContext(ctx context.Context, code string) *ApiError
+}
+
+// ProductModelService is implemented by ProductModelApi.
+type ProductModelService interface {
+	GetAll(opts QueryOptions) (*ProductModelResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*ProductModelResponse, *ApiError)
+	Iterate(opts QueryOptions) *ProductModelIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *ProductModelIterator
+	Get(code string) (*ProductModel, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*ProductModel, *ApiError)
+	Create(productModel *ProductModel) *ApiError
+	CreateWithContext(ctx context.Context, productModel *ProductModel) *ApiError
+	Upsert(productModel *ProductModel) *ApiError
+	UpsertWithContext(ctx context.Context, productModel *ProductModel) *ApiError
+	BatchUpsert(productModels []*ProductModel) ([]*ResponseBody, *ApiError)
+	BatchUpsertWithContext(ctx context.Context, productModels []*ProductModel) ([]*ResponseBody, *ApiError)
+}
+
+// MediaFileService is implemented by MediaFileApi.
+type MediaFileService interface {
+	GetAll(opts QueryOptions) (*ProductMediaFileResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*ProductMediaFileResponse, *ApiError)
+	Iterate(opts QueryOptions) *MediaFileIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *MediaFileIterator
+	Get(code string) (*MediaFile, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*MediaFile, *ApiError)
+	Create(mediaFile *MediaFileBody) *ApiError
+	CreateWithContext(ctx context.Context, mediaFile *MediaFileBody) *ApiError
+	Download(code string, folderPath string) *ApiError
+	DownloadWithContext(ctx context.Context, code string, folderPath string) *ApiError
+}
+
+// FamilyService is implemented by FamilyApi.
+type FamilyService interface {
+	GetAll(opts QueryOptions) (*FamiliesResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*FamiliesResponse, *ApiError)
+	Iterate(opts QueryOptions) *FamilyIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *FamilyIterator
+	Get(code string) (*Family, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*Family, *ApiError)
+	Create(family *Family) *ApiError
+	CreateWithContext(ctx context.Context, family *Family) *ApiError
+	Upsert(family *Family) *ApiError
+	UpsertWithContext(ctx context.Context, family *Family) *ApiError
+	BatchUpsert(families []*Family) ([]*ResponseBody, *ApiError)
+	BatchUpsertWithContext(ctx context.Context, families []*Family) ([]*ResponseBody, *ApiError)
+}
+
+// FamilyVariantService is implemented by FamilyVariantApi.
+type FamilyVariantService interface {
+	GetAll(familyCode string, opts QueryOptions) (*FamilyVariantsResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, familyCode string, opts QueryOptions) (*FamilyVariantsResponse, *ApiError)
+	Iterate(familyCode string, opts QueryOptions) *FamilyVariantIterator
+	IterateWithContext(ctx context.Context, familyCode string, opts QueryOptions) *FamilyVariantIterator
+	Get(familyCode string, variantCode string) (*FamilyVariant, *ApiError)
+	GetWithContext(ctx context.Context, familyCode string, variantCode string) (*FamilyVariant, *ApiError)
+	Create(familyCode string, variant *FamilyVariant) *ApiError
+	CreateWithContext(ctx context.Context, familyCode string, variant *FamilyVariant) *ApiError
+	Upsert(familyCode string, variant *FamilyVariant) *ApiError
+	UpsertWithContext(ctx context.Context, familyCode string, variant *FamilyVariant) *ApiError
+	BatchUpsert(familyCode string, variants []*FamilyVariant) ([]*ResponseBody, *ApiError)
+	BatchUpsertWithContext(ctx context.Context, familyCode string, variants []*FamilyVariant) ([]*ResponseBody, *ApiError)
+}
+
+// AttributeService is implemented by AttributeApi.
+type AttributeService interface {
+	GetAll(opts QueryOptions) (*AttributesResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*AttributesResponse, *ApiError)
+	Iterate(opts QueryOptions) *AttributeIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *AttributeIterator
+	Get(code string) (*Attribute, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*Attribute, *ApiError)
+	Create(attribute *Attribute) *ApiError
+	CreateWithContext(ctx context.Context, attribute *Attribute) *ApiError
+	Upsert(attribute *Attribute) *ApiError
+	UpsertWithContext(ctx context.Context, attribute *Attribute) *ApiError
+	BatchUpsert(attributes []*Attribute) ([]*ResponseBody, *ApiError)
+	BatchUpsertWithContext(ctx context.Context, attributes []*Attribute) ([]*ResponseBody, *ApiError)
+}
+
+// AttributeOptionService is implemented by AttributeOptionApi.
+type AttributeOptionService interface {
+	GetAll(attributeCode string, opts QueryOptions) (*AttributeOptionsResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, attributeCode string, opts QueryOptions) (*AttributeOptionsResponse, *ApiError)
+	Iterate(attributeCode string, opts QueryOptions) *AttributeOptionIterator
+	IterateWithContext(ctx context.Context, attributeCode string, opts QueryOptions) *AttributeOptionIterator
+	Get(attributeCode string, optionCode string) (*AttributeOption, *ApiError)
+	GetWithContext(ctx context.Context, attributeCode string, optionCode string) (*AttributeOption, *ApiError)
+	Create(attributeCode string, attributeOption *AttributeOption) *ApiError
+	CreateWithContext(ctx context.Context, attributeCode string, attributeOption *AttributeOption) *ApiError
+	Upsert(attributeCode string, attributeOption *AttributeOption) *ApiError
+	UpsertWithContext(ctx context.Context, attributeCode string, attributeOption *AttributeOption) *ApiError
+	BatchUpsert(attributeCode string, attributeOptions []*AttributeOption) ([]*ResponseBody, *ApiError)
+	BatchUpsertWithContext(ctx context.Context, attributeCode string, attributeOptions []*AttributeOption) ([]*ResponseBody, *ApiError)
+}
+
+// AttributeGroupService is implemented by AttributeGroupApi.
+type AttributeGroupService interface {
+	GetAll(opts QueryOptions) (*AttributeGroupsResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*AttributeGroupsResponse, *ApiError)
+	Iterate(opts QueryOptions) *AttributeGroupIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *AttributeGroupIterator
+	Get(code string) (*AttributeGroup, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*AttributeGroup, *ApiError)
+	Create(group *AttributeGroup) *ApiError
+	CreateWithContext(ctx context.Context, group *AttributeGroup) *ApiError
+	Upsert(group *AttributeGroup) *ApiError
+	UpsertWithContext(ctx context.Context, group *AttributeGroup) *ApiError
+	BatchUpsert(groups []*AttributeGroup) ([]*ResponseBody, *ApiError)
+	BatchUpsertWithContext(ctx context.Context, groups []*AttributeGroup) ([]*ResponseBody, *ApiError)
+}
+
+// AssociationTypeService is implemented by AssociationTypeApi.
+type AssociationTypeService interface {
+	GetAll(opts QueryOptions) (*AssociationTypeResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*AssociationTypeResponse, *ApiError)
+	Iterate(opts QueryOptions) *AssociationTypeIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *AssociationTypeIterator
+	Get(code string) (*AssociationType, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*AssociationType, *ApiError)
+	Create(associationType *AssociationType) *ApiError
+	CreateWithContext(ctx context.Context, associationType *AssociationType) *ApiError
+	Upsert(associationType *AssociationType) *ApiError
+	UpsertWithContext(ctx context.Context, associationType *AssociationType) *ApiError
+	BatchUpsert(associationTypes []*AssociationType) ([]*ResponseBody, *ApiError)
+	BatchUpsertWithContext(ctx context.Context, associationTypes []*AssociationType) ([]*ResponseBody, *ApiError)
+}
+
+// CategoryService is implemented by CategoriesApi.
+type CategoryService interface {
+	GetAll(opts QueryOptions) (*CategoriesResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*CategoriesResponse, *ApiError)
+	Iterate(opts QueryOptions) *CategoryIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *CategoryIterator
+	Get(code string) (*Category, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*Category, *ApiError)
+	Create(category *Category) *ApiError
+	CreateWithContext(ctx context.Context, category *Category) *ApiError
+	Upsert(category *Category) *ApiError
+	UpsertWithContext(ctx context.Context, category *Category) *ApiError
+	BatchUpsert(categories []*Category) ([]*ResponseBody, *ApiError)
+	BatchUpsertWithContext(ctx context.Context, categories []*Category) ([]*ResponseBody, *ApiError)
+}
+
+// ChannelService is implemented by ChannelApi.
+type ChannelService interface {
+	GetAll(opts QueryOptions) (*ChannelResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*ChannelResponse, *ApiError)
+	Iterate(opts QueryOptions) *ChannelIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *ChannelIterator
+	Get(code string) (*Channel, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*Channel, *ApiError)
+	Create(channel *Channel) *ApiError
+	CreateWithContext(ctx context.Context, channel *Channel) *ApiError
+	Upsert(channel *Channel) *ApiError
+	UpsertWithContext(ctx context.Context, channel *Channel) *ApiError
+	BatchUpsert(channels []*Channel) ([]*ResponseBody, *ApiError)
+	BatchUpsertWithContext(ctx context.Context, channels []*Channel) ([]*ResponseBody, *ApiError)
+}
+
+// LocaleService is implemented by LocaleApi.
+type LocaleService interface {
+	GetAll(opts QueryOptions) (*LocaleResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*LocaleResponse, *ApiError)
+	Iterate(opts QueryOptions) *LocaleIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *LocaleIterator
+	Get(code string) (*Locale, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*Locale, *ApiError)
+}
+
+// CurrencyService is implemented by CurrencyApi.
+type CurrencyService interface {
+	GetAll(opts QueryOptions) (*CurrencyResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*CurrencyResponse, *ApiError)
+	Iterate(opts QueryOptions) *CurrencyIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *CurrencyIterator
+	Get(code string) (*Currency, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*Currency, *ApiError)
+}
+
+// MeasureFamilyService is implemented by MeasureFamilyApi.
+type MeasureFamilyService interface {
+	GetAll(opts QueryOptions) (*MeasureFamilyResponse, *ApiError)
+	GetAllWithContext(ctx context.Context, opts QueryOptions) (*MeasureFamilyResponse, *ApiError)
+	Iterate(opts QueryOptions) *MeasureFamilyIterator
+	IterateWithContext(ctx context.Context, opts QueryOptions) *MeasureFamilyIterator
+	Get(code string) (*MeasureFamily, *ApiError)
+	GetWithContext(ctx context.Context, code string) (*MeasureFamily, *ApiError)
+}
+
+var (
+	_ ProductService         = (*ProductApi)(nil)
+	_ ProductModelService    = (*ProductModelApi)(nil)
+	_ MediaFileService       = (*MediaFileApi)(nil)
+	_ FamilyService          = (*FamilyApi)(nil)
+	_ FamilyVariantService   = (*FamilyVariantApi)(nil)
+	_ AttributeService       = (*AttributeApi)(nil)
+	_ AttributeOptionService = (*AttributeOptionApi)(nil)
+	_ AttributeGroupService  = (*AttributeGroupApi)(nil)
+	_ AssociationTypeService = (*AssociationTypeApi)(nil)
+	_ CategoryService        = (*CategoriesApi)(nil)
+	_ ChannelService         = (*ChannelApi)(nil)
+	_ LocaleService          = (*LocaleApi)(nil)
+	_ CurrencyService        = (*CurrencyApi)(nil)
+	_ MeasureFamilyService   = (*MeasureFamilyApi)(nil)
+)
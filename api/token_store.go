@@ -0,0 +1,269 @@
+package akeneo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TokenStore keeps the OAuth token shared by every ClientAuth pointing at it.
+// Lock serialises renewals so that only one holder asks Akeneo for a new
+// token while the others wait and then reuse it.
+type TokenStore interface {
+	Load() (*Token, error)
+	Save(token *Token) error
+	Lock(ctx context.Context) (unlock func(), err error)
+}
+
+type MemoryTokenStore struct {
+	mu    sync.RWMutex
+	token *Token
+	lock  chan struct{}
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{lock: make(chan struct{}, 1)}
+}
+
+func (ms *MemoryTokenStore) Load() (*Token, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.token, nil
+}
+
+func (ms *MemoryTokenStore) Save(token *Token) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.token = token
+
+	return nil
+}
+
+func (ms *MemoryTokenStore) Lock(ctx context.Context) (func(), error) {
+	select {
+	case ms.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { <-ms.lock })
+	}, nil
+}
+
+const (
+	DefaultTokenLockStaleAfter = time.Second * 30
+	tokenLockPollInterval      = time.Millisecond * 50
+)
+
+// FileTokenStore persists the token as JSON so that several processes on the
+// same host share it. Writes go to a temporary file renamed over the target,
+// and renewals are guarded by a "<path>.lock" file created exclusively. A
+// lock file not refreshed for StaleAfter is considered abandoned.
+type FileTokenStore struct {
+	Path       string
+	StaleAfter time.Duration
+}
+
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path, StaleAfter: DefaultTokenLockStaleAfter}
+}
+
+func (fs *FileTokenStore) Load() (*Token, error) {
+	data, err := ioutil.ReadFile(fs.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	token := &Token{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (fs *FileTokenStore) Save(token *Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(fs.Path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, filepath.Base(fs.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), fs.Path)
+}
+
+// Lock creates the lock file holding a token unique to this call. A stale
+// lock is moved aside before being removed, so that two processes cannot
+// both take it over, and the lock file is only removed on unlock while it
+// still holds our token. While the lock is held its modification time is
+// refreshed, so that a slow renewal is not taken for an abandoned one.
+func (fs *FileTokenStore) Lock(ctx context.Context) (func(), error) {
+	lockPath := fs.Path + ".lock"
+
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0700); err != nil {
+		return nil, err
+	}
+
+	owner, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = file.WriteString(owner)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				releaseLock(lockPath, owner)
+				return nil, err
+			}
+
+			return fs.holdLock(lockPath, owner), nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if holder, stale := fs.staleLock(lockPath); stale {
+			releaseLock(lockPath, holder)
+			continue
+		}
+
+		if err := waitForRetry(ctx, tokenLockPollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// holdLock keeps the lock fresh until the returned unlock function is called.
+func (fs *FileTokenStore) holdLock(lockPath string, owner string) func() {
+	done := make(chan struct{})
+
+	if fs.StaleAfter > 0 {
+		go func() {
+			ticker := time.NewTicker(fs.StaleAfter / 3)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					if holder, err := readLock(lockPath); err == nil && holder == owner {
+						now := time.Now()
+						os.Chtimes(lockPath, now, now)
+					}
+				}
+			}
+		}()
+	}
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			close(done)
+			releaseLock(lockPath, owner)
+		})
+	}
+}
+
+// staleLock returns the token of the lock file when it is older than
+// StaleAfter.
+func (fs *FileTokenStore) staleLock(lockPath string) (string, bool) {
+	if fs.StaleAfter <= 0 {
+		return "", false
+	}
+
+	info, err := os.Stat(lockPath)
+	if err != nil || time.Since(info.ModTime()) <= fs.StaleAfter {
+		return "", false
+	}
+
+	holder, err := readLock(lockPath)
+	if err != nil {
+		return "", false
+	}
+
+	return holder, true
+}
+
+// releaseLock removes the lock file if it holds the token of holder. The
+// file is first renamed to a name of its own, which only one caller can
+// do, then checked: a lock taken meanwhile by someone else is put back.
+func releaseLock(lockPath string, holder string) {
+	moved := lockPath + "." + holder + ".release"
+	if err := os.Rename(lockPath, moved); err != nil {
+		return
+	}
+
+	if current, err := readLock(moved); err == nil && current != holder {
+		// os.Link does not replace a lock created in the meantime.
+		os.Link(moved, lockPath)
+	}
+
+	os.Remove(moved)
+}
+
+func readLock(lockPath string) (string, error) {
+	data, err := ioutil.ReadFile(lockPath)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func newLockToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d-%s", os.Getpid(), hex.EncodeToString(token)), nil
+}
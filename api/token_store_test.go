@@ -0,0 +1,217 @@
+package akeneo
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestFileTokenStoreSaveLoad(t *testing.T) {
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "token", "akeneo.json"))
+
+	if token, err := store.Load(); token != nil || err != nil {
+		t.Fatalf("expected no token, got %v, %v", token, err)
+	}
+
+	saved := &Token{Token: oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour).Round(time.Second)}, ExpiresIn: 3600}
+	if err := store.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.AccessToken != "access" || loaded.RefreshToken != "refresh" || !loaded.Expiry.Equal(saved.Expiry) {
+		t.Errorf("unexpected token %+v", loaded)
+	}
+}
+
+func TestFileTokenStoreLockIsExclusive(t *testing.T) {
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "akeneo.json"))
+
+	unlock, err := store.Lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := store.Lock(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the second lock to time out, got %v", err)
+	}
+
+	unlock()
+	unlock()
+
+	again, err := store.Lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	again()
+}
+
+func TestFileTokenStoreUnlockKeepsLockOfAnotherOwner(t *testing.T) {
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "akeneo.json"))
+	lockPath := store.Path + ".lock"
+
+	unlock, err := store.Lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The lock was taken over while this holder was stuck.
+	if err := ioutil.WriteFile(lockPath, []byte("other-owner"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	if holder, err := readLock(lockPath); err != nil || holder != "other-owner" {
+		t.Errorf("the lock of another owner was removed: %q, %v", holder, err)
+	}
+}
+
+func TestFileTokenStoreTakesOverStaleLock(t *testing.T) {
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "akeneo.json"))
+	store.StaleAfter = time.Second
+	lockPath := store.Path + ".lock"
+
+	if err := ioutil.WriteFile(lockPath, []byte("crashed-owner"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	os.Chtimes(lockPath, old, old)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	unlock, err := store.Lock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if holder, _ := readLock(lockPath); holder == "crashed-owner" || holder == "" {
+		t.Errorf("expected the lock to hold our token, got %q", holder)
+	}
+
+	unlock()
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("expected the lock to be removed, got %v", err)
+	}
+}
+
+func TestFileTokenStoreStaleLockHasOneNewOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "akeneo.json")
+	lockPath := path + ".lock"
+
+	if err := ioutil.WriteFile(lockPath, []byte("crashed-owner"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	os.Chtimes(lockPath, old, old)
+
+	var holders, maxHolders int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			store := NewFileTokenStore(path)
+			store.StaleAfter = time.Second
+
+			unlock, err := store.Lock(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			current := atomic.AddInt32(&holders, 1)
+			if current > atomic.LoadInt32(&maxHolders) {
+				atomic.StoreInt32(&maxHolders, current)
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+
+			unlock()
+		}()
+	}
+	wg.Wait()
+
+	if maxHolders != 1 {
+		t.Errorf("expected one holder at a time, got %d", maxHolders)
+	}
+}
+
+func TestFileTokenStoreLongRenewalKeepsLock(t *testing.T) {
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "akeneo.json"))
+	store.StaleAfter = 150 * time.Millisecond
+
+	unlock, err := store.Lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+
+	if _, err := store.Lock(ctx); err != context.DeadlineExceeded {
+		t.Errorf("a held lock was taken over as stale: %v", err)
+	}
+}
+
+func TestClientsShareTokenThroughFileStore(t *testing.T) {
+	ts := newTokenServer()
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "akeneo.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			client := NewClient(&ClientConfig{BaseUrl: ts.URL, ClientId: "client", SecretKey: "secret", TokenStore: NewFileTokenStore(path)})
+			if _, err := client.auth.GetToken(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if grants := ts.grantTypes(); grants != GrantTypePassword {
+		t.Errorf("expected a single password grant, got %s", grants)
+	}
+}
+
+func TestMemoryTokenStoreLock(t *testing.T) {
+	store := NewMemoryTokenStore()
+
+	unlock, err := store.Lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := store.Lock(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the second lock to time out, got %v", err)
+	}
+
+	unlock()
+	if again, err := store.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	} else {
+		again()
+	}
+}
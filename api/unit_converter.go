@@ -0,0 +1,273 @@
+package akeneo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultConversionDecimals is the number of decimals NewUnitConverter keeps
+// in converted amounts.
+const DefaultConversionDecimals = 12
+
+// UnitConverter converts amounts between the units of a measure family
+// using exact rational arithmetic: an amount is converted to the standard
+// unit of the family with the operations of its unit, then from it with the
+// inverse operations of the target unit.
+type UnitConverter struct {
+	// Decimals is the number of decimals of the amounts returned by
+	// Convert, rounded half away from zero. Trailing zeros are removed.
+	Decimals int
+
+	families map[string]*MeasureFamily
+}
+
+func NewUnitConverter(families ...*MeasureFamily) *UnitConverter {
+	converter := &UnitConverter{Decimals: DefaultConversionDecimals, families: map[string]*MeasureFamily{}}
+
+	for _, family := range families {
+		converter.families[family.Code] = family
+	}
+
+	return converter
+}
+
+// LoadUnitConverter fetches every measure family.
+func LoadUnitConverter(ctx context.Context, service MeasureFamilyService) (*UnitConverter, *ApiError) {
+	var families []*MeasureFamily
+
+	it := service.IterateWithContext(ctx, PageOptions{Limit: maxPageLimit})
+	for it.Next() {
+		family := it.Item().MeasureFamily
+		families = append(families, &family)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewUnitConverter(families...), nil
+}
+
+// ConvertRat converts amount exactly from a unit to another of a family.
+func (converter *UnitConverter) ConvertRat(family string, from string, to string, amount *big.Rat) (*big.Rat, error) {
+	measureFamily, known := converter.families[family]
+	if !known {
+		return nil, fmt.Errorf("akeneo: measure family %q does not exist", family)
+	}
+
+	fromUnit, err := familyUnit(measureFamily, from)
+	if err != nil {
+		return nil, err
+	}
+	toUnit, err := familyUnit(measureFamily, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(big.Rat).Set(amount)
+	if from == to {
+		return result, nil
+	}
+
+	operations, err := unitOperations(fromUnit)
+	if err != nil {
+		return nil, err
+	}
+	for _, operation := range operations {
+		if err := applyOperation(result, operation.operator, operation.value); err != nil {
+			return nil, err
+		}
+	}
+
+	operations, err = unitOperations(toUnit)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(operations) - 1; i >= 0; i-- {
+		if err := applyOperation(result, inverseOperators[operations[i].operator], operations[i].value); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Convert converts a decimal amount, such as the amount of a metric value.
+func (converter *UnitConverter) Convert(family string, from string, to string, amount json.Number) (json.Number, error) {
+	value, ok := new(big.Rat).SetString(amount.String())
+	if !ok {
+		return "", fmt.Errorf("akeneo: amount %q is not a number", amount)
+	}
+
+	converted, err := converter.ConvertRat(family, from, to, value)
+	if err != nil {
+		return "", err
+	}
+
+	return json.Number(formatDecimal(converted, converter.Decimals)), nil
+}
+
+// ConvertMetric converts the data of a metric value to a unit of family.
+func (converter *UnitConverter) ConvertMetric(family string, metric MetricData, to string) (MetricData, error) {
+	amount, err := converter.Convert(family, metric.Unit, to, metric.Amount)
+	if err != nil {
+		return MetricData{}, err
+	}
+
+	return MetricData{Amount: amount, Unit: to}, nil
+}
+
+// NormalizeValues returns a copy of values where the metric values of the
+// attributes listed in the conversion units of channel are converted to
+// those units. Values of other channels are copied unchanged.
+func (converter *UnitConverter) NormalizeValues(values ProductValues, channel *Channel) (ProductValues, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	normalized := ProductValues{}
+	for attribute, attributeValues := range values {
+		copied := make([]*ProductAttributeValue, 0, len(attributeValues))
+
+		for _, value := range attributeValues {
+			if value == nil {
+				copied = append(copied, nil)
+				continue
+			}
+
+			value := *value
+			unit, converted := channel.ConversionUnits[attribute]
+			if converted && value.Data != nil && (value.Scope == nil || *value.Scope == channel.Code) {
+				metric := MetricData{}
+				if err := decodeData(value.Data, &metric); err != nil || metric.Unit == "" {
+					return nil, fmt.Errorf("akeneo: value of %q is not a metric", attribute)
+				}
+
+				family, err := converter.unitFamily(metric.Unit, unit)
+				if err != nil {
+					return nil, fmt.Errorf("akeneo: value of %q: %s", attribute, strings.TrimPrefix(err.Error(), "akeneo: "))
+				}
+
+				if value.Data, err = converter.ConvertMetric(family, metric, unit); err != nil {
+					return nil, err
+				}
+			}
+
+			copied = append(copied, &value)
+		}
+
+		normalized[attribute] = copied
+	}
+
+	return normalized, nil
+}
+
+// NormalizeProduct returns a copy of product with its values normalized for
+// the export to channel.
+func (converter *UnitConverter) NormalizeProduct(product *Product, channel *Channel) (*Product, error) {
+	values, err := converter.NormalizeValues(product.Values, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := *product
+	normalized.Values = values
+
+	return &normalized, nil
+}
+
+// unitFamily returns the code of the measure family holding both units.
+func (converter *UnitConverter) unitFamily(from string, to string) (string, error) {
+	for code, family := range converter.families {
+		if _, err := familyUnit(family, from); err != nil {
+			continue
+		}
+		if _, err := familyUnit(family, to); err == nil {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("akeneo: no measure family has both units %q and %q", from, to)
+}
+
+var inverseOperators = map[string]string{
+	"add": "sub",
+	"sub": "add",
+	"mul": "div",
+	"div": "mul",
+}
+
+type conversionStep struct {
+	operator string
+	value    *big.Rat
+}
+
+func familyUnit(family *MeasureFamily, code string) (*FamilyUnit, error) {
+	for _, unit := range family.Units {
+		if unit != nil && unit.Code == code {
+			return unit, nil
+		}
+	}
+
+	return nil, fmt.Errorf("akeneo: unit %q does not belong to the measure family %q", code, family.Code)
+}
+
+// unitOperations returns the parsed operations converting unit to the
+// standard unit. The order of several operations in Convert is unknown, so
+// Operations must give it.
+func unitOperations(unit *FamilyUnit) ([]conversionStep, error) {
+	if len(unit.Operations) == 0 && len(unit.Convert) > 1 {
+		return nil, fmt.Errorf("akeneo: unit %q has %d conversion operations but no Operations giving their order", unit.Code, len(unit.Convert))
+	}
+
+	operations := unit.operations()
+
+	steps := make([]conversionStep, 0, len(operations))
+	for _, operation := range operations {
+		if _, known := inverseOperators[operation.Operator]; !known {
+			return nil, fmt.Errorf("akeneo: unit %q has an unknown conversion operator %q", unit.Code, operation.Operator)
+		}
+
+		value, ok := new(big.Rat).SetString(operation.Value)
+		if !ok {
+			return nil, fmt.Errorf("akeneo: unit %q has an invalid conversion value %q", unit.Code, operation.Value)
+		}
+
+		steps = append(steps, conversionStep{operator: operation.Operator, value: value})
+	}
+
+	return steps, nil
+}
+
+func applyOperation(result *big.Rat, operator string, value *big.Rat) error {
+	switch operator {
+	case "add":
+		result.Add(result, value)
+	case "sub":
+		result.Sub(result, value)
+	case "mul":
+		result.Mul(result, value)
+	case "div":
+		if value.Sign() == 0 {
+			return fmt.Errorf("akeneo: conversion divides by zero")
+		}
+		result.Quo(result, value)
+	}
+
+	return nil
+}
+
+// formatDecimal formats value with at most decimals decimals.
+func formatDecimal(value *big.Rat, decimals int) string {
+	formatted := value.FloatString(decimals)
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	}
+	if formatted == "-0" {
+		formatted = "0"
+	}
+
+	return formatted
+}
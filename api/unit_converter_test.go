@@ -0,0 +1,182 @@
+package akeneo
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const temperatureFamily = `{
+	"code": "Temperature",
+	"standard": "KELVIN",
+	"units": [
+		{"code": "KELVIN", "symbol": "K", "convert": {"mul": "1"}},
+		{"code": "CELSIUS", "symbol": "°C", "convert": {"add": "273.15"}},
+		{"code": "FAHRENHEIT", "symbol": "°F", "convert": {"sub": "32", "div": "1.8", "add": "273.15"}},
+		{"code": "REAUMUR", "symbol": "°r", "convert": {"mul": "1.25", "add": "273.15"}}
+	]
+}`
+
+func decodeMeasureFamily(t *testing.T, data string) *MeasureFamily {
+	family := &MeasureFamily{}
+	if err := json.Unmarshal([]byte(data), family); err != nil {
+		t.Fatal(err)
+	}
+
+	return family
+}
+
+func checkTemperatureConversions(t *testing.T, converter *UnitConverter) {
+	tests := []struct {
+		from   string
+		to     string
+		amount json.Number
+		result json.Number
+	}{
+		{"FAHRENHEIT", "KELVIN", "212", "373.15"},
+		{"KELVIN", "FAHRENHEIT", "273.15", "32"},
+		{"FAHRENHEIT", "CELSIUS", "-40", "-40"},
+		{"CELSIUS", "FAHRENHEIT", "100", "212"},
+		{"REAUMUR", "KELVIN", "80", "373.15"},
+		{"KELVIN", "REAUMUR", "273.15", "0"},
+		{"REAUMUR", "CELSIUS", "80", "100"},
+		{"CELSIUS", "REAUMUR", "100", "80"},
+		{"FAHRENHEIT", "REAUMUR", "212", "80"},
+		{"REAUMUR", "FAHRENHEIT", "80", "212"},
+	}
+
+	for _, test := range tests {
+		result, err := converter.Convert("Temperature", test.from, test.to, test.amount)
+		if err != nil {
+			t.Errorf("%s %s to %s: %v", test.amount, test.from, test.to, err)
+			continue
+		}
+		if result != test.result {
+			t.Errorf("%s %s to %s: expected %s, got %s", test.amount, test.from, test.to, test.result, result)
+		}
+	}
+}
+
+func TestUnitConverterTemperatures(t *testing.T) {
+	checkTemperatureConversions(t, NewUnitConverter(decodeMeasureFamily(t, temperatureFamily)))
+}
+
+func TestFamilyUnitMarshalKeepsOperationOrder(t *testing.T) {
+	family := decodeMeasureFamily(t, temperatureFamily)
+
+	encoded, err := json.Marshal(family)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"convert":{"sub":"32","div":"1.8","add":"273.15"}`) {
+		t.Errorf("the Fahrenheit operations lost their order: %s", encoded)
+	}
+
+	decoded := decodeMeasureFamily(t, string(encoded))
+	checkTemperatureConversions(t, NewUnitConverter(decoded))
+
+	// Repeated operators cannot be written as one object.
+	unit := &FamilyUnit{Code: "ODD", Operations: []ConversionOperation{{"add", "1"}, {"mul", "2"}, {"add", "3"}}}
+	encoded, err = json.Marshal(unit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"code":"ODD","symbol":"","convert":[{"add":"1"},{"mul":"2"},{"add":"3"}]}`; string(encoded) != expected {
+		t.Errorf("unexpected JSON %s", encoded)
+	}
+
+	again := &FamilyUnit{}
+	if err := json.Unmarshal(encoded, again); err != nil || len(again.Operations) != 3 || again.Operations[2].Value != "3" {
+		t.Errorf("unexpected unit %+v, %v", again, err)
+	}
+}
+
+func TestUnitConverterNeedsOrderOfSeveralOperations(t *testing.T) {
+	family := &MeasureFamily{
+		Code:     "Temperature",
+		Standard: "KELVIN",
+		Units: []*FamilyUnit{
+			{Code: "KELVIN", Convert: map[string]string{"mul": "1"}},
+			{Code: "CELSIUS", Convert: map[string]string{"add": "273.15"}},
+			{Code: "FAHRENHEIT", Convert: map[string]string{"sub": "32", "div": "1.8", "add": "273.15"}},
+		},
+	}
+	converter := NewUnitConverter(family)
+
+	if result, err := converter.Convert("Temperature", "CELSIUS", "KELVIN", "0"); err != nil || result != "273.15" {
+		t.Errorf("unexpected single operation conversion %s, %v", result, err)
+	}
+	if _, err := converter.Convert("Temperature", "FAHRENHEIT", "KELVIN", "32"); err == nil {
+		t.Error("expected an error for several operations without Operations")
+	}
+
+	// Marshalling does not need the order: the operators are sorted.
+	for i := 0; i < 3; i++ {
+		encoded, err := json.Marshal(family)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(encoded), `"convert":{"add":"273.15","div":"1.8","sub":"32"}`) {
+			t.Errorf("unexpected JSON %s", encoded)
+		}
+	}
+	if encoded, _ := json.Marshal(&FamilyUnit{Code: "NONE"}); string(encoded) != `{"code":"NONE","symbol":"","convert":null}` {
+		t.Errorf("unexpected JSON %s", encoded)
+	}
+
+	family.Units[2].Operations = []ConversionOperation{{"sub", "32"}, {"div", "1.8"}, {"add", "273.15"}}
+	if result, err := converter.Convert("Temperature", "FAHRENHEIT", "KELVIN", "32"); err != nil || result != "273.15" {
+		t.Errorf("unexpected conversion %s, %v", result, err)
+	}
+}
+
+func TestUnitConverterExactAndRounded(t *testing.T) {
+	family := decodeMeasureFamily(t, `{"code": "Length", "standard": "METER", "units": [
+		{"code": "METER", "symbol": "m", "convert": {"mul": "1"}},
+		{"code": "INCH", "symbol": "in", "convert": {"mul": "0.0254"}},
+		{"code": "FOOT", "symbol": "ft", "convert": {"mul": "0.3048"}}
+	]}`)
+
+	converter := NewUnitConverter(family)
+	if result, err := converter.ConvertRat("Length", "FOOT", "INCH", big.NewRat(1, 1)); err != nil || result.Cmp(big.NewRat(12, 1)) != 0 {
+		t.Errorf("expected exactly 12 inches, got %v, %v", result, err)
+	}
+
+	converter.Decimals = 2
+	if result, _ := converter.Convert("Length", "METER", "INCH", "1"); result != "39.37" {
+		t.Errorf("unexpected rounded result %s", result)
+	}
+
+	if _, err := converter.Convert("Length", "METER", "KILOGRAM", "1"); err == nil {
+		t.Error("expected an error for a unit of another family")
+	}
+	if _, err := converter.Convert("Weight", "GRAM", "KILOGRAM", "1"); err == nil {
+		t.Error("expected an error for an unknown family")
+	}
+}
+
+func TestUnitConverterNormalizeValues(t *testing.T) {
+	converter := NewUnitConverter(decodeMeasureFamily(t, temperatureFamily))
+	channel := &Channel{Code: "us", ConversionUnits: map[string]string{"max_temperature": "FAHRENHEIT"}}
+
+	values := ProductValues{}
+	values.SetMetric("max_temperature", "", "", MetricData{Amount: "100", Unit: "CELSIUS"})
+	values.SetMetric("max_temperature", "eu", "", MetricData{Amount: "100", Unit: "CELSIUS"})
+	values.SetText("name", "", "", "Kettle")
+
+	normalized, err := converter.NormalizeValues(values, channel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metric, _ := normalized.Metric("max_temperature", "", ""); metric.Amount != "212" || metric.Unit != "FAHRENHEIT" {
+		t.Errorf("unexpected metric %+v", metric)
+	}
+	if metric, _ := normalized.Metric("max_temperature", "eu", ""); metric.Unit != "CELSIUS" {
+		t.Errorf("a value of another channel was converted: %+v", metric)
+	}
+	if metric, _ := values.Metric("max_temperature", "", ""); metric.Unit != "CELSIUS" {
+		t.Error("the given values were changed")
+	}
+}
@@ -0,0 +1,261 @@
+package akeneo
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+)
+
+// parentSearchSize bounds the number of parent codes of one product search,
+// to keep the search parameter short enough for a URL.
+const parentSearchSize = 50
+
+// VariantNode is a product model or a variant product in a VariantHierarchy.
+// Level is 0 for a root product model and the variant attribute set level
+// for the others; Axes holds the values of the axes of that level.
+type VariantNode struct {
+	ProductModel *ProductModel
+	Product      *Product
+	Level        int
+	Axes         map[string]interface{}
+	Parent       *VariantNode
+	Children     []*VariantNode
+}
+
+func (node *VariantNode) Code() string {
+	if node.Product != nil {
+		return node.Product.Identifier
+	}
+
+	return node.ProductModel.Code
+}
+
+func (node *VariantNode) parentCode() string {
+	if node.Product != nil {
+		return node.Product.Parent
+	}
+
+	return node.ProductModel.Parent
+}
+
+func (node *VariantNode) values() ProductValues {
+	if node.Product != nil {
+		return node.Product.Values
+	}
+
+	return node.ProductModel.Values
+}
+
+// Products returns the variant products under node, in code order.
+func (node *VariantNode) Products() []*Product {
+	if node.Product != nil {
+		return []*Product{node.Product}
+	}
+
+	var products []*Product
+	for _, child := range node.Children {
+		products = append(products, child.Products()...)
+	}
+
+	return products
+}
+
+// VariantHierarchy is the tree of product models and variant products of a
+// family variant, with the inconsistencies found while building it:
+//   - Orphans have a parent that is not part of the hierarchy;
+//   - Duplicates are groups of siblings sharing the same axis values;
+//   - WrongLevel are nodes deeper or shallower than the family variant allows;
+//   - MissingAxes are nodes without a value for one of their axes.
+type VariantHierarchy struct {
+	FamilyVariant *FamilyVariant
+	Roots         []*VariantNode
+	Orphans       []*VariantNode
+	Duplicates    [][]*VariantNode
+	WrongLevel    []*VariantNode
+	MissingAxes   []*VariantNode
+
+	nodes map[string]*VariantNode
+}
+
+// BuildVariantHierarchy assembles the product models of variant and the
+// products whose parent is one of them. Models of other family variants
+// are ignored.
+func BuildVariantHierarchy(variant *FamilyVariant, models []*ProductModel, products []*Product) *VariantHierarchy {
+	hierarchy := &VariantHierarchy{FamilyVariant: variant, nodes: map[string]*VariantNode{}}
+
+	var nodes []*VariantNode
+	for _, model := range models {
+		if model.FamilyVariant == variant.Code {
+			nodes = append(nodes, &VariantNode{ProductModel: model})
+		}
+	}
+	for _, product := range products {
+		nodes = append(nodes, &VariantNode{Product: product})
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Code() < nodes[j].Code()
+	})
+
+	for _, node := range nodes {
+		hierarchy.nodes[node.Code()] = node
+	}
+
+	for _, node := range nodes {
+		parentCode := node.parentCode()
+		if parentCode == "" {
+			hierarchy.Roots = append(hierarchy.Roots, node)
+			continue
+		}
+
+		parent, known := hierarchy.nodes[parentCode]
+		if !known || parent.Product != nil {
+			hierarchy.Orphans = append(hierarchy.Orphans, node)
+			continue
+		}
+
+		node.Parent = parent
+		parent.Children = append(parent.Children, node)
+	}
+
+	walked := map[*VariantNode]bool{}
+	for _, root := range hierarchy.Roots {
+		hierarchy.walk(root, 0, walked)
+	}
+
+	// Nodes out of reach of the roots have a cycle in their parents.
+	for _, node := range nodes {
+		if node.parentCode() != "" && node.Parent != nil && !walked[node] {
+			hierarchy.Orphans = append(hierarchy.Orphans, node)
+		}
+	}
+
+	return hierarchy
+}
+
+// LoadVariantHierarchy fetches the family variant, its product models and
+// their variant products, and builds their hierarchy.
+func LoadVariantHierarchy(ctx context.Context, api *Api, familyCode string, variantCode string) (*VariantHierarchy, *ApiError) {
+	variant, err := api.FamilyVariant.GetWithContext(ctx, familyCode, variantCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []*ProductModel
+	var parents []string
+
+	modelOptions := ProductModelOptions{Limit: maxPageLimit, Search: NewSearch().Family(OperatorIn, familyCode)}
+	modelIterator := api.ProductModel.IterateWithContext(ctx, modelOptions)
+	for modelIterator.Next() {
+		model := modelIterator.Item().ProductModel
+		if model.FamilyVariant == variantCode {
+			models = append(models, &model)
+			parents = append(parents, model.Code)
+		}
+	}
+	if err := modelIterator.Err(); err != nil {
+		return nil, err
+	}
+
+	var products []*Product
+	for start := 0; start < len(parents); start += parentSearchSize {
+		end := start + parentSearchSize
+		if end > len(parents) {
+			end = len(parents)
+		}
+
+		productOptions := ProductOptions{Limit: maxPageLimit, Search: NewSearch().Parent(OperatorIn, parents[start:end]...)}
+		productIterator := api.Product.IterateWithContext(ctx, productOptions)
+		for productIterator.Next() {
+			product := productIterator.Item().Product
+			products = append(products, &product)
+		}
+		if err := productIterator.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return BuildVariantHierarchy(variant, models, products), nil
+}
+
+// Find returns the node of a product model code or product identifier.
+func (hierarchy *VariantHierarchy) Find(code string) *VariantNode {
+	return hierarchy.nodes[code]
+}
+
+// Valid tells whether the hierarchy has no inconsistency.
+func (hierarchy *VariantHierarchy) Valid() bool {
+	return len(hierarchy.Orphans) == 0 && len(hierarchy.Duplicates) == 0 && len(hierarchy.WrongLevel) == 0 && len(hierarchy.MissingAxes) == 0
+}
+
+func (hierarchy *VariantHierarchy) walk(node *VariantNode, level int, walked map[*VariantNode]bool) {
+	walked[node] = true
+	node.Level = level
+	levels := len(hierarchy.FamilyVariant.AttributeSets)
+
+	// Variant products sit at the last level, product models above it.
+	wrongLevel := (node.Product != nil && level != levels) || (node.ProductModel != nil && level >= levels)
+	if wrongLevel {
+		hierarchy.WrongLevel = append(hierarchy.WrongLevel, node)
+	}
+
+	if axes := hierarchy.axes(level); len(axes) > 0 && !wrongLevel {
+		node.Axes = map[string]interface{}{}
+
+		for _, axis := range axes {
+			if value := node.values().Value(axis, "", ""); value != nil && !isEmptyData(value.Data) {
+				node.Axes[axis] = value.Data
+			}
+		}
+
+		if len(node.Axes) < len(axes) {
+			hierarchy.MissingAxes = append(hierarchy.MissingAxes, node)
+		}
+	}
+
+	combinations := map[string][]*VariantNode{}
+	var keys []string
+
+	for _, child := range node.Children {
+		hierarchy.walk(child, level+1, walked)
+
+		axes := hierarchy.axes(level + 1)
+		if len(axes) == 0 || len(child.Axes) < len(axes) {
+			continue
+		}
+
+		key := axesKey(axes, child.Axes)
+		if _, seen := combinations[key]; !seen {
+			keys = append(keys, key)
+		}
+		combinations[key] = append(combinations[key], child)
+	}
+
+	for _, key := range keys {
+		if len(combinations[key]) > 1 {
+			hierarchy.Duplicates = append(hierarchy.Duplicates, combinations[key])
+		}
+	}
+}
+
+// axes returns the axes of a level of the family variant.
+func (hierarchy *VariantHierarchy) axes(level int) []string {
+	for _, set := range hierarchy.FamilyVariant.AttributeSets {
+		if set != nil && int(set.Level) == level {
+			return set.Axes
+		}
+	}
+
+	return nil
+}
+
+func axesKey(axes []string, values map[string]interface{}) string {
+	combination := make([]interface{}, len(axes))
+	for i, axis := range axes {
+		combination[i] = values[axis]
+	}
+
+	key, _ := json.Marshal(combination)
+
+	return string(key)
+}
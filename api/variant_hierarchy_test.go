@@ -0,0 +1,122 @@
+package akeneo
+
+import "testing"
+
+func newTestFamilyVariant() *FamilyVariant {
+	return &FamilyVariant{
+		Code: "shoes_by_color_size",
+		AttributeSets: []*FamilyVariantAttributeSets{
+			{Level: 1, Axes: []string{"color"}, Attributes: []string{"color", "picture"}},
+			{Level: 2, Axes: []string{"size"}, Attributes: []string{"size", "sku"}},
+		},
+	}
+}
+
+func newVariantModel(code string, parent string, axis string, value interface{}) *ProductModel {
+	model := &ProductModel{Code: code, Parent: parent, FamilyVariant: "shoes_by_color_size"}
+	if axis != "" {
+		model.Values.Set(axis, "", "", value)
+	}
+
+	return model
+}
+
+func newVariantProduct(identifier string, parent string, size interface{}) *Product {
+	product := &Product{Identifier: identifier, Parent: parent}
+	if size != nil {
+		product.Values.Set("size", "", "", size)
+	}
+
+	return product
+}
+
+func TestBuildVariantHierarchy(t *testing.T) {
+	models := []*ProductModel{
+		newVariantModel("boot", "", "", nil),
+		newVariantModel("boot_red", "boot", "color", "red"),
+		newVariantModel("boot_blue", "boot", "color", "blue"),
+		{Code: "other", FamilyVariant: "shirts_by_size"},
+	}
+	products := []*Product{
+		newVariantProduct("boot_red_42", "boot_red", "42"),
+		newVariantProduct("boot_red_43", "boot_red", "43"),
+		newVariantProduct("boot_blue_42", "boot_blue", "42"),
+	}
+
+	hierarchy := BuildVariantHierarchy(newTestFamilyVariant(), models, products)
+	if !hierarchy.Valid() {
+		t.Fatalf("unexpected inconsistencies %+v", hierarchy)
+	}
+
+	if len(hierarchy.Roots) != 1 || hierarchy.Roots[0].Code() != "boot" {
+		t.Fatalf("unexpected roots %v", hierarchy.Roots)
+	}
+	if hierarchy.Find("other") != nil {
+		t.Error("a model of another family variant was kept")
+	}
+
+	red := hierarchy.Find("boot_red")
+	if red.Level != 1 || red.Axes["color"] != "red" || red.Parent != hierarchy.Roots[0] {
+		t.Errorf("unexpected node %+v", red)
+	}
+
+	leaf := hierarchy.Find("boot_red_43")
+	if leaf.Level != 2 || leaf.Axes["size"] != "43" || leaf.Parent != red {
+		t.Errorf("unexpected node %+v", leaf)
+	}
+
+	var identifiers []string
+	for _, product := range hierarchy.Roots[0].Products() {
+		identifiers = append(identifiers, product.Identifier)
+	}
+	if len(identifiers) != 3 || identifiers[0] != "boot_blue_42" {
+		t.Errorf("unexpected products %v", identifiers)
+	}
+}
+
+func TestVariantHierarchyInconsistencies(t *testing.T) {
+	models := []*ProductModel{
+		newVariantModel("boot", "", "", nil),
+		newVariantModel("boot_red", "boot", "color", "red"),
+		newVariantModel("boot_red_again", "boot", "color", "red"),
+		newVariantModel("boot_nocolor", "boot", "", nil),
+		newVariantModel("loop_a", "loop_b", "color", "green"),
+		newVariantModel("loop_b", "loop_a", "color", "green"),
+	}
+	products := []*Product{
+		newVariantProduct("boot_red_42", "boot_red", "42"),
+		newVariantProduct("lost_42", "missing", "42"),
+		newVariantProduct("shallow", "boot", "42"),
+		newVariantProduct("under_product", "boot_red_42", "42"),
+	}
+
+	hierarchy := BuildVariantHierarchy(newTestFamilyVariant(), models, products)
+	if hierarchy.Valid() {
+		t.Fatal("expected inconsistencies")
+	}
+
+	if codes := nodeCodes(hierarchy.Orphans); codes != "lost_42,under_product,loop_a,loop_b" {
+		t.Errorf("unexpected orphans %s", codes)
+	}
+	if len(hierarchy.Duplicates) != 1 || nodeCodes(hierarchy.Duplicates[0]) != "boot_red,boot_red_again" {
+		t.Errorf("unexpected duplicates %v", hierarchy.Duplicates)
+	}
+	if codes := nodeCodes(hierarchy.WrongLevel); codes != "shallow" {
+		t.Errorf("unexpected wrong levels %s", codes)
+	}
+	if codes := nodeCodes(hierarchy.MissingAxes); codes != "boot_nocolor" {
+		t.Errorf("unexpected missing axes %s", codes)
+	}
+}
+
+func nodeCodes(nodes []*VariantNode) string {
+	codes := ""
+	for i, node := range nodes {
+		if i > 0 {
+			codes += ","
+		}
+		codes += node.Code()
+	}
+
+	return codes
+}
@@ -0,0 +1,310 @@
+package akeneo
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var codeUnsafeCharacters = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// VariantSplitter splits flat records, one per variant product with all its
+// values, into the product models and variant products of a family variant:
+// values of an attribute set go to the model or product of its level and
+// the others to the root product model.
+type VariantSplitter struct {
+	FamilyVariant *FamilyVariant
+
+	// IdentifierAttribute is the code of the identifier attribute, whose
+	// values always stay on the variant products. Defaults to "sku".
+	IdentifierAttribute string
+
+	// RootCode returns the code of the root product model of a record.
+	// Defaults to the Parent of the record.
+	RootCode func(record *Product) string
+
+	// ModelCode returns the code of the sub product model under parent
+	// with the given axis values, in the order of the family variant
+	// axes. Defaults to the parent code followed by the axis values.
+	ModelCode func(parent string, axisValues []interface{}) string
+}
+
+// ValueConflict is a value of a product model on which the records of its
+// variants disagree. Values holds the data of each record, by identifier.
+type ValueConflict struct {
+	Model     string
+	Attribute string
+	Scope     string
+	Locale    string
+	Values    map[string]interface{}
+}
+
+// VariantSplit is the result of VariantSplitter.Split. Records that cannot
+// be placed in the tree are left out and reported in Violations, with the
+// record identifier in the message.
+type VariantSplit struct {
+	ProductModels []*ProductModel
+	Products      []*Product
+	Conflicts     []*ValueConflict
+	Violations    Violations
+}
+
+func NewVariantSplitter(variant *FamilyVariant) *VariantSplitter {
+	return &VariantSplitter{FamilyVariant: variant}
+}
+
+// Split returns the product models, root ones first, and the variant
+// products of records, in the order of records. The values of a model are
+// taken from the first record that has them.
+func (splitter *VariantSplitter) Split(records []*Product) *VariantSplit {
+	split := &VariantSplit{}
+	levels := splitter.levels()
+	if len(levels) == 0 {
+		split.Violations = append(split.Violations, Violation{Message: fmt.Sprintf("family variant %q has no attribute set", splitter.FamilyVariant.Code)})
+		return split
+	}
+
+	models := map[string]*splitModel{}
+	var modelCodes []string
+
+	for _, record := range records {
+		chain, violation := splitter.chain(record, levels)
+		if violation != nil {
+			split.Violations = append(split.Violations, *violation)
+			continue
+		}
+
+		product := &Product{
+			Identifier:   record.Identifier,
+			Enabled:      record.Enabled,
+			FamilyCode:   record.FamilyCode,
+			Categories:   record.Categories,
+			Groups:       record.Groups,
+			Parent:       chain[len(chain)-1],
+			Associations: record.Associations,
+		}
+
+		for level, code := range chain {
+			if _, known := models[code]; known {
+				continue
+			}
+
+			model := &ProductModel{Code: code, FamilyVariant: splitter.FamilyVariant.Code}
+			if level > 0 {
+				model.Parent = chain[level-1]
+			}
+
+			models[code] = &splitModel{model: model, seen: map[string]*splitValue{}}
+			modelCodes = append(modelCodes, code)
+		}
+
+		attributes := make([]string, 0, len(record.Values))
+		for attribute := range record.Values {
+			attributes = append(attributes, attribute)
+		}
+		sort.Strings(attributes)
+
+		for _, attribute := range attributes {
+			level := splitter.level(attribute, levels)
+			if level == len(chain) {
+				product.Values = appendValues(product.Values, attribute, record.Values[attribute])
+				continue
+			}
+
+			for _, value := range record.Values[attribute] {
+				models[chain[level]].add(split, record.Identifier, attribute, value)
+			}
+		}
+
+		split.Products = append(split.Products, product)
+	}
+
+	// Parents are created before their children, so a stable sort on the
+	// depth keeps roots first.
+	sort.SliceStable(modelCodes, func(i, j int) bool {
+		return depth(models, modelCodes[i]) < depth(models, modelCodes[j])
+	})
+	for _, code := range modelCodes {
+		split.ProductModels = append(split.ProductModels, models[code].model)
+	}
+
+	return split
+}
+
+// levels returns the attribute sets of the family variant by level.
+func (splitter *VariantSplitter) levels() []*FamilyVariantAttributeSets {
+	var sets []*FamilyVariantAttributeSets
+	for _, set := range splitter.FamilyVariant.AttributeSets {
+		if set != nil {
+			sets = append(sets, set)
+		}
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].Level < sets[j].Level
+	})
+
+	return sets
+}
+
+// level returns the level of an attribute: 0 for the common attributes of
+// the root product model, the level of its attribute set otherwise.
+func (splitter *VariantSplitter) level(attribute string, levels []*FamilyVariantAttributeSets) int {
+	identifier := splitter.IdentifierAttribute
+	if identifier == "" {
+		identifier = "sku"
+	}
+
+	if attribute == identifier {
+		return len(levels)
+	}
+
+	for i, set := range levels {
+		if containsString(set.Attributes, attribute) || containsString(set.Axes, attribute) {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// chain returns the codes of the root product model and of the sub product
+// models of a record.
+func (splitter *VariantSplitter) chain(record *Product, levels []*FamilyVariantAttributeSets) ([]string, *Violation) {
+	root := record.Parent
+	if splitter.RootCode != nil {
+		root = splitter.RootCode(record)
+	}
+
+	if root == "" {
+		return nil, &Violation{Message: fmt.Sprintf("record %q has no root product model code", record.Identifier)}
+	}
+
+	chain := []string{root}
+	for _, set := range levels[:len(levels)-1] {
+		axisValues := make([]interface{}, len(set.Axes))
+
+		for i, axis := range set.Axes {
+			value := record.Values.Value(axis, "", "")
+			if value == nil || isEmptyData(value.Data) {
+				return nil, &Violation{Attribute: axis, Message: fmt.Sprintf("record %q has no value for this axis", record.Identifier)}
+			}
+			axisValues[i] = value.Data
+		}
+
+		parent := chain[len(chain)-1]
+		if splitter.ModelCode != nil {
+			chain = append(chain, splitter.ModelCode(parent, axisValues))
+		} else {
+			chain = append(chain, defaultModelCode(parent, axisValues))
+		}
+	}
+
+	return chain, nil
+}
+
+type splitModel struct {
+	model *ProductModel
+	seen  map[string]*splitValue
+}
+
+type splitValue struct {
+	identifier string
+	data       interface{}
+	encoded    string
+	conflict   *ValueConflict
+}
+
+// add sets a value on the model, or checks it against the value given by a
+// previous record.
+func (m *splitModel) add(split *VariantSplit, identifier string, attribute string, value *ProductAttributeValue) {
+	if value == nil {
+		return
+	}
+
+	scope, locale := "", ""
+	if value.Scope != nil {
+		scope = *value.Scope
+	}
+	if value.Locale != nil {
+		locale = *value.Locale
+	}
+
+	encoded, _ := json.Marshal(value.Data)
+	key := attribute + "/" + scope + "/" + locale
+
+	previous, seen := m.seen[key]
+	if !seen {
+		m.seen[key] = &splitValue{identifier: identifier, data: value.Data, encoded: string(encoded)}
+		m.model.Values = appendValues(m.model.Values, attribute, []*ProductAttributeValue{value})
+		return
+	}
+
+	if previous.encoded == string(encoded) {
+		return
+	}
+
+	if previous.conflict == nil {
+		previous.conflict = &ValueConflict{
+			Model:     m.model.Code,
+			Attribute: attribute,
+			Scope:     scope,
+			Locale:    locale,
+			Values:    map[string]interface{}{previous.identifier: previous.data},
+		}
+		split.Conflicts = append(split.Conflicts, previous.conflict)
+	}
+
+	previous.conflict.Values[identifier] = value.Data
+}
+
+func appendValues(values ProductValues, attribute string, added []*ProductAttributeValue) ProductValues {
+	if values == nil {
+		values = ProductValues{}
+	}
+
+	values[attribute] = append(values[attribute], added...)
+
+	return values
+}
+
+func depth(models map[string]*splitModel, code string) int {
+	level := 0
+	for model := models[code]; model != nil && model.model.Parent != ""; model = models[model.model.Parent] {
+		level++
+	}
+
+	return level
+}
+
+func defaultModelCode(parent string, axisValues []interface{}) string {
+	parts := []string{parent}
+	for _, value := range axisValues {
+		parts = append(parts, axisToken(value))
+	}
+
+	return codeUnsafeCharacters.ReplaceAllString(strings.Join(parts, "_"), "_")
+}
+
+func axisToken(value interface{}) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(typed)
+	}
+
+	metric := &MetricData{}
+	if decodeData(value, metric) == nil && metric.Unit != "" {
+		return metric.Amount.String() + "_" + metric.Unit
+	}
+
+	encoded, _ := json.Marshal(value)
+
+	return string(encoded)
+}
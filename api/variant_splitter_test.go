@@ -0,0 +1,117 @@
+package akeneo
+
+import (
+	"strings"
+	"testing"
+)
+
+func newSplitRecord(identifier string, color string, size string, name string) *Product {
+	record := &Product{Identifier: identifier, Parent: "boot", FamilyCode: "shoes", Enabled: true}
+	record.Values.SetText("sku", "", "", identifier)
+	record.Values.SetText("name", "", "en_US", name)
+	record.Values.SetSimpleSelect("color", "", "", color)
+	record.Values.SetText("picture", "", "", color+".jpg")
+	record.Values.SetSimpleSelect("size", "", "", size)
+
+	return record
+}
+
+func TestVariantSplitterSplit(t *testing.T) {
+	records := []*Product{
+		newSplitRecord("boot-red-42", "red", "42", "Boot"),
+		newSplitRecord("boot-red-43", "red", "43", "Boot"),
+		newSplitRecord("boot-dark blue-42", "dark blue", "42", "Boot"),
+	}
+
+	split := NewVariantSplitter(newTestFamilyVariant()).Split(records)
+	if len(split.Violations) > 0 || len(split.Conflicts) > 0 {
+		t.Fatalf("unexpected problems %v %v", split.Violations, split.Conflicts)
+	}
+
+	var codes []string
+	for _, model := range split.ProductModels {
+		codes = append(codes, model.Code+"<"+model.Parent)
+	}
+	if joined := strings.Join(codes, " "); joined != "boot< boot_red<boot boot_dark_blue<boot" {
+		t.Errorf("unexpected product models %s", joined)
+	}
+
+	root := split.ProductModels[0]
+	if name, _ := root.Values.Text("name", "", "en_US"); name != "Boot" || len(root.Values) != 1 {
+		t.Errorf("unexpected root values %v", root.Values)
+	}
+
+	red := split.ProductModels[1]
+	if picture, _ := red.Values.Text("picture", "", ""); picture != "red.jpg" || len(red.Values) != 2 {
+		t.Errorf("unexpected sub model values %v", red.Values)
+	}
+
+	product := split.Products[1]
+	if product.Identifier != "boot-red-43" || product.Parent != "boot_red" || !product.Enabled || len(product.Values) != 2 {
+		t.Errorf("unexpected product %+v", product)
+	}
+	if size, _ := product.Values.SimpleSelect("size", "", ""); size != "43" {
+		t.Errorf("unexpected size %q", size)
+	}
+}
+
+func TestVariantSplitterReportsConflictsAndViolations(t *testing.T) {
+	noColor := newSplitRecord("boot-none-42", "", "42", "Boot")
+	noRoot := newSplitRecord("orphan", "red", "42", "Boot")
+	noRoot.Parent = ""
+
+	records := []*Product{
+		newSplitRecord("boot-red-42", "red", "42", "Boot"),
+		newSplitRecord("boot-red-43", "red", "43", "Big boot"),
+		newSplitRecord("boot-red-44", "red", "44", "Small boot"),
+		noColor,
+		noRoot,
+	}
+
+	split := NewVariantSplitter(newTestFamilyVariant()).Split(records)
+
+	if len(split.Products) != 3 {
+		t.Errorf("expected 3 products, got %d", len(split.Products))
+	}
+	if len(split.Violations) != 2 || split.Violations[0].Attribute != "color" || !strings.Contains(split.Violations[1].Message, `"orphan"`) {
+		t.Errorf("unexpected violations %v", split.Violations)
+	}
+
+	if len(split.Conflicts) != 1 {
+		t.Fatalf("expected one conflict, got %v", split.Conflicts)
+	}
+	conflict := split.Conflicts[0]
+	if conflict.Model != "boot" || conflict.Attribute != "name" || conflict.Locale != "en_US" || len(conflict.Values) != 3 || conflict.Values["boot-red-44"] != "Small boot" {
+		t.Errorf("unexpected conflict %+v", conflict)
+	}
+}
+
+func TestVariantSplitterCustomCodes(t *testing.T) {
+	splitter := NewVariantSplitter(newTestFamilyVariant())
+	splitter.IdentifierAttribute = "ean"
+	splitter.RootCode = func(record *Product) string {
+		return "model-" + record.FamilyCode
+	}
+	splitter.ModelCode = func(parent string, axisValues []interface{}) string {
+		return parent + "-" + axisValues[0].(string)
+	}
+
+	record := newSplitRecord("boot-red-42", "red", "42", "Boot")
+	record.Values.SetText("ean", "", "", "1234567890123")
+
+	split := splitter.Split([]*Product{record})
+
+	if len(split.ProductModels) != 2 || split.ProductModels[1].Code != "model-shoes-red" {
+		t.Errorf("unexpected product models %v", split.ProductModels)
+	}
+	if ean, err := split.Products[0].Values.Text("ean", "", ""); err != nil || ean != "1234567890123" {
+		t.Errorf("expected the identifier on the variant product: %q, %v", ean, err)
+	}
+}
+
+func TestDefaultModelCode(t *testing.T) {
+	code := defaultModelCode("boot", []interface{}{"dark blue", 42.5, true, map[string]interface{}{"amount": "1.5", "unit": "KILOGRAM"}})
+	if code != "boot_dark_blue_42_5_true_1_5_KILOGRAM" {
+		t.Errorf("unexpected code %q", code)
+	}
+}
@@ -17,6 +17,12 @@ func _main() {
 		ClientId:       "client_id", // http://akeneo-pim-host.com/#/client/
 		SecretKey:      "secret_key",
 		RequestTimeout: time.Second * 5,
+		RetryPolicy:    akeneo.DefaultRetryPolicy(),
+		RateLimit: &akeneo.RateLimitConfig{
+			RequestsPerSecond: 10,
+			Burst:             20,
+			MaxConcurrent:     4,
+		},
 	}
 
 	client := akeneo.NewClient(config)
@@ -96,6 +96,7 @@ func runProductsMethods() {
 	getProduct()
 	deleteProduct()
 	getAllProducts()
+	iterateProducts()
 }
 
 func createProduct() {
@@ -160,3 +161,39 @@ func getAllProducts() {
 		}
 	}
 }
+
+
+func iterateProducts() {
+	opts := akeneo.ProductOptions{PaginationType: akeneo.PaginationTypeSearchAfter, Limit: 100}
+	it := akeneoApi.Product.Iterate(opts)
+
+	for it.Next() {
+		log.Println(fmt.Sprintf("[PRODUCT_ITERATE]: %s", it.Item().Identifier))
+	}
+
+	if err := it.Err(); err != nil {
+		log.Println(fmt.Sprintf("[PRODUCT_ITERATE_ERROR]: %s", err.Message))
+	}
+}
+
+func searchProducts() {
+	opts, err := akeneo.NewSearch().
+		Family(akeneo.OperatorIn, "drop_family").
+		Enabled(true).
+		UpdatedSinceLastNDays(7).
+		Apply(akeneo.RequestOpts{"limit": "100"})
+	if err != nil {
+		log.Println(fmt.Sprintf("[PRODUCT_SEARCH_ERROR]: %s", err))
+		return
+	}
+
+	resp, apiErr := akeneoApi.Product.GetAll(opts)
+	if apiErr != nil {
+		log.Println(fmt.Sprintf("[PRODUCT_SEARCH_ERROR]: %s", apiErr.Message))
+		return
+	}
+
+	for _, prod := range resp.Data.Items {
+		log.Println(fmt.Sprintf("[PRODUCT_SEARCH]: %s", prod.Identifier))
+	}
+}
@@ -0,0 +1,229 @@
+package fakeserver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault is a scripted failure. A request matches when its method and path
+// match; Path is a path.Match pattern on the URL path, relative to
+// /api/rest/v1/ unless it starts with a slash. The first After matching
+// requests are served normally, then the fault is applied to the next Times
+// ones, or to all of them when Times is zero.
+type Fault struct {
+	Method string
+	Path   string
+	After  int
+	Times  int
+
+	// Identifiers restricts the fault to these product identifiers or codes.
+	// On a batch PATCH only the matching lines fail, with Status as their
+	// status code, while the other lines are processed.
+	Identifiers []string
+
+	Latency        time.Duration
+	Status         int
+	RetryAfter     time.Duration
+	Body           string
+	MalformedJSON  bool
+	TruncateBody   bool
+	DropConnection bool
+
+	// ExpireTokens invalidates every access token before the request is
+	// handled, as if the token expired mid-run.
+	ExpireTokens bool
+
+	hits    int
+	applied int
+}
+
+// AddFault registers fault rules. Rules are evaluated in the order they
+// were added and the first matching one wins.
+func (s *Server) AddFault(faults ...*Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, faults...)
+}
+
+// ClearFaults removes every fault rule.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// Applied returns how many requests the fault has been applied to.
+func (s *Server) Applied(fault *Fault) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fault.applied
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, r.Method) {
+		return false
+	}
+
+	if f.Path != "" {
+		pattern := f.Path
+		if !strings.HasPrefix(pattern, "/") {
+			pattern = restPrefix + pattern
+		}
+		if ok, _ := path.Match(pattern, r.URL.Path); !ok {
+			return false
+		}
+	}
+
+	if len(f.Identifiers) > 0 && !f.batch(r) {
+		rt, ok := parseRoute(strings.TrimPrefix(r.URL.EscapedPath(), restPrefix))
+		if !ok || !contains(f.Identifiers, rt.code) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *Fault) batch(r *http.Request) bool {
+	if r.Method != http.MethodPatch {
+		return false
+	}
+
+	rt, ok := parseRoute(strings.TrimPrefix(r.URL.EscapedPath(), restPrefix))
+
+	return ok && rt.code == ""
+}
+
+// lineFault reports whether a batch line must fail because of the fault.
+func (f *Fault) lineFault(code string) bool {
+	return f != nil && contains(f.Identifiers, code)
+}
+
+func (f *Fault) lineStatus() int {
+	if f.Status != 0 {
+		return f.Status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// fault returns the fault to apply to the request, if any. It must be
+// called with the lock held.
+func (s *Server) fault(r *http.Request) *Fault {
+	for _, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+
+		f.hits++
+		if f.hits <= f.After || (f.Times > 0 && f.applied >= f.Times) {
+			return nil
+		}
+
+		f.applied++
+
+		return f
+	}
+
+	return nil
+}
+
+// wait sleeps for the fault latency, or until the client gives up.
+func (f *Fault) wait(r *http.Request) bool {
+	if f.Latency <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(f.Latency)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// respond writes the faulty response and reports whether the request was
+// fully handled. When it returns false the request must be processed
+// normally.
+func (f *Fault) respond(w http.ResponseWriter, r *http.Request) bool {
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((f.RetryAfter+time.Second-1)/time.Second)))
+	}
+
+	switch {
+	case f.DropConnection:
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			panic(http.ErrAbortHandler)
+		}
+		conn, _, err := hijacker.Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+		return true
+	case f.batch(r) && len(f.Identifiers) > 0:
+		return false
+	case f.MalformedJSON:
+		status := f.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"code": ` + strconv.Itoa(status) + `, "message": "`))
+		return true
+	case f.Status != 0 && !f.TruncateBody:
+		if f.Body != "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(f.Status)
+			_, _ = w.Write([]byte(f.Body))
+		} else {
+			writeError(w, f.Status, http.StatusText(f.Status))
+		}
+		return true
+	}
+
+	return false
+}
+
+// truncate writes the recorded response with its body cut in the middle of
+// a line. Content-Length matches the cut body, so the client reads a clean
+// but incomplete response.
+func (f *Fault) truncate(w http.ResponseWriter, recorded *httptest.ResponseRecorder) {
+	for key, values := range recorded.Header() {
+		w.Header()[key] = values
+	}
+
+	status := recorded.Code
+	if f.Status != 0 {
+		status = f.Status
+	}
+
+	body := recorded.Body.Bytes()
+	if f.Body != "" {
+		body = []byte(f.Body)
+	}
+
+	half := len(body) / 2
+	start := bytes.LastIndexByte(body[:half], '\n') + 1
+	end := len(body)
+	if i := bytes.IndexByte(body[half:], '\n'); i >= 0 {
+		end = half + i
+	}
+	cut := start + (end-start)/2
+
+	w.Header().Set("Content-Length", strconv.Itoa(cut))
+	w.WriteHeader(status)
+	_, _ = w.Write(body[:cut])
+}
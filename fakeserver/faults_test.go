@@ -0,0 +1,183 @@
+package fakeserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+func newRetryingApi(t *testing.T) (*Server, *akeneo.Api) {
+	s := NewServer(&Config{Username: "admin", Password: "admin", ClientId: "client_id", SecretKey: "secret_key", SkipReferenceChecks: true})
+	t.Cleanup(s.Close)
+
+	policy := akeneo.DefaultRetryPolicy()
+	policy.MinDelay = time.Millisecond
+	policy.MaxDelay = 10 * time.Millisecond
+
+	config := s.ClientConfig()
+	config.RetryPolicy = policy
+
+	return s, akeneo.NewAkeneoApi(akeneo.NewClient(config))
+}
+
+func TestFaultStatusIsRetried(t *testing.T) {
+	s, api := newRetryingApi(t)
+	s.Load(akeneo.ResourceProduct, "", &akeneo.Product{Identifier: "a"})
+
+	fault := &Fault{Method: http.MethodGet, Path: "products/*", Status: http.StatusServiceUnavailable, Times: 2}
+	s.AddFault(fault)
+
+	if _, err := api.Product.Get("a"); err != nil {
+		t.Fatal(err)
+	}
+	if applied := s.Applied(fault); applied != 2 {
+		t.Errorf("expected the fault to be applied twice, got %d", applied)
+	}
+}
+
+func TestFaultAfterAndRetryAfter(t *testing.T) {
+	s, api := newRetryingApi(t)
+	s.Load(akeneo.ResourceProduct, "", &akeneo.Product{Identifier: "a"})
+
+	fault := &Fault{Path: "products/a", After: 1, Times: 1, Status: http.StatusTooManyRequests, RetryAfter: time.Millisecond}
+	s.AddFault(fault)
+
+	for i := 0; i < 2; i++ {
+		if _, err := api.Product.Get("a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if applied := s.Applied(fault); applied != 1 {
+		t.Errorf("expected the fault to be applied once, got %d", applied)
+	}
+}
+
+func TestFaultOnBatchLines(t *testing.T) {
+	s, api := newRetryingApi(t)
+	s.AddFault(&Fault{Method: http.MethodPatch, Path: "products", Identifiers: []string{"b"}, Status: http.StatusUnprocessableEntity})
+
+	lines, err := api.Product.BatchUpsert([]*akeneo.Product{{Identifier: "a"}, {Identifier: "b"}, {Identifier: "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range lines {
+		failed := line.StatusCode == http.StatusUnprocessableEntity
+		if failed != (line.Identifier == "b") {
+			t.Errorf("unexpected status %d for %q", line.StatusCode, line.Identifier)
+		}
+	}
+	if count := s.Count(akeneo.ResourceProduct, ""); count != 2 {
+		t.Errorf("expected 2 products, got %d", count)
+	}
+}
+
+func TestFaultDropConnectionIsRetried(t *testing.T) {
+	s, api := newRetryingApi(t)
+	s.Load(akeneo.ResourceProduct, "", &akeneo.Product{Identifier: "a"})
+
+	fault := &Fault{Path: "products/a", DropConnection: true, Times: 1}
+	s.AddFault(fault)
+
+	if _, err := api.Product.Get("a"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Applied(fault) != 1 {
+		t.Error("expected the fault to be applied")
+	}
+}
+
+func TestFaultMalformedAndTruncatedBodies(t *testing.T) {
+	s, api := newRetryingApi(t)
+	s.Load(akeneo.ResourceProduct, "", &akeneo.Product{Identifier: "a"})
+
+	s.AddFault(&Fault{Path: "products/a", MalformedJSON: true, Times: 1})
+	if _, err := api.Product.Get("a"); err == nil {
+		t.Error("expected a decoding error for a malformed body")
+	}
+
+	s.ClearFaults()
+	s.AddFault(&Fault{Path: "products/a", TruncateBody: true, Times: 1})
+	if _, err := api.Product.Get("a"); err == nil {
+		t.Error("expected a decoding error for a truncated body")
+	}
+
+	if _, err := api.Product.Get("a"); err != nil {
+		t.Errorf("expected the request to succeed once the fault is spent, got %v", err)
+	}
+}
+
+func TestFaultTruncatedBatchResponse(t *testing.T) {
+	s, api := newRetryingApi(t)
+	s.AddFault(&Fault{Method: http.MethodPatch, Path: "products", TruncateBody: true, Times: 1})
+
+	lines, err := api.Product.BatchUpsert([]*akeneo.Product{{Identifier: "a"}, {Identifier: "b"}, {Identifier: "c"}})
+	if err == nil {
+		t.Errorf("expected an error for a truncated batch response, got %d lines", len(lines))
+	}
+}
+
+func TestFaultLatencyAndTimeout(t *testing.T) {
+	s := NewServer(nil)
+	defer s.Close()
+
+	config := s.ClientConfig()
+	config.RequestTimeout = 20 * time.Millisecond
+	api := akeneo.NewAkeneoApi(akeneo.NewClient(config))
+
+	s.AddFault(&Fault{Path: "products/*", Latency: time.Second})
+	if _, err := api.Product.Get("a"); err == nil {
+		t.Error("expected a timeout")
+	}
+}
+
+func TestFaultLatencyAndContextDeadline(t *testing.T) {
+	s, api := newRetryingApi(t)
+	s.Load(akeneo.ResourceProduct, "", &akeneo.Product{Identifier: "a"})
+
+	s.AddFault(&Fault{Path: "/api/oauth/v1/token", Latency: time.Second, Times: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := api.Product.GetWithContext(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the token fetch to exceed the deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("the token fetch returned after %s", elapsed)
+	}
+
+	if _, err := api.Product.Get("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.AddFault(&Fault{Path: "products/a", Latency: time.Second})
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+	if _, err := api.Product.GetWithContext(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the request to exceed the deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("the request returned after %s", elapsed)
+	}
+}
+
+func TestFaultExpireTokens(t *testing.T) {
+	s, api := newRetryingApi(t)
+	s.Load(akeneo.ResourceProduct, "", &akeneo.Product{Identifier: "a"})
+
+	if _, err := api.Product.Get("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.AddFault(&Fault{Path: "products/a", ExpireTokens: true, Times: 1})
+	if _, err := api.Product.Get("a"); err == nil || !akeneo.IsUnauthorized(err) {
+		t.Errorf("expected a 401 once tokens expired, got %v", err)
+	}
+}
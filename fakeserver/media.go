@@ -0,0 +1,122 @@
+package fakeserver
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+const maxUploadSize = 32 << 20
+
+func (s *Server) handleMediaUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		writeError(w, http.StatusBadRequest, "The request should be a multipart/form-data request.")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeValidationError(w, []*akeneo.ValidationError{violation("file", "Property \"file\" is required.")})
+		return
+	}
+	defer file.Close()
+
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sum := sha1.Sum(append([]byte(header.Filename), content...))
+	hash := hex.EncodeToString(sum[:])
+	code := fmt.Sprintf("%s/%s/%s/%s/%s_%s", hash[0:1], hash[1:2], hash[2:3], hash[3:4], hash, header.Filename)
+
+	extension := strings.TrimPrefix(filepath.Ext(header.Filename), ".")
+	mimeType := mime.TypeByExtension(filepath.Ext(header.Filename))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(content)
+	}
+
+	rec := record{
+		"code":              code,
+		"original_filename": header.Filename,
+		"mime_type":         mimeType,
+		"size":              len(content),
+		"extension":         extension,
+	}
+
+	if raw := r.FormValue("product"); raw != "" {
+		target := &akeneo.MediaFileProduct{}
+		if err := json.Unmarshal([]byte(raw), target); err != nil {
+			writeError(w, http.StatusBadRequest, "Property \"product\" should be valid JSON.")
+			return
+		}
+
+		if violations := s.attachMedia(productDef, target.Identifier, target.Attribute, target.Scope, target.Locale, code); len(violations) > 0 {
+			writeValidationError(w, violations)
+			return
+		}
+	} else if raw := r.FormValue("product_model"); raw != "" {
+		target := &akeneo.MediaFileProductModel{}
+		if err := json.Unmarshal([]byte(raw), target); err != nil {
+			writeError(w, http.StatusBadRequest, "Property \"product_model\" should be valid JSON.")
+			return
+		}
+
+		if violations := s.attachMedia(productModelDef, target.Code, target.Attribute, target.Scope, target.Locale, code); len(violations) > 0 {
+			writeValidationError(w, violations)
+			return
+		}
+	}
+
+	s.collection(mediaFileDef, "").items[code] = rec
+	s.files[code] = content
+
+	w.Header().Set("Location", s.link(mediaFileDef.path+"/"+code, nil)["href"])
+	w.WriteHeader(http.StatusCreated)
+}
+
+// attachMedia sets the uploaded file as the value of the given attribute on
+// an existing product or product model.
+func (s *Server) attachMedia(def *resourceDef, code string, attribute string, scope *string, locale *string, media string) []*akeneo.ValidationError {
+	if !s.exists(def, "", code) {
+		return []*akeneo.ValidationError{violation(def.name, "The %s \"%s\" does not exist.", strings.Replace(def.name, "_", " ", -1), code)}
+	}
+
+	value := map[string]interface{}{"data": media, "scope": nil, "locale": nil}
+	if scope != nil {
+		value["scope"] = *scope
+	}
+	if locale != nil {
+		value["locale"] = *locale
+	}
+
+	patch := record{"values": map[string]interface{}{attribute: []interface{}{value}}}
+	_, violations := s.upsert(&route{def: def}, code, patch)
+
+	return violations
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, rt *route) {
+	content, ok := s.files[rt.code]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Media file \"%s\" does not exist.", rt.code))
+		return
+	}
+
+	rec := s.collection(mediaFileDef, "").items[rt.code]
+	if mimeType := rec.str("mime_type"); mimeType != "" {
+		w.Header().Set("Content-Type", mimeType)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", rec.str("original_filename")))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
@@ -0,0 +1,138 @@
+package fakeserver
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+type recordingObserver struct {
+	akeneo.NopObserver
+	retries    []*akeneo.RetryEvent
+	batchLines []*akeneo.BatchLineEvent
+}
+
+func (o *recordingObserver) OnRetry(event *akeneo.RetryEvent) {
+	o.retries = append(o.retries, event)
+}
+
+func (o *recordingObserver) OnBatchLineFailure(event *akeneo.BatchLineEvent) {
+	o.batchLines = append(o.batchLines, event)
+}
+
+func newObservedApi(t *testing.T, observers ...akeneo.Observer) (*Server, *akeneo.Api) {
+	s := NewServer(&Config{Username: "admin", Password: "admin", ClientId: "client_id", SecretKey: "secret_key", SkipReferenceChecks: true})
+	t.Cleanup(s.Close)
+
+	policy := akeneo.DefaultRetryPolicy()
+	policy.MinDelay = time.Millisecond
+	policy.MaxDelay = 10 * time.Millisecond
+
+	config := s.ClientConfig()
+	config.RetryPolicy = policy
+	config.Observers = observers
+
+	s.Load(akeneo.ResourceProduct, "", &akeneo.Product{Identifier: "a"})
+	s.AddFault(
+		&Fault{Method: http.MethodGet, Path: "products/a", Status: http.StatusServiceUnavailable, Times: 1},
+		&Fault{Method: http.MethodPatch, Path: "products", Identifiers: []string{"b"}, Status: http.StatusUnprocessableEntity},
+	)
+
+	return s, akeneo.NewAkeneoApi(akeneo.NewClient(config))
+}
+
+// runObservedRequests gets a product once through a retried 503 and sends a
+// batch with one rejected line.
+func runObservedRequests(t *testing.T, api *akeneo.Api) {
+	if _, err := api.Product.Get("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.Product.BatchUpsert([]*akeneo.Product{{Identifier: "a"}, {Identifier: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObserverRetryAndBatchLineCallbacks(t *testing.T) {
+	observer := &recordingObserver{}
+	_, api := newObservedApi(t, observer)
+	runObservedRequests(t, api)
+
+	if len(observer.retries) != 1 {
+		t.Fatalf("expected one retry, got %d", len(observer.retries))
+	}
+	retry := observer.retries[0]
+	if retry.Resource != akeneo.ResourceProduct || retry.Operation != akeneo.OperationGet || retry.Attempt != 1 || retry.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("unexpected retry %+v", retry)
+	}
+
+	if len(observer.batchLines) != 1 {
+		t.Fatalf("expected one failed batch line, got %d", len(observer.batchLines))
+	}
+	line := observer.batchLines[0]
+	if line.Operation != akeneo.OperationBatchUpsert || line.Line.Identifier != "b" || line.Line.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("unexpected batch line %+v %+v", line, line.Line)
+	}
+}
+
+func TestMetricsCollectorExposition(t *testing.T) {
+	collector := akeneo.NewMetricsCollector()
+	_, api := newObservedApi(t, collector)
+	runObservedRequests(t, api)
+
+	var b bytes.Buffer
+	if err := collector.WriteMetrics(&b); err != nil {
+		t.Fatal(err)
+	}
+	metrics := b.String()
+
+	expected := []string{
+		"# HELP akeneo_requests_in_flight Requests sent to Akeneo and not answered yet.\n# TYPE akeneo_requests_in_flight gauge\nakeneo_requests_in_flight 0\n",
+		"# HELP akeneo_requests_total HTTP requests sent to Akeneo.\n# TYPE akeneo_requests_total counter\n",
+		`akeneo_requests_total{resource="product",operation="get",method="GET",status="503"} 1` + "\n",
+		`akeneo_requests_total{resource="product",operation="get",method="GET",status="200"} 1` + "\n",
+		`akeneo_requests_total{resource="product",operation="batch_upsert",method="PATCH",status="200"} 1` + "\n",
+		"# TYPE akeneo_request_duration_seconds histogram\n",
+		`akeneo_request_duration_seconds_bucket{resource="product",operation="get",le="30"} 2` + "\n",
+		`akeneo_request_duration_seconds_bucket{resource="product",operation="get",le="+Inf"} 2` + "\n",
+		`akeneo_request_duration_seconds_count{resource="product",operation="get"} 2` + "\n",
+		`akeneo_request_duration_seconds_count{resource="product",operation="batch_upsert"} 1` + "\n",
+		"# TYPE akeneo_token_refresh_total counter\n" + `akeneo_token_refresh_total{grant_type="password",result="success"} 1` + "\n",
+		"# TYPE akeneo_retries_total counter\n" + `akeneo_retries_total{resource="product",operation="get"} 1` + "\n",
+		"# TYPE akeneo_batch_line_failures_total counter\n" + `akeneo_batch_line_failures_total{resource="product",status="422"} 1` + "\n",
+	}
+	for _, part := range expected {
+		if !strings.Contains(metrics, part) {
+			t.Errorf("missing %q in\n%s", part, metrics)
+		}
+	}
+	if !strings.Contains(metrics, `akeneo_request_duration_seconds_sum{resource="product",operation="get"} `) {
+		t.Errorf("missing the duration sum in\n%s", metrics)
+	}
+}
+
+func TestLogObserver(t *testing.T) {
+	var b bytes.Buffer
+	_, api := newObservedApi(t, akeneo.NewLogObserver(log.New(&b, "", 0)))
+	runObservedRequests(t, api)
+
+	expected := []string{
+		"[AKENEO_TOKEN]: password grant in ",
+		"[AKENEO_REQUEST]: GET http://",
+		"(product/get) 503 in ",
+		"(product/get) 200 in ",
+		"[AKENEO_RETRY]: product/get attempt 1 (status 503) in ",
+		"(product/batch_upsert) 200 in ",
+		"[AKENEO_BATCH_LINE_ERROR]: product line 2 b => 422 ",
+	}
+	logged := b.String()
+	for _, part := range expected {
+		if !strings.Contains(logged, part) {
+			t.Errorf("missing %q in\n%s", part, logged)
+		}
+	}
+}
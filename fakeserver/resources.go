@@ -0,0 +1,372 @@
+package fakeserver
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+type validator func(s *Server, parent string, item record) []*akeneo.ValidationError
+
+type resourceDef struct {
+	name        string
+	path        string
+	key         string
+	writable    bool
+	deletable   bool
+	timestamps  bool
+	searchAfter bool
+	searchable  bool
+	validate    validator
+}
+
+var (
+	productDef         = &resourceDef{name: akeneo.ResourceProduct, path: "products", key: "identifier", writable: true, deletable: true, timestamps: true, searchAfter: true, searchable: true}
+	productModelDef    = &resourceDef{name: akeneo.ResourceProductModel, path: "product-models", key: "code", writable: true, deletable: true, timestamps: true, searchAfter: true, searchable: true}
+	mediaFileDef       = &resourceDef{name: akeneo.ResourceMediaFile, path: "media-files", key: "code", searchAfter: true}
+	categoryDef        = &resourceDef{name: akeneo.ResourceCategory, path: "categories", key: "code", writable: true}
+	familyDef          = &resourceDef{name: akeneo.ResourceFamily, path: "families", key: "code", writable: true}
+	familyVariantDef   = &resourceDef{name: akeneo.ResourceFamilyVariant, path: "variants", key: "code", writable: true}
+	attributeDef       = &resourceDef{name: akeneo.ResourceAttribute, path: "attributes", key: "code", writable: true}
+	attributeOptionDef = &resourceDef{name: akeneo.ResourceAttributeOption, path: "options", key: "code", writable: true}
+	attributeGroupDef  = &resourceDef{name: akeneo.ResourceAttributeGroup, path: "attribute-groups", key: "code", writable: true}
+	associationTypeDef = &resourceDef{name: akeneo.ResourceAssociationType, path: "association-types", key: "code", writable: true}
+	channelDef         = &resourceDef{name: akeneo.ResourceChannel, path: "channels", key: "code", writable: true}
+	localeDef          = &resourceDef{name: akeneo.ResourceLocale, path: "locales", key: "code"}
+	currencyDef        = &resourceDef{name: akeneo.ResourceCurrency, path: "currencies", key: "code"}
+	measureFamilyDef   = &resourceDef{name: akeneo.ResourceMeasureFamily, path: "measure-families", key: "code"}
+)
+
+// Validators look other definitions up, so they are attached here to avoid
+// an initialization cycle.
+func init() {
+	productDef.validate = validateProduct
+	productModelDef.validate = validateProductModel
+	categoryDef.validate = validateCategory
+	familyDef.validate = validateFamily
+	familyVariantDef.validate = validateFamilyVariant
+	attributeDef.validate = validateAttribute
+	attributeOptionDef.validate = validateAttributeOption
+	attributeGroupDef.validate = validateCode
+	associationTypeDef.validate = validateCode
+	channelDef.validate = validateChannel
+}
+
+var topLevelDefs = []*resourceDef{
+	productDef, productModelDef, mediaFileDef, categoryDef, familyDef, attributeDef,
+	attributeGroupDef, associationTypeDef, channelDef, localeDef, currencyDef, measureFamilyDef,
+}
+
+var resourceDefs = append([]*resourceDef{familyVariantDef, attributeOptionDef}, topLevelDefs...)
+
+func defByName(name string) (*resourceDef, bool) {
+	for _, def := range resourceDefs {
+		if def.name == name {
+			return def, true
+		}
+	}
+
+	return nil, false
+}
+
+type route struct {
+	def      *resourceDef
+	parent   string
+	code     string
+	download bool
+}
+
+// collectionPath returns the URL path of the collection the route belongs to.
+func (rt *route) collectionPath() string {
+	switch rt.def {
+	case familyVariantDef:
+		return "families/" + url.PathEscape(rt.parent) + "/variants"
+	case attributeOptionDef:
+		return "attributes/" + url.PathEscape(rt.parent) + "/options"
+	}
+
+	return rt.def.path
+}
+
+func (rt *route) itemPath(code string) string {
+	if rt.def == mediaFileDef {
+		return rt.collectionPath() + "/" + code
+	}
+
+	return rt.collectionPath() + "/" + url.PathEscape(code)
+}
+
+func parseRoute(path string) (*route, bool) {
+	path = strings.Trim(path, "/")
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, false
+		}
+		segments[i] = unescaped
+	}
+
+	if segments[0] == mediaFileDef.path && len(segments) > 1 {
+		rt := &route{def: mediaFileDef}
+		rest := segments[1:]
+		if len(rest) > 1 && rest[len(rest)-1] == "download" {
+			rt.download = true
+			rest = rest[:len(rest)-1]
+		}
+		rt.code = strings.Join(rest, "/")
+
+		return rt, true
+	}
+
+	if len(segments) >= 3 && len(segments) <= 4 {
+		var def *resourceDef
+		switch {
+		case segments[0] == familyDef.path && segments[2] == familyVariantDef.path:
+			def = familyVariantDef
+		case segments[0] == attributeDef.path && segments[2] == attributeOptionDef.path:
+			def = attributeOptionDef
+		default:
+			return nil, false
+		}
+
+		rt := &route{def: def, parent: segments[1]}
+		if len(segments) == 4 {
+			rt.code = segments[3]
+		}
+
+		return rt, true
+	}
+
+	if len(segments) > 2 {
+		return nil, false
+	}
+
+	for _, def := range topLevelDefs {
+		if def.path == segments[0] {
+			rt := &route{def: def}
+			if len(segments) == 2 {
+				rt.code = segments[1]
+			}
+
+			return rt, true
+		}
+	}
+
+	return nil, false
+}
+
+var codePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+var attributeTypes = map[string]bool{
+	"pim_catalog_identifier":             true,
+	"pim_catalog_text":                   true,
+	"pim_catalog_textarea":               true,
+	"pim_catalog_number":                 true,
+	"pim_catalog_metric":                 true,
+	"pim_catalog_price_collection":       true,
+	"pim_catalog_boolean":                true,
+	"pim_catalog_date":                   true,
+	"pim_catalog_simpleselect":           true,
+	"pim_catalog_multiselect":            true,
+	"pim_catalog_image":                  true,
+	"pim_catalog_file":                   true,
+	"pim_reference_data_simpleselect":    true,
+	"pim_reference_data_multiselect":     true,
+	"pim_catalog_asset_collection":       true,
+	"pim_catalog_table":                  true,
+	"pim_catalog_product_link":           true,
+	"akeneo_reference_entity":            true,
+	"akeneo_reference_entity_collection": true,
+}
+
+func violation(property string, format string, args ...interface{}) *akeneo.ValidationError {
+	return &akeneo.ValidationError{Property: property, Message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) exists(def *resourceDef, parent string, code string) bool {
+	_, ok := s.collection(def, parent).items[code]
+	return ok
+}
+
+func (s *Server) checkReference(violations []*akeneo.ValidationError, property string, def *resourceDef, parent string, code string) []*akeneo.ValidationError {
+	if s.config.SkipReferenceChecks || code == "" || s.exists(def, parent, code) {
+		return violations
+	}
+
+	return append(violations, violation(property, "Property \"%s\" expects a valid %s code. The %s does not exist, \"%s\" given.", property, strings.Replace(def.name, "_", " ", -1), strings.Replace(def.name, "_", " ", -1), code))
+}
+
+func validateCode(s *Server, parent string, item record) []*akeneo.ValidationError {
+	code := item.str("code")
+	if code == "" {
+		return []*akeneo.ValidationError{violation("code", "This value should not be blank.")}
+	}
+
+	if !codePattern.MatchString(code) {
+		return []*akeneo.ValidationError{violation("code", "Code may contain only letters, numbers and underscores")}
+	}
+
+	return nil
+}
+
+func validateProduct(s *Server, parent string, item record) []*akeneo.ValidationError {
+	var violations []*akeneo.ValidationError
+
+	if item.str("identifier") == "" {
+		violations = append(violations, violation("identifier", "This value should not be blank."))
+	}
+
+	violations = s.checkReference(violations, "family", familyDef, "", item.str("family"))
+	violations = s.checkReference(violations, "parent", productModelDef, "", item.str("parent"))
+
+	for _, category := range item.strs("categories") {
+		violations = s.checkReference(violations, "categories", categoryDef, "", category)
+	}
+
+	for _, group := range item.strs("groups") {
+		if group == "" {
+			violations = append(violations, violation("groups", "Property \"groups\" expects an array of strings."))
+		}
+	}
+
+	return append(violations, s.validateValues(item)...)
+}
+
+func validateProductModel(s *Server, parent string, item record) []*akeneo.ValidationError {
+	violations := validateCode(s, parent, item)
+
+	if item.str("family_variant") == "" && item.str("parent") == "" {
+		violations = append(violations, violation("family_variant", "The product model family variant must not be empty."))
+	}
+
+	violations = s.checkReference(violations, "parent", productModelDef, "", item.str("parent"))
+
+	for _, category := range item.strs("categories") {
+		violations = s.checkReference(violations, "categories", categoryDef, "", category)
+	}
+
+	return append(violations, s.validateValues(item)...)
+}
+
+func (s *Server) validateValues(item record) []*akeneo.ValidationError {
+	if s.config.SkipReferenceChecks {
+		return nil
+	}
+
+	values, _ := item["values"].(map[string]interface{})
+
+	var violations []*akeneo.ValidationError
+	for _, code := range sortedKeys(values) {
+		if !s.exists(attributeDef, "", code) {
+			violations = append(violations, &akeneo.ValidationError{
+				Property:  "values",
+				Attribute: code,
+				Message:   fmt.Sprintf("The %s attribute does not exist.", code),
+			})
+		}
+	}
+
+	return violations
+}
+
+func validateCategory(s *Server, parent string, item record) []*akeneo.ValidationError {
+	violations := validateCode(s, parent, item)
+
+	if item.str("parent") != "" && item.str("parent") == item.str("code") {
+		return append(violations, violation("parent", "A category cannot be its own parent."))
+	}
+
+	return s.checkReference(violations, "parent", categoryDef, "", item.str("parent"))
+}
+
+func validateFamily(s *Server, parent string, item record) []*akeneo.ValidationError {
+	violations := validateCode(s, parent, item)
+
+	attributes := item.strs("attributes")
+	for _, attribute := range attributes {
+		violations = s.checkReference(violations, "attributes", attributeDef, "", attribute)
+	}
+
+	if label := item.str("attribute_as_label"); label != "" && !contains(attributes, label) {
+		violations = append(violations, violation("attribute_as_label", "Property \"attribute_as_label\" must belong to the family."))
+	}
+
+	requirements, _ := item["attribute_requirements"].(map[string]interface{})
+	for _, channel := range sortedKeys(requirements) {
+		violations = s.checkReference(violations, "attribute_requirements", channelDef, "", channel)
+	}
+
+	return violations
+}
+
+func validateFamilyVariant(s *Server, parent string, item record) []*akeneo.ValidationError {
+	violations := validateCode(s, parent, item)
+	violations = s.checkReference(violations, "family", familyDef, "", parent)
+
+	sets, _ := item["variant_attribute_sets"].([]interface{})
+	if len(sets) == 0 {
+		violations = append(violations, violation("variant_attribute_sets", "There should be at least one level defined in the family variant."))
+	}
+
+	for _, set := range sets {
+		level, _ := set.(map[string]interface{})
+		if len(record(level).strs("axes")) == 0 {
+			violations = append(violations, violation("variant_attribute_sets", "Variant attribute sets must define at least one axis."))
+		}
+	}
+
+	return violations
+}
+
+func validateAttribute(s *Server, parent string, item record) []*akeneo.ValidationError {
+	violations := validateCode(s, parent, item)
+
+	if !attributeTypes[item.str("type")] {
+		violations = append(violations, violation("type", "Property \"type\" expects a valid attribute type, \"%s\" given.", item.str("type")))
+	}
+
+	if item.str("group") == "" {
+		violations = append(violations, violation("group", "This value should not be blank."))
+	}
+
+	return s.checkReference(violations, "group", attributeGroupDef, "", item.str("group"))
+}
+
+func validateAttributeOption(s *Server, parent string, item record) []*akeneo.ValidationError {
+	violations := validateCode(s, parent, item)
+
+	if attribute := item.str("attribute"); attribute != "" && attribute != parent {
+		violations = append(violations, violation("attribute", "The attribute code \"%s\" provided in the request body must match the attribute code \"%s\" provided in the url.", attribute, parent))
+	}
+
+	return s.checkReference(violations, "attribute", attributeDef, "", parent)
+}
+
+func validateChannel(s *Server, parent string, item record) []*akeneo.ValidationError {
+	violations := validateCode(s, parent, item)
+
+	for _, locale := range item.strs("locales") {
+		violations = s.checkReference(violations, "locales", localeDef, "", locale)
+	}
+
+	for _, currency := range item.strs("currencies") {
+		violations = s.checkReference(violations, "currencies", currencyDef, "", currency)
+	}
+
+	return s.checkReference(violations, "category_tree", categoryDef, "", item.str("category_tree"))
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,297 @@
+package fakeserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type searchFilter struct {
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+	Scope    *string     `json:"scope"`
+	Locale   *string     `json:"locale"`
+	Locales  []string    `json:"locales"`
+}
+
+var propertyFilters = map[string]bool{
+	"identifier":     true,
+	"code":           true,
+	"family":         true,
+	"family_variant": true,
+	"categories":     true,
+	"enabled":        true,
+	"groups":         true,
+	"parent":         true,
+	"created":        true,
+	"updated":        true,
+}
+
+// filter returns the sorted codes of the collection items matching the
+// `search` query parameter. Only products and product models support it.
+func (s *Server) filter(rt *route, query url.Values) ([]string, error) {
+	c := s.collection(rt.def, rt.parent)
+	codes := c.codes()
+
+	raw := query.Get("search")
+	if raw == "" || !rt.def.searchable {
+		return codes, nil
+	}
+
+	search := map[string][]*searchFilter{}
+	if err := json.Unmarshal([]byte(raw), &search); err != nil {
+		return nil, fmt.Errorf("Search query parameter should be valid JSON.")
+	}
+
+	matched := codes[:0:0]
+	for _, code := range codes {
+		ok, err := s.matches(c.items[code], search, query)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, code)
+		}
+	}
+
+	return matched, nil
+}
+
+func (s *Server) matches(rec record, search map[string][]*searchFilter, query url.Values) (bool, error) {
+	for property, filters := range search {
+		for _, f := range filters {
+			var value interface{}
+			if property == "family" && rec["family"] == nil {
+				value = s.variantFamily(rec.str("family_variant"))
+			} else if propertyFilters[property] {
+				value = rec[property]
+			} else {
+				value = attributeData(rec, property, f, query)
+			}
+
+			ok, err := s.apply(property, f, value)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+
+	return true, nil
+}
+
+func attributeData(rec record, attribute string, f *searchFilter, query url.Values) interface{} {
+	values, _ := rec["values"].(map[string]interface{})
+	list, _ := values[attribute].([]interface{})
+
+	scope := query.Get("search_scope")
+	if f.Scope != nil {
+		scope = *f.Scope
+	}
+
+	locale := query.Get("search_locale")
+	if f.Locale != nil {
+		locale = *f.Locale
+	}
+
+	for _, item := range list {
+		value, _ := item.(map[string]interface{})
+		if s, ok := value["scope"].(string); ok && scope != "" && s != scope {
+			continue
+		}
+		if l, ok := value["locale"].(string); ok && locale != "" && l != locale {
+			continue
+		}
+
+		return value["data"]
+	}
+
+	return nil
+}
+
+// variantFamily returns the family of a family variant, which is the family
+// of the product models using it.
+func (s *Server) variantFamily(variant string) interface{} {
+	if variant == "" {
+		return nil
+	}
+
+	for _, family := range s.collection(familyDef, "").codes() {
+		if s.exists(familyVariantDef, family, variant) {
+			return family
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) apply(property string, f *searchFilter, value interface{}) (bool, error) {
+	switch strings.ToUpper(f.Operator) {
+	case "IN", "IN CHILDREN":
+		return intersects(value, f.Value, strings.ToUpper(f.Operator) == "IN CHILDREN", s), nil
+	case "NOT IN", "NOT IN CHILDREN":
+		return !intersects(value, f.Value, strings.ToUpper(f.Operator) == "NOT IN CHILDREN", s), nil
+	case "UNCLASSIFIED":
+		return isEmpty(value), nil
+	case "IN OR UNCLASSIFIED":
+		return isEmpty(value) || intersects(value, f.Value, false, s), nil
+	case "EMPTY":
+		return isEmpty(value), nil
+	case "NOT EMPTY":
+		return !isEmpty(value), nil
+	case "=":
+		return compare(value, f.Value) == 0, nil
+	case "!=":
+		return compare(value, f.Value) != 0, nil
+	case "<":
+		return !isEmpty(value) && compare(value, f.Value) < 0, nil
+	case "<=":
+		return !isEmpty(value) && compare(value, f.Value) <= 0, nil
+	case ">":
+		return !isEmpty(value) && compare(value, f.Value) > 0, nil
+	case ">=":
+		return !isEmpty(value) && compare(value, f.Value) >= 0, nil
+	case "BETWEEN", "NOT BETWEEN":
+		bounds, ok := f.Value.([]interface{})
+		if !ok || len(bounds) != 2 {
+			return false, fmt.Errorf("Property \"%s\" expects an array with 2 elements as data.", property)
+		}
+		inside := !isEmpty(value) && compare(value, bounds[0]) >= 0 && compare(value, bounds[1]) <= 0
+		return inside == (strings.ToUpper(f.Operator) == "BETWEEN"), nil
+	case "SINCE LAST N DAYS":
+		days, ok := toFloat(f.Value)
+		if !ok {
+			return false, fmt.Errorf("Property \"%s\" expects a number as data.", property)
+		}
+		since := time.Now().Add(-time.Duration(days*24) * time.Hour)
+		date, ok := toTime(value)
+		return ok && !date.Before(since), nil
+	case "CONTAINS":
+		return strings.Contains(strings.ToLower(fmt.Sprint(value)), strings.ToLower(fmt.Sprint(f.Value))), nil
+	case "DOES NOT CONTAIN":
+		return !strings.Contains(strings.ToLower(fmt.Sprint(value)), strings.ToLower(fmt.Sprint(f.Value))), nil
+	case "STARTS WITH":
+		return strings.HasPrefix(strings.ToLower(fmt.Sprint(value)), strings.ToLower(fmt.Sprint(f.Value))), nil
+	}
+
+	return false, fmt.Errorf("Filter on property \"%s\" is not supported or does not support operator \"%s\"", property, f.Operator)
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+
+	return false
+}
+
+func toStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	}
+
+	return []string{fmt.Sprint(value)}
+}
+
+func intersects(value interface{}, wanted interface{}, withChildren bool, s *Server) bool {
+	candidates := toStrings(wanted)
+	if withChildren {
+		candidates = s.categoryDescendants(candidates)
+	}
+
+	for _, have := range toStrings(value) {
+		for _, want := range candidates {
+			if have == want {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (s *Server) categoryDescendants(roots []string) []string {
+	categories := s.collection(categoryDef, "").items
+	result := append([]string(nil), roots...)
+
+	for i := 0; i < len(result); i++ {
+		for code, rec := range categories {
+			if rec.str("parent") == result[i] && !contains(result, code) {
+				result = append(result, code)
+			}
+		}
+	}
+
+	return result
+}
+
+func compare(a interface{}, b interface{}) int {
+	if left, ok := toFloat(a); ok {
+		if right, ok := toFloat(b); ok {
+			switch {
+			case left < right:
+				return -1
+			case left > right:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	if left, ok := toTime(a); ok {
+		if right, ok := toTime(b); ok {
+			switch {
+			case left.Before(right):
+				return -1
+			case left.After(right):
+				return 1
+			}
+			return 0
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+func toTime(value interface{}) (time.Time, bool) {
+	text, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if date, err := time.Parse(layout, text); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
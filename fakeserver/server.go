@@ -0,0 +1,277 @@
+// Package fakeserver runs an in-process stand-in for the Akeneo PIM REST API.
+// It keeps its catalog in memory and implements the OAuth token endpoint and
+// the endpoints wrapped by the akeneo package, so integrations can be tested
+// without a real PIM.
+package fakeserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+const (
+	tokenPath  = "/api/oauth/v1/token"
+	restPrefix = "/api/rest/v1/"
+
+	maxPageSize  = 100
+	maxBatchSize = 100
+)
+
+type Config struct {
+	Username  string
+	Password  string
+	ClientId  string
+	SecretKey string
+	TokenTTL  time.Duration
+
+	// SkipReferenceChecks disables the checks that referenced families,
+	// categories, attributes... exist, so payloads can be loaded in any order.
+	SkipReferenceChecks bool
+}
+
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+}
+
+type Server struct {
+	*httptest.Server
+	mu            sync.Mutex
+	config        Config
+	collections   map[string]*collection
+	files         map[string][]byte
+	accessTokens  map[string]time.Time
+	refreshTokens map[string]bool
+	tokenCount    int
+	requests      []Request
+	faults        []*Fault
+}
+
+func NewServer(config *Config) *Server {
+	s := &Server{
+		config: Config{
+			Username:  "admin",
+			Password:  "admin",
+			ClientId:  "client_id",
+			SecretKey: "secret_key",
+			TokenTTL:  time.Hour,
+		},
+		collections:   map[string]*collection{},
+		files:         map[string][]byte{},
+		accessTokens:  map[string]time.Time{},
+		refreshTokens: map[string]bool{},
+	}
+
+	if config != nil {
+		s.config = *config
+		if s.config.TokenTTL <= 0 {
+			s.config.TokenTTL = time.Hour
+		}
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// ClientConfig returns a configuration pointing the akeneo client at the
+// fake server with valid credentials.
+func (s *Server) ClientConfig() *akeneo.ClientConfig {
+	return &akeneo.ClientConfig{
+		BaseUrl:        s.URL,
+		UserAgent:      "akeneo-fakeserver",
+		Username:       s.config.Username,
+		Password:       s.config.Password,
+		ClientId:       s.config.ClientId,
+		SecretKey:      s.config.SecretKey,
+		RequestTimeout: time.Second * 5,
+	}
+}
+
+// Requests returns every request received so far, token requests included.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// TokenCount returns how many tokens the token endpoint has issued.
+func (s *Server) TokenCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.tokenCount
+}
+
+// ExpireTokens invalidates every access token issued so far, as if they had
+// all reached their expiry date. Refresh tokens stay valid.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accessTokens = map[string]time.Time{}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query()})
+	fault := s.fault(r)
+	if fault != nil && fault.ExpireTokens {
+		s.accessTokens = map[string]time.Time{}
+	}
+	s.mu.Unlock()
+
+	if fault != nil {
+		if !fault.wait(r) || fault.respond(w, r) {
+			return
+		}
+
+		if fault.TruncateBody {
+			recorded := httptest.NewRecorder()
+			s.handle(recorded, r, fault)
+			fault.truncate(w, recorded)
+			return
+		}
+	}
+
+	s.handle(w, r, fault)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, fault *Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.URL.Path == tokenPath:
+		s.handleToken(w, r)
+	case strings.HasPrefix(r.URL.Path, restPrefix):
+		if !s.authorized(r) {
+			writeError(w, http.StatusUnauthorized, "The access token provided is invalid.")
+			return
+		}
+		s.handleRest(w, r, fault)
+	default:
+		writeError(w, http.StatusNotFound, "No route found for \""+r.Method+" "+r.URL.Path+"\"")
+	}
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	clientId, secret, ok := r.BasicAuth()
+	if !ok || clientId != s.config.ClientId || secret != s.config.SecretKey {
+		writeError(w, http.StatusUnprocessableEntity, "Parameter \"client_id\" is missing or does not match any client, or secret is invalid")
+		return
+	}
+
+	body := &akeneo.AuthBody{}
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid json message received")
+		return
+	}
+
+	switch body.GrantType {
+	case akeneo.GrantTypePassword:
+		if body.Username != s.config.Username || body.Password != s.config.Password {
+			writeError(w, http.StatusUnprocessableEntity, "No user found for the given username and password")
+			return
+		}
+	case akeneo.GrantTypeRefreshToken:
+		if !s.refreshTokens[body.RefreshToken] {
+			writeError(w, http.StatusUnprocessableEntity, "Refresh token is invalid or has expired")
+			return
+		}
+		delete(s.refreshTokens, body.RefreshToken)
+	default:
+		writeError(w, http.StatusUnprocessableEntity, "Parameter \"grant_type\" is missing or invalid")
+		return
+	}
+
+	s.tokenCount++
+	accessToken := fmt.Sprintf("access-token-%d", s.tokenCount)
+	refreshToken := fmt.Sprintf("refresh-token-%d", s.tokenCount)
+
+	s.accessTokens[accessToken] = time.Now().Add(s.config.TokenTTL)
+	s.refreshTokens[refreshToken] = true
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  accessToken,
+		"expires_in":    int(s.config.TokenTTL.Seconds()),
+		"token_type":    "bearer",
+		"scope":         nil,
+		"refresh_token": refreshToken,
+	})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return false
+	}
+
+	expiry, ok := s.accessTokens[header[7:]]
+
+	return ok && time.Now().Before(expiry)
+}
+
+func (s *Server) handleRest(w http.ResponseWriter, r *http.Request, fault *Fault) {
+	rt, ok := parseRoute(strings.TrimPrefix(r.URL.EscapedPath(), restPrefix))
+	if !ok {
+		writeError(w, http.StatusNotFound, "No route found for \""+r.Method+" "+r.URL.Path+"\"")
+		return
+	}
+
+	switch {
+	case rt.download && r.Method == http.MethodGet:
+		s.handleDownload(w, rt)
+	case rt.download:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	case rt.code == "" && r.Method == http.MethodGet:
+		s.handleList(w, r, rt)
+	case rt.code == "" && r.Method == http.MethodPost && rt.def.name == akeneo.ResourceMediaFile:
+		s.handleMediaUpload(w, r)
+	case rt.code == "" && r.Method == http.MethodPost && rt.def.writable:
+		s.handleCreate(w, r, rt)
+	case rt.code == "" && r.Method == http.MethodPatch && rt.def.writable:
+		s.handleBatch(w, r, rt, fault)
+	case rt.code != "" && r.Method == http.MethodGet:
+		s.handleGet(w, rt)
+	case rt.code != "" && r.Method == http.MethodPatch && rt.def.writable:
+		s.handleUpsert(w, r, rt)
+	case rt.code != "" && r.Method == http.MethodDelete && rt.def.deletable:
+		s.handleDelete(w, rt)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"code": status, "message": message})
+}
+
+func writeValidationError(w http.ResponseWriter, violations []*akeneo.ValidationError) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+		"code":    http.StatusUnprocessableEntity,
+		"message": "Validation failed.",
+		"errors":  violations,
+	})
+}
@@ -0,0 +1,227 @@
+package fakeserver
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+func newTestApi(t *testing.T, config *Config) (*Server, *akeneo.Api) {
+	s := NewServer(config)
+	t.Cleanup(s.Close)
+
+	return s, akeneo.NewAkeneoApi(akeneo.NewClient(s.ClientConfig()))
+}
+
+func TestServerRejectsWrongCredentials(t *testing.T) {
+	s, _ := newTestApi(t, nil)
+
+	config := s.ClientConfig()
+	config.Password = "wrong"
+	api := akeneo.NewAkeneoApi(akeneo.NewClient(config))
+
+	_, err := api.Product.Get("sku-1")
+	if apiErr, ok := akeneo.AsApiError(err); !ok || apiErr.Code != http.StatusUnprocessableEntity || apiErr.Message != "No user found for the given username and password" {
+		t.Fatalf("expected a decoded authentication error, got %v", err)
+	}
+	if s.TokenCount() != 0 {
+		t.Errorf("expected no token, got %d", s.TokenCount())
+	}
+}
+
+func TestServerProductLifecycle(t *testing.T) {
+	s, api := newTestApi(t, nil)
+	if err := s.Load(akeneo.ResourceFamily, "", &akeneo.Family{Code: "shoes", AttributeAsLabel: "sku"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := api.Product.Create(&akeneo.Product{Identifier: "sku-1", Enabled: true, FamilyCode: "shoes"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.Product.Create(&akeneo.Product{Identifier: "sku-1"}); err == nil || err.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected a 422 for a duplicate, got %v", err)
+	}
+
+	if err := api.Product.Upsert(&akeneo.Product{Identifier: "sku-1", Enabled: false, FamilyCode: "shoes"}); err != nil {
+		t.Fatal(err)
+	}
+
+	product, err := api.Product.Get("sku-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if product.Enabled || product.FamilyCode != "shoes" || product.Created == "" {
+		t.Errorf("unexpected product %+v", product)
+	}
+
+	if err := api.Product.Delete("sku-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.Product.Get("sku-1"); err == nil || !akeneo.IsNotFound(err) {
+		t.Errorf("expected a 404, got %v", err)
+	}
+}
+
+func TestServerChecksReferences(t *testing.T) {
+	_, api := newTestApi(t, nil)
+
+	err := api.Product.Create(&akeneo.Product{Identifier: "sku-1", FamilyCode: "unknown"})
+	if err == nil || err.Code != http.StatusUnprocessableEntity || len(err.Errors) == 0 {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	_, api = newTestApi(t, &Config{Username: "admin", Password: "admin", ClientId: "client_id", SecretKey: "secret_key", SkipReferenceChecks: true})
+	if err := api.Product.Create(&akeneo.Product{Identifier: "sku-1", FamilyCode: "unknown"}); err != nil {
+		t.Errorf("expected the reference check to be skipped, got %v", err)
+	}
+}
+
+func TestServerBatchUpsert(t *testing.T) {
+	s, api := newTestApi(t, nil)
+	s.Load(akeneo.ResourceCategory, "", &akeneo.Category{Code: "master"})
+
+	parent := "master"
+	lines, err := api.Category.BatchUpsert([]*akeneo.Category{
+		{Code: "shoes", Parent: &parent},
+		{Code: "boots", Parent: &parent},
+		{Code: "orphan", Parent: &[]string{"missing"}[0]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[string]int32{}
+	for _, line := range lines {
+		statuses[line.Code] = line.StatusCode
+	}
+	if statuses["shoes"] != http.StatusCreated || statuses["boots"] != http.StatusCreated || statuses["orphan"] != http.StatusUnprocessableEntity {
+		t.Errorf("unexpected statuses %v", statuses)
+	}
+	if count := s.Count(akeneo.ResourceCategory, ""); count != 3 {
+		t.Errorf("expected 3 categories, got %d", count)
+	}
+}
+
+func TestServerPaginatesWithSearchAfter(t *testing.T) {
+	s, api := newTestApi(t, &Config{Username: "admin", Password: "admin", ClientId: "client_id", SecretKey: "secret_key", SkipReferenceChecks: true})
+
+	for i := 0; i < 25; i++ {
+		s.Load(akeneo.ResourceProduct, "", &akeneo.Product{Identifier: fmt.Sprintf("sku-%02d", i), Enabled: i%2 == 0})
+	}
+
+	it := api.Product.Iterate(akeneo.ProductOptions{PaginationType: akeneo.PaginationTypeSearchAfter, Limit: 10})
+	count := 0
+	previous := ""
+	for it.Next() {
+		if it.Item().Identifier <= previous {
+			t.Fatalf("%q came after %q", it.Item().Identifier, previous)
+		}
+		previous = it.Item().Identifier
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 25 {
+		t.Errorf("expected 25 products, got %d", count)
+	}
+
+	pages := 0
+	for _, request := range s.Requests() {
+		if request.Path == "/api/rest/v1/products" {
+			pages++
+		}
+	}
+	if pages != 3 {
+		t.Errorf("expected 3 pages, got %d", pages)
+	}
+}
+
+func TestServerSearchesProducts(t *testing.T) {
+	s, api := newTestApi(t, &Config{Username: "admin", Password: "admin", ClientId: "client_id", SecretKey: "secret_key", SkipReferenceChecks: true})
+
+	s.Load(akeneo.ResourceProduct, "",
+		&akeneo.Product{Identifier: "a", Enabled: true, FamilyCode: "shoes"},
+		&akeneo.Product{Identifier: "b", Enabled: false, FamilyCode: "shoes"},
+		&akeneo.Product{Identifier: "c", Enabled: true, FamilyCode: "shirts"})
+
+	search := akeneo.NewSearch().Enabled(true).Family(akeneo.OperatorIn, "shoes")
+	response, err := api.Product.GetAll(akeneo.ProductOptions{Search: search})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Data.Items) != 1 || response.Data.Items[0].Identifier != "a" {
+		t.Errorf("unexpected items %+v", response.Data.Items)
+	}
+}
+
+func TestServerRenewsExpiredTokens(t *testing.T) {
+	s, api := newTestApi(t, &Config{Username: "admin", Password: "admin", ClientId: "client_id", SecretKey: "secret_key", SkipReferenceChecks: true})
+	s.Load(akeneo.ResourceProduct, "", &akeneo.Product{Identifier: "a"})
+
+	if _, err := api.Product.Get("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.ExpireTokens()
+	if _, err := api.Product.Get("a"); err == nil || !akeneo.IsUnauthorized(err) {
+		t.Errorf("expected a 401 with an expired token, got %v", err)
+	}
+}
+
+func TestServerRejectedRefreshFallsBackToPassword(t *testing.T) {
+	s := NewServer(nil)
+	t.Cleanup(s.Close)
+
+	token := &akeneo.Token{ExpiresIn: 3600}
+	token.AccessToken = "expired"
+	token.RefreshToken = "revoked"
+	token.TokenType = "bearer"
+	token.Expiry = time.Now().Add(-time.Minute)
+
+	config := s.ClientConfig()
+	config.TokenStore = akeneo.NewMemoryTokenStore()
+	config.TokenStore.Save(token)
+	api := akeneo.NewAkeneoApi(akeneo.NewClient(config))
+
+	if _, err := api.Locale.GetAll(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The refresh is rejected without issuing a token, then the password
+	// grant issues one.
+	requests := 0
+	for _, request := range s.Requests() {
+		if request.Path == "/api/oauth/v1/token" {
+			requests++
+		}
+	}
+	if requests != 2 || s.TokenCount() != 1 {
+		t.Errorf("expected a rejected refresh and a password grant, got %d token requests and %d tokens", requests, s.TokenCount())
+	}
+}
+
+func TestServerSearchesProductModelsByFamily(t *testing.T) {
+	s, api := newTestApi(t, &Config{Username: "admin", Password: "admin", ClientId: "client_id", SecretKey: "secret_key", SkipReferenceChecks: true})
+
+	s.Load(akeneo.ResourceFamily, "", &akeneo.Family{Code: "shoes"}, &akeneo.Family{Code: "shirts"})
+	s.Load(akeneo.ResourceFamilyVariant, "shoes", &akeneo.FamilyVariant{Code: "shoes_by_color"})
+	s.Load(akeneo.ResourceFamilyVariant, "shirts", &akeneo.FamilyVariant{Code: "shirts_by_size"})
+	s.Load(akeneo.ResourceProductModel, "",
+		&akeneo.ProductModel{Code: "boot", FamilyVariant: "shoes_by_color"},
+		&akeneo.ProductModel{Code: "polo", FamilyVariant: "shirts_by_size"})
+
+	search := akeneo.NewSearch().Family(akeneo.OperatorIn, "shoes")
+	response, err := api.ProductModel.GetAll(akeneo.ProductModelOptions{Search: search})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Data.Items) != 1 || response.Data.Items[0].Code != "boot" {
+		t.Errorf("unexpected items %+v", response.Data.Items)
+	}
+}
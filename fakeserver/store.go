@@ -0,0 +1,551 @@
+package fakeserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	akeneo "github.com/c-design/akeneo/api"
+)
+
+// record is a resource as Akeneo stores it: a decoded JSON object.
+type record map[string]interface{}
+
+func (r record) str(key string) string {
+	value, _ := r[key].(string)
+	return value
+}
+
+func (r record) strs(key string) []string {
+	list, _ := r[key].([]interface{})
+
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		value, _ := item.(string)
+		values = append(values, value)
+	}
+
+	return values
+}
+
+type collection struct {
+	items map[string]record
+}
+
+func (c *collection) codes() []string {
+	codes := make([]string, 0, len(c.items))
+	for code := range c.items {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+
+	return codes
+}
+
+func (s *Server) collection(def *resourceDef, parent string) *collection {
+	key := def.name + "/" + parent
+
+	c, ok := s.collections[key]
+	if !ok {
+		c = &collection{items: map[string]record{}}
+		s.collections[key] = c
+	}
+
+	return c
+}
+
+// Load stores resources without validation, e.g. to seed the catalog before
+// a test. resource is one of the akeneo.Resource* constants; parent is the
+// family code for family variants, the attribute code for attribute options
+// and empty otherwise. Items are anything that marshals to the Akeneo JSON
+// representation, such as *akeneo.Product.
+func (s *Server) Load(resource string, parent string, items ...interface{}) error {
+	def, ok := defByName(resource)
+	if !ok {
+		return fmt.Errorf("fakeserver: unknown resource %q", resource)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range items {
+		rec, err := toRecord(item)
+		if err != nil {
+			return err
+		}
+
+		code := rec.str(def.key)
+		if code == "" {
+			return fmt.Errorf("fakeserver: %s without %s", resource, def.key)
+		}
+
+		s.collection(def, parent).items[code] = rec
+	}
+
+	return nil
+}
+
+// Find decodes the stored resource into out and reports whether it exists.
+func (s *Server) Find(resource string, parent string, code string, out interface{}) bool {
+	def, ok := defByName(resource)
+	if !ok {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.collection(def, parent).items[code]
+	if !ok {
+		return false
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(data, out) == nil
+}
+
+// Count returns the number of stored resources of the given kind.
+func (s *Server) Count(resource string, parent string) int {
+	def, ok := defByName(resource)
+	if !ok {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.collection(def, parent).items)
+}
+
+func toRecord(item interface{}) (record, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := record{}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+func (s *Server) link(path string, query url.Values) map[string]string {
+	href := s.URL + restPrefix + path
+	if len(query) > 0 {
+		href += "?" + query.Encode()
+	}
+
+	return map[string]string{"href": href}
+}
+
+func (s *Server) itemWithLinks(rt *route, code string, rec record) record {
+	item := record{}
+	for k, v := range rec {
+		item[k] = v
+	}
+
+	links := map[string]interface{}{"self": s.link(rt.itemPath(code), nil)}
+	if rt.def == mediaFileDef {
+		links["download"] = s.link(rt.itemPath(code)+"/download", nil)
+	}
+	item["_links"] = links
+
+	return item
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request, rt *route) {
+	query := r.URL.Query()
+
+	limit := 10
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			writeError(w, http.StatusUnprocessableEntity, "\"limit\" must be a positive integer.")
+			return
+		}
+		if parsed > maxPageSize {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("You cannot request more than %d items.", maxPageSize))
+			return
+		}
+		limit = parsed
+	}
+
+	codes, err := s.filter(rt, query)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	c := s.collection(rt.def, rt.parent)
+	links := map[string]interface{}{}
+	body := map[string]interface{}{"_links": links}
+
+	var page []string
+
+	if query.Get("pagination_type") == "search_after" {
+		if !rt.def.searchAfter {
+			writeError(w, http.StatusUnprocessableEntity, "Pagination type \"search_after\" is not supported by this resource.")
+			return
+		}
+
+		start := 0
+		if after := query.Get("search_after"); after != "" {
+			start = sort.SearchStrings(codes, after)
+			if start < len(codes) && codes[start] == after {
+				start++
+			}
+		}
+
+		end := start + limit
+		if end > len(codes) {
+			end = len(codes)
+		}
+		page = codes[start:end]
+
+		links["self"] = s.link(rt.collectionPath(), query)
+		first := cloneQuery(query)
+		first.Del("search_after")
+		links["first"] = s.link(rt.collectionPath(), first)
+
+		if end < len(codes) {
+			next := cloneQuery(query)
+			next.Set("search_after", codes[end-1])
+			links["next"] = s.link(rt.collectionPath(), next)
+		}
+	} else {
+		current := 1
+		if value := query.Get("page"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				writeError(w, http.StatusUnprocessableEntity, "\"page\" must be a positive integer.")
+				return
+			}
+			current = parsed
+		}
+
+		start := (current - 1) * limit
+		if start > len(codes) {
+			start = len(codes)
+		}
+		end := start + limit
+		if end > len(codes) {
+			end = len(codes)
+		}
+		page = codes[start:end]
+
+		body["current_page"] = current
+		if query.Get("withCount") == "true" {
+			body["items_count"] = len(codes)
+		}
+
+		links["self"] = s.link(rt.collectionPath(), withPage(query, current))
+		links["first"] = s.link(rt.collectionPath(), withPage(query, 1))
+		if current > 1 {
+			links["previous"] = s.link(rt.collectionPath(), withPage(query, current-1))
+		}
+		if end < len(codes) {
+			links["next"] = s.link(rt.collectionPath(), withPage(query, current+1))
+		}
+	}
+
+	items := make([]record, 0, len(page))
+	for _, code := range page {
+		items = append(items, s.itemWithLinks(rt, code, c.items[code]))
+	}
+	body["_embedded"] = map[string]interface{}{"items": items}
+
+	writeJSON(w, http.StatusOK, body)
+}
+
+func cloneQuery(query url.Values) url.Values {
+	clone := url.Values{}
+	for k, v := range query {
+		clone[k] = append([]string(nil), v...)
+	}
+
+	return clone
+}
+
+func withPage(query url.Values, page int) url.Values {
+	clone := cloneQuery(query)
+	clone.Set("page", strconv.Itoa(page))
+
+	return clone
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, rt *route) {
+	rec, ok := s.collection(rt.def, rt.parent).items[rt.code]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource `%s` does not exist.", rt.code))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.itemWithLinks(rt, rt.code, rec))
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request, rt *route) {
+	rec := record{}
+	if err := json.NewDecoder(r.Body).Decode(&rec); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid json message received")
+		return
+	}
+
+	code := rec.str(rt.def.key)
+	if _, exists := s.collection(rt.def, rt.parent).items[code]; exists && code != "" {
+		writeValidationError(w, []*akeneo.ValidationError{
+			violation(rt.def.key, "The same %s is already set on another %s", rt.def.key, rt.def.name),
+		})
+		return
+	}
+
+	if violations := s.save(rt, code, rec, true); len(violations) > 0 {
+		writeValidationError(w, violations)
+		return
+	}
+
+	w.Header().Set("Location", s.link(rt.itemPath(code), nil)["href"])
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) handleUpsert(w http.ResponseWriter, r *http.Request, rt *route) {
+	rec := record{}
+	if err := json.NewDecoder(r.Body).Decode(&rec); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid json message received")
+		return
+	}
+
+	status, violations := s.upsert(rt, rt.code, rec)
+	if len(violations) > 0 {
+		writeValidationError(w, violations)
+		return
+	}
+
+	w.Header().Set("Location", s.link(rt.itemPath(rt.code), nil)["href"])
+	w.WriteHeader(status)
+}
+
+func (s *Server) upsert(rt *route, code string, patch record) (int, []*akeneo.ValidationError) {
+	if bodyCode, ok := patch[rt.def.key].(string); ok && bodyCode != code {
+		return 0, []*akeneo.ValidationError{
+			violation(rt.def.key, "The %s \"%s\" provided in the request body must match the %s \"%s\" provided in the url.", rt.def.key, bodyCode, rt.def.key, code),
+		}
+	}
+
+	existing, exists := s.collection(rt.def, rt.parent).items[code]
+
+	merged := record{}
+	if exists {
+		for k, v := range existing {
+			merged[k] = v
+		}
+	}
+	merge(merged, patch)
+	merged[rt.def.key] = code
+
+	if violations := s.save(rt, code, merged, !exists); len(violations) > 0 {
+		return 0, violations
+	}
+
+	if exists {
+		return http.StatusNoContent, nil
+	}
+
+	return http.StatusCreated, nil
+}
+
+func (s *Server) save(rt *route, code string, rec record, created bool) []*akeneo.ValidationError {
+	if rt.def.validate != nil {
+		if violations := rt.def.validate(s, rt.parent, rec); len(violations) > 0 {
+			return violations
+		}
+	}
+
+	if rt.def.timestamps {
+		now := time.Now().Format(time.RFC3339)
+		if created {
+			rec["created"] = now
+		}
+		rec["updated"] = now
+	}
+
+	s.collection(rt.def, rt.parent).items[code] = rec
+
+	return nil
+}
+
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request, rt *route, fault *Fault) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var lines [][]byte
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) > maxBatchSize {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Too many resources to process, %d is the maximum allowed.", maxBatchSize))
+		return
+	}
+
+	var out bytes.Buffer
+	writer := bufio.NewWriter(&out)
+	encoder := json.NewEncoder(writer)
+
+	for i, line := range lines {
+		result := map[string]interface{}{"line": i + 1}
+
+		rec := record{}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			result["status_code"] = http.StatusBadRequest
+			result["message"] = "Invalid json message received"
+			_ = encoder.Encode(result)
+			continue
+		}
+
+		code := rec.str(rt.def.key)
+		result[rt.def.key] = code
+
+		if code == "" {
+			result["status_code"] = http.StatusUnprocessableEntity
+			result["message"] = fmt.Sprintf("%s is missing.", rt.def.key)
+			_ = encoder.Encode(result)
+			continue
+		}
+
+		if fault.lineFault(code) {
+			result["status_code"] = fault.lineStatus()
+			result["message"] = http.StatusText(fault.lineStatus())
+			_ = encoder.Encode(result)
+			continue
+		}
+
+		status, violations := s.upsert(rt, code, rec)
+		if len(violations) > 0 {
+			result["status_code"] = http.StatusUnprocessableEntity
+			result["message"] = "Validation failed."
+			result["errors"] = violations
+		} else {
+			result["status_code"] = status
+		}
+
+		_ = encoder.Encode(result)
+	}
+
+	_ = writer.Flush()
+
+	w.Header().Set("Content-Type", "application/vnd.akeneo.collection+json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(out.Bytes())
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, rt *route) {
+	c := s.collection(rt.def, rt.parent)
+	if _, ok := c.items[rt.code]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource `%s` does not exist.", rt.code))
+		return
+	}
+
+	delete(c.items, rt.code)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// merge applies an Akeneo PATCH: objects are merged recursively, any other
+// value replaces the previous one, and product values are merged per
+// attribute, scope and locale.
+func merge(target record, patch record) {
+	for key, value := range patch {
+		if key == "values" {
+			target[key] = mergeValues(target[key], value)
+			continue
+		}
+
+		patchObject, isObject := value.(map[string]interface{})
+		targetObject, wasObject := target[key].(map[string]interface{})
+		if isObject && wasObject {
+			merged := record{}
+			for k, v := range targetObject {
+				merged[k] = v
+			}
+			merge(merged, patchObject)
+			target[key] = map[string]interface{}(merged)
+			continue
+		}
+
+		target[key] = value
+	}
+}
+
+func mergeValues(current interface{}, patch interface{}) interface{} {
+	currentValues, _ := current.(map[string]interface{})
+	patchValues, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	merged := map[string]interface{}{}
+	for code, values := range currentValues {
+		merged[code] = values
+	}
+
+	for code, values := range patchValues {
+		existing, _ := merged[code].([]interface{})
+		incoming, _ := values.([]interface{})
+
+		result := append([]interface{}(nil), existing...)
+		for _, value := range incoming {
+			replaced := false
+			for i, old := range result {
+				if sameChannelAndLocale(old, value) {
+					result[i] = value
+					replaced = true
+					break
+				}
+			}
+
+			if !replaced {
+				result = append(result, value)
+			}
+		}
+
+		merged[code] = result
+	}
+
+	return merged
+}
+
+func sameChannelAndLocale(a interface{}, b interface{}) bool {
+	left, _ := a.(map[string]interface{})
+	right, _ := b.(map[string]interface{})
+
+	return left["scope"] == right["scope"] && left["locale"] == right["locale"]
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}